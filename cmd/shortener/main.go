@@ -8,28 +8,49 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	grpcsrv "github.com/nextlag/shortenerURL/internal/controllers/grpc"
 	http2 "github.com/nextlag/shortenerURL/internal/controllers/http"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository"
-	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
 	pb "github.com/nextlag/shortenerURL/proto"
 
 	"github.com/nextlag/shortenerURL/internal/cert"
 	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/health"
+	"github.com/nextlag/shortenerURL/internal/metrics"
 	"github.com/nextlag/shortenerURL/internal/middleware/logger"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+	"github.com/nextlag/shortenerURL/internal/tracing"
 	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/linkcheck"
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc"
+	"github.com/nextlag/shortenerURL/internal/usecase/outbox"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
 )
 
+// stopTimeout bounds the flush of the tracer and the stop of the repository, which
+// happen after the servers drained and may be left with no time of the shutdown timeout.
+const stopTimeout = 5 * time.Second
+
 var (
 	buildVersion = "N/A"
 	buildDate    = "N/A"
@@ -37,12 +58,16 @@ var (
 )
 
 func main() {
-	log := logger.SetupLogger()
-
 	cfg, err := configuration.Load()
 	if err != nil {
-		log.Fatal("failed to init configuration", zap.Error(err))
+		logger.SetupLogger().Fatal("failed to init configuration", zap.Error(err))
+	}
+
+	log, level, err := logger.NewLogger(cfg.Log)
+	if err != nil {
+		logger.SetupLogger().Fatal("failed to init logger", zap.Error(err))
 	}
+	defer log.Sync()
 
 	fmt.Printf(
 		"Build version: %s,\nBuild date: %s,\nBuild commit: %s,\n",
@@ -57,10 +82,69 @@ func main() {
 	if err != nil {
 		log.Fatal("failed to init repository")
 	}
-	uc := usecase.New(db)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	tracer, err := newTracer(ctx, cfg.Tracing, log)
+	if err != nil {
+		log.Fatal("failed to init tracing", zap.Error(err))
+	}
+	m := metrics.New()
+	if store, ok := db.(*inmemory.Data); ok {
+		m.SetInMemoryLinks(store.Len)
+	}
+	repo := repository.Instrument(db, m, tracer)
+
+	dispatcher := webhook.New(repo, cfg.Webhooks, log)
+	dispatcher.Start(ctx)
+	defaultQuota := entity.Quota{MaxLinks: cfg.Quota.MaxLinks, MaxDailyLinks: cfg.Quota.MaxDailyLinks}
+	recorder := audit.NewRecorder(repo, log)
+	uc := usecase.New(repo, usecase.WithPublisher(dispatcher), usecase.WithQuota(defaultQuota), usecase.WithAudit(recorder))
+
+	if store, ok := db.(outbox.Store); ok {
+		publisher, err := newOutboxPublisher(cfg.Outbox, dispatcher)
+		if err != nil {
+			log.Fatal("failed to init outbox publisher", zap.Error(err))
+		}
+		go outbox.NewRelay(store, publisher, cfg.Outbox, log).Run(ctx)
+	}
+
+	if cfg.LinkCheck.Interval > 0 {
+		go linkcheck.New(repo, cfg.LinkCheck, log).Run(ctx)
+	}
+
+	a, err := auth.New(cfg.JWT, repo, log, auth.WithAdmins(cfg.Admin.Users...), auth.WithAudit(recorder))
+	if err != nil {
+		log.Fatal("failed to init authentication", zap.Error(err))
+	}
+
+	opts := []http2.Option{http2.WithMetrics(m), http2.WithTracer(tracer), http2.WithLogLevel(level)}
+	if cfg.OIDC.Issuer != "" {
+		if cfg.OIDC.RedirectURL == "" {
+			cfg.OIDC.RedirectURL = strings.TrimSuffix(cfg.BaseURL, "/") + http2.OIDCCallbackPath
+		}
+		provider, err := oidc.Discover(ctx, cfg.OIDC, &http.Client{Timeout: 10 * time.Second})
+		if err != nil {
+			log.Fatal("failed to discover OIDC provider", zap.Error(err))
+		}
+		opts = append(opts, http2.WithOIDC(provider))
+	}
+
+	limits, err := newRateLimitStore(cfg.RateLimit, db)
+	if err != nil {
+		log.Fatal("failed to init rate limit store", zap.Error(err))
+	}
+	opts = append(opts, http2.WithRateLimitStore(limits))
+
+	checker := health.New(time.Duration(cfg.Health.Timeout))
+	opts = append(opts, http2.WithHealth(checker))
 
 	wg := sync.WaitGroup{}
-	controller := http2.New(uc, &wg, cfg, log)
+	controller := http2.New(uc, a, &wg, cfg, log, opts...)
+	m.SetDeletionQueueDepth(controller.PendingDeletions)
+
+	var grpcServing atomic.Bool
+	registerChecks(checker, cfg, db, uc, controller, &grpcServing)
 
 	r := chi.NewRouter()
 	r.Mount("/", controller.Controller(r))
@@ -78,12 +162,66 @@ func main() {
 		}
 	}
 
+	var adminSrv *http.Server
+	if cfg.AdminServer.Address != "" {
+		adminListener, err := listenAdmin(cfg.AdminServer.Address)
+		if err != nil {
+			log.Fatal("failed to listen for admin server", zap.Error(err))
+		}
+		adminSrv = &http.Server{Handler: controller.AdminController(chi.NewRouter())}
+		log.Info("admin server starting", zap.String("address", cfg.AdminServer.Address))
+		go func() {
+			if err := adminSrv.Serve(adminListener); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("admin server Serve:", zap.Error(err))
+			}
+		}()
+	}
+
 	log.Info(
 		"server starting",
 		zap.String("address", cfg.Host),
 		zap.String("url", cfg.BaseURL),
 	)
 
+	var grpcServer *grpc.Server
+	if cfg.EnableGRPC {
+		listen, err := net.Listen("tcp", cfg.RPCPort)
+		if err != nil {
+			log.Fatal("failed to listen for gRPC server", zap.Error(err))
+		}
+
+		authenticator := grpcsrv.NewAuthenticator(a, log)
+		limiter := grpcsrv.NewRateLimiter(cfg.RateLimit, limits, log)
+		grpcServer = grpc.NewServer(
+			tracer.ServerOption(),
+			grpc.ChainUnaryInterceptor(m.UnaryInterceptor(), grpcsrv.AuditUnary(), authenticator.Unary(), limiter.Unary()),
+			grpc.ChainStreamInterceptor(m.StreamInterceptor(), grpcsrv.AuditStream(), authenticator.Stream(), limiter.Stream()),
+		)
+
+		// The gRPC server shares the use case, and so the repository, of the HTTP server,
+		// and hands deletions to the controller so that shutdown waits for them.
+		pb.RegisterLinksServer(grpcServer, &grpcsrv.LinksServer{DB: uc, Auth: a, Deleter: controller, BaseURL: cfg.BaseURL})
+		pb.RegisterAdminServer(grpcServer, &grpcsrv.AdminServer{DB: uc, Auth: a})
+
+		healthServer := grpchealth.NewServer()
+		healthpb.RegisterHealthServer(grpcServer, healthServer)
+		go grpcsrv.ReportHealth(ctx, healthServer, checker, time.Duration(cfg.Health.Interval),
+			pb.Links_ServiceDesc.ServiceName, pb.Admin_ServiceDesc.ServiceName)
+
+		// Enable reflection
+		reflection.Register(grpcServer)
+
+		log.Info("gRPC server starting", zap.String("address", cfg.RPCPort))
+		go func() {
+			grpcServing.Store(true)
+			err := grpcServer.Serve(listen)
+			grpcServing.Store(false)
+			if err != nil {
+				log.Fatal("gRPC server failed", zap.Error(err))
+			}
+		}()
+	}
+
 	idleConnsClosed := make(chan struct{})
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
@@ -91,51 +229,66 @@ func main() {
 	go func() {
 		<-sigint
 		log.Info("shutting down server...")
+		checker.Shutdown()
+		if delay := time.Duration(cfg.Health.ShutdownDelay); delay > 0 {
+			log.Info("draining before shutdown", zap.Duration("delay", delay))
+			time.Sleep(delay)
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		if err = srv.Shutdown(ctx); err != nil {
-			log.Error("HTTP server Shutdown:", zap.Error(err))
-		}
-
-		wg.Wait()
-
-		close(idleConnsClosed)
-	}()
-
-	if cfg.EnableGRPC {
+		// The servers stop accepting requests together and drain their in-flight calls.
+		var servers sync.WaitGroup
+		servers.Add(1)
 		go func() {
-			listen, err := net.Listen("tcp", cfg.RPCPort)
-			if err != nil {
-				log.Fatal("failed to listen:", zap.Error(err))
-			}
-			log.Info("gRPC server starting", zap.String("address", cfg.RPCPort))
-
-			s := grpc.NewServer()
-
-			// Создаём экземпляр базы данных отдельно для gRPC сервера
-			db, err := psql.New(cfg, log)
-			uc = usecase.New(db)
-			if err != nil {
-				log.Fatal("failed to connect in database for gRPC server", zap.Error(err))
+			defer servers.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Error("HTTP server Shutdown:", zap.Error(err))
 			}
-			defer func() {
-				if err = db.Stop(); err != nil {
-					log.Error("error stopping DB for gRPC", zap.Error(err))
+		}()
+		if adminSrv != nil {
+			servers.Add(1)
+			go func() {
+				defer servers.Done()
+				if err := adminSrv.Shutdown(ctx); err != nil {
+					log.Error("admin server Shutdown:", zap.Error(err))
 				}
 			}()
+		}
+		if grpcServer != nil {
+			servers.Add(1)
+			go func() {
+				defer servers.Done()
+				stopGRPC(ctx, grpcServer)
+			}()
+		}
+		servers.Wait()
 
-			pb.RegisterLinksServer(s, &grpcsrv.LinksServer{DB: uc})
+		// Deletions accepted by either server are finished before the repository is closed.
+		// Those that outlast the shutdown timeout are abandoned with the repository left open,
+		// since closing it under them would fail them anyway.
+		deleted := waitGroup(ctx, &wg)
+		if !deleted {
+			log.Error("deletions did not finish before shutdown and were abandoned", zap.Error(ctx.Err()))
+		}
 
-			// Enable reflection
-			reflection.Register(s)
+		stop()
+		dispatcher.Wait()
 
-			if err := s.Serve(listen); err != nil {
-				log.Fatal("gRPC server failed", zap.Error(err))
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer stopCancel()
+		if err := tracer.Shutdown(stopCtx); err != nil {
+			log.Error("tracing Shutdown:", zap.Error(err))
+		}
+		if closer, ok := db.(interface{ Stop() error }); ok && deleted {
+			if err := stopRepository(stopCtx, closer); err != nil {
+				log.Error("error stopping repository", zap.Error(err))
 			}
-		}()
-	}
+		}
+
+		close(idleConnsClosed)
+	}()
 
 	switch {
 	case cfg.EnableHTTPS:
@@ -152,3 +305,187 @@ func main() {
 
 	log.Info("Server Shutdown gracefully")
 }
+
+// newOutboxPublisher creates the publisher of the outbox events chosen in the configuration.
+// Every publisher hands the events to the queue of the webhook dispatcher, which delivers
+// them after the relay acknowledged them; the file and stdout publishers write the events
+// as well.
+func newOutboxPublisher(cfg configuration.Outbox, dispatcher *webhook.Dispatcher) (outbox.Publisher, error) {
+	webhooks := outbox.NewChanPublisher(dispatcher.Events())
+	switch cfg.Publisher {
+	case "file", "stdout":
+		name := cfg.File
+		if cfg.Publisher == "stdout" {
+			name = "-"
+		}
+		publisher, err := outbox.NewFilePublisher(name)
+		if err != nil {
+			return nil, err
+		}
+		return outbox.MultiPublisher(publisher, webhooks), nil
+	case "channel", "":
+		return webhooks, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.Publisher)
+	}
+}
+
+// registerChecks registers the readiness checks of the dependencies: the database or the
+// file storage of the repository, the deletion queue and, if enabled, the gRPC server.
+func registerChecks(checker *health.Checker, cfg *configuration.Config, db repository.Repository, uc *usecase.UseCase, controller *http2.Controller, grpcServing *atomic.Bool) {
+	storage := func(context.Context) error {
+		ok, err := uc.DoHealthcheck()
+		if err == nil && !ok {
+			err = errors.New("storage is not available")
+		}
+		return err
+	}
+	switch {
+	case repository.Backend(db) == "postgres":
+		checker.Register("database", storage)
+	case cfg.FileStorage != "":
+		checker.Register("file_storage", storage)
+	}
+
+	checker.Register("deletion_queue", func(context.Context) error {
+		if pending := controller.PendingDeletions(); cfg.Health.MaxDeletionQueue > 0 && pending > cfg.Health.MaxDeletionQueue {
+			return fmt.Errorf("%d links are waiting for deletion, more than %d", pending, cfg.Health.MaxDeletionQueue)
+		}
+		return nil
+	})
+
+	if cfg.EnableGRPC {
+		checker.Register("grpc", func(context.Context) error {
+			if !grpcServing.Load() {
+				return errors.New("gRPC server is not serving")
+			}
+			return nil
+		})
+	}
+}
+
+// newTracer creates the tracer exporting spans with the exporter chosen in the configuration,
+// or nil if tracing is disabled. Errors of the export are logged.
+func newTracer(ctx context.Context, cfg configuration.Tracing, log *zap.Logger) (*tracing.Tracer, error) {
+	if cfg.Exporter == "" {
+		return nil, nil
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio %v is not between 0 and 1", cfg.SampleRatio)
+	}
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case "file":
+		exporter, err = tracing.NewFileExporter(cfg.File)
+	case "stdout":
+		exporter, err = tracing.NewWriterExporter(os.Stdout)
+	case "otlp":
+		headers := make(map[string]string, len(cfg.Headers))
+		for _, header := range cfg.Headers {
+			key, value, ok := strings.Cut(header, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid OTLP header %q, want key=value", header)
+			}
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		exporter, err = tracing.NewOTLPExporter(ctx, cfg.Endpoint, headers)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Error("tracing failed", zap.Error(err))
+	}))
+	return tracing.New(exporter, cfg.SampleRatio, cfg.ServiceName), nil
+}
+
+// stopGRPC stops the gRPC server gracefully, waiting for the in-flight calls, and stops
+// it forcefully if they do not finish before ctx is done.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+		<-done
+	}
+}
+
+// stopRepository stops the repository, giving up when ctx is done first.
+func stopRepository(ctx context.Context, closer interface{ Stop() error }) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- closer.Stop()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitGroup waits for wg until ctx is done and reports whether wg finished.
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// listenAdmin listens on the address of the admin server: a unix socket for addresses
+// starting with "unix:", which is only accessible to the user and group of the service,
+// and a TCP address otherwise. A socket left behind by an earlier run is removed.
+func listenAdmin(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, "unix:")
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err = os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale admin socket: %w", err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, 0o660); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to restrict admin socket: %w", err)
+	}
+	return l, nil
+}
+
+// newRateLimitStore creates the store of the rate limit buckets chosen in the configuration.
+// The database store shares the limits between the instances of the service.
+func newRateLimitStore(cfg configuration.RateLimit, db repository.Repository) (ratelimit.Store, error) {
+	switch cfg.Store {
+	case "memory", "":
+		return ratelimit.NewMemoryStore(), nil
+	case "database":
+		store, ok := db.(ratelimit.Store)
+		if !ok {
+			return nil, errors.New("the repository cannot store rate limits, a database is required")
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/nextlag/shortenerURL/internal/configuration"
 )
@@ -46,3 +52,71 @@ func TestSetupServer(t *testing.T) {
 		t.Error("expected router handler to be set")
 	}
 }
+
+func TestListenAdmin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admin.sock")
+	for i := 0; i < 2; i++ {
+		l, err := listenAdmin("unix:" + path)
+		if err != nil {
+			t.Fatalf("listen %d: %v", i, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o660 {
+			t.Errorf("expected socket mode 0660, got %v", info.Mode().Perm())
+		}
+		// Leave the socket file behind like a crashed server
+		l.(*net.UnixListener).SetUnlinkOnClose(false)
+		l.Close()
+	}
+
+	l, err := listenAdmin("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if l.Addr().Network() != "tcp" {
+		t.Errorf("expected a TCP listener, got %s", l.Addr().Network())
+	}
+}
+
+func TestWaitGroup(t *testing.T) {
+	var wg sync.WaitGroup
+	if !waitGroup(context.Background(), &wg) {
+		t.Error("expected an empty group to finish")
+	}
+
+	wg.Add(1)
+	defer wg.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if waitGroup(ctx, &wg) {
+		t.Error("expected the wait to stop with the context")
+	}
+}
+
+// stopFunc is a repository closer backed by a function.
+type stopFunc func() error
+
+func (f stopFunc) Stop() error { return f() }
+
+func TestStopRepository(t *testing.T) {
+	stopErr := errors.New("stop failed")
+	if err := stopRepository(context.Background(), stopFunc(func() error { return stopErr })); !errors.Is(err, stopErr) {
+		t.Errorf("expected the error of Stop, got %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := stopRepository(ctx, stopFunc(func() error {
+		<-release
+		return nil
+	}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the stop to give up with the context, got %v", err)
+	}
+}
@@ -19,22 +19,148 @@ var (
 // Config structure for configuration.
 type Config struct {
 	ServerHTTP
-	ConfigPath string `json:"config_path" env:"CONFIG_PATH" envDefault:"configuration.json"`
+	LinkCheck   LinkCheck   `json:"link_check"`
+	Webhooks    Webhooks    `json:"webhooks"`
+	Outbox      Outbox      `json:"outbox"`
+	JWT         JWT         `json:"jwt"`
+	OIDC        OIDC        `json:"oidc"`
+	Admin       Admin       `json:"admin"`
+	AdminServer AdminServer `json:"admin_server"`
+	RateLimit   RateLimit   `json:"rate_limit"`
+	Quota       Quota       `json:"quota"`
+	Tracing     Tracing     `json:"tracing"`
+	Log         Log         `json:"log"`
+	Health      Health      `json:"health"`
+	ConfigPath  string      `json:"config_path" env:"CONFIG_PATH" envDefault:"configuration.json"`
 }
 
 // ServerHTTP - structure for storing HTTP server configuration.
 type ServerHTTP struct {
-	Host          string `json:"host" env:"SERVER_ADDRESS" envDefault:":8080"`
-	BaseURL       string `json:"base_url" env:"BASE_URL" envDefault:"http://localhost:8080"`
-	FileStorage   string `json:"file_storage,omitempty" env:"FILE_STORAGE_PATH" envDefault:""`
-	DSN           string `json:"dsn,omitempty" env:"DATABASE_DSN" envDefault:""`
-	StorageType   string `json:"storage_type" env:"STORAGE_TYPE"`
-	EnableHTTPS   bool   `json:"enable_https" env:"ENABLE_HTTPS" envDefault:"false"`
-	Cert          string `json:"cert" env:"CERT" envDefault:"cert.pem"`
-	Key           string `json:"key" env:"KEY" envDefault:"key.pem"`
-	TrustedSubnet string `json:"trusted_subnet" envDefault:""`
-	EnableGRPC    bool   `json:"enable_grpc"`
-	RPCPort       string `json:"rpc_port"`
+	Host           string   `json:"host" env:"SERVER_ADDRESS" envDefault:":8080"`
+	BaseURL        string   `json:"base_url" env:"BASE_URL" envDefault:"http://localhost:8080"`
+	FileStorage    string   `json:"file_storage,omitempty" env:"FILE_STORAGE_PATH" envDefault:""`
+	DSN            string   `json:"dsn,omitempty" env:"DATABASE_DSN" envDefault:""`
+	StorageType    string   `json:"storage_type" env:"STORAGE_TYPE"`
+	EnableHTTPS    bool     `json:"enable_https" env:"ENABLE_HTTPS" envDefault:"false"`
+	Cert           string   `json:"cert" env:"CERT" envDefault:"cert.pem"`
+	Key            string   `json:"key" env:"KEY" envDefault:"key.pem"`
+	TrustedSubnet  string   `json:"trusted_subnet" env:"TRUSTED_SUBNET" envDefault:""` // TrustedSubnet is a comma-separated list of IPv4 and IPv6 CIDRs
+	TrustedProxies []string `json:"trusted_proxies" env:"TRUSTED_PROXIES"`             // TrustedProxies are the CIDRs of the proxies whose X-Forwarded-For is believed
+	EnableGRPC     bool     `json:"enable_grpc"`
+	RPCPort        string   `json:"rpc_port"`
+}
+
+// LinkCheck - structure for storing the configuration of the link target liveness checker.
+type LinkCheck struct {
+	Interval     Duration `json:"interval" env:"LINK_CHECK_INTERVAL" envDefault:"0s"`           // Interval between passes, 0 disables the checker
+	Timeout      Duration `json:"timeout" env:"LINK_CHECK_TIMEOUT" envDefault:"10s"`            // Timeout of a single request
+	Concurrency  int      `json:"concurrency" env:"LINK_CHECK_CONCURRENCY" envDefault:"4"`      // Concurrency is the number of parallel requests
+	Hosts        int      `json:"hosts" env:"LINK_CHECK_HOSTS" envDefault:"16"`                 // Hosts is the number of hosts checked in parallel
+	HostInterval Duration `json:"host_interval" env:"LINK_CHECK_HOST_INTERVAL" envDefault:"1s"` // HostInterval is the minimum delay between requests to one host
+}
+
+// Webhooks - structure for storing the configuration of outgoing webhook delivery.
+type Webhooks struct {
+	Workers        int      `json:"workers" env:"WEBHOOK_WORKERS" envDefault:"4"`                  // Workers is the number of parallel deliveries
+	QueueSize      int      `json:"queue_size" env:"WEBHOOK_QUEUE_SIZE" envDefault:"1024"`         // QueueSize is the number of buffered events
+	MaxAttempts    int      `json:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"6"`        // MaxAttempts before an event becomes a dead letter
+	InitialBackoff Duration `json:"initial_backoff" env:"WEBHOOK_INITIAL_BACKOFF" envDefault:"1s"` // InitialBackoff is the delay before the first retry
+	MaxBackoff     Duration `json:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" envDefault:"5m"`         // MaxBackoff caps the delay between retries
+	Timeout        Duration `json:"timeout" env:"WEBHOOK_TIMEOUT" envDefault:"10s"`                // Timeout of a single delivery
+}
+
+// Outbox - structure for storing the configuration of the transactional outbox relay.
+type Outbox struct {
+	Publisher string   `json:"publisher" env:"OUTBOX_PUBLISHER" envDefault:"channel"` // Publisher is "channel" (webhooks only), "file" or "stdout", which feed the webhooks too
+	File      string   `json:"file" env:"OUTBOX_FILE" envDefault:"events.json"`       // File receives the events of the "file" publisher
+	Interval  Duration `json:"interval" env:"OUTBOX_INTERVAL" envDefault:"1s"`        // Interval between polls of an empty outbox
+	BatchSize int      `json:"batch_size" env:"OUTBOX_BATCH_SIZE" envDefault:"100"`   // BatchSize is the number of events relayed per poll
+}
+
+// JWT - structure for storing the keys that sign and verify the user tokens.
+// Keys are given as "kid:secret" pairs; without a signing key the first active key signs.
+type JWT struct {
+	Keys        []string `json:"keys" env:"JWT_KEYS"`                                 // Keys are the active "kid:secret" pairs
+	SigningKey  string   `json:"signing_key" env:"JWT_SIGNING_KEY"`                   // SigningKey is the kid of the key that signs new tokens
+	RetiredKeys []string `json:"retired_keys" env:"JWT_RETIRED_KEYS"`                 // RetiredKeys are the kids of keys whose tokens are rejected
+	KeyFile     string   `json:"key_file,omitempty" env:"JWT_KEY_FILE"`               // KeyFile is a JSON file with further keys
+	AccessTTL   Duration `json:"access_ttl" env:"JWT_ACCESS_TTL" envDefault:"15m"`    // AccessTTL is the lifetime of an access token
+	RefreshTTL  Duration `json:"refresh_ttl" env:"JWT_REFRESH_TTL" envDefault:"720h"` // RefreshTTL is the lifetime of a refresh token
+}
+
+// OIDC - structure for storing the OpenID Connect provider users can log in with.
+// Login through the provider is enabled when Issuer is set.
+type OIDC struct {
+	Issuer       string   `json:"issuer" env:"OIDC_ISSUER"`                             // Issuer is the provider URL serving the discovery document
+	ClientID     string   `json:"client_id" env:"OIDC_CLIENT_ID"`                       // ClientID is the client registered at the provider
+	ClientSecret string   `json:"client_secret,omitempty" env:"OIDC_CLIENT_SECRET"`     // ClientSecret is empty for public clients
+	RedirectURL  string   `json:"redirect_url" env:"OIDC_REDIRECT_URL"`                 // RedirectURL defaults to the callback under BaseURL
+	Scopes       []string `json:"scopes" env:"OIDC_SCOPES" envDefault:"openid,profile"` // Scopes are requested in the authorization request
+}
+
+// Admin - structure for storing who moderates the service.
+type Admin struct {
+	Users       []string `json:"users" env:"ADMIN_USERS"`               // Users are the user IDs with the admin role
+	TrustedOnly bool     `json:"trusted_only" env:"ADMIN_TRUSTED_ONLY"` // TrustedOnly admits admins only from the trusted subnets
+}
+
+// AdminServer - structure for storing the separate listener of the profiling, stats, health
+// and admin routes. Without an address those routes are served by the public listener.
+type AdminServer struct {
+	Address string `json:"address" env:"ADMIN_SERVER_ADDRESS"` // Address is a TCP address such as "127.0.0.1:8081" or "unix:/path/to/socket"
+}
+
+// RateLimit - structure for storing the token-bucket rate limits of route groups, applied per user
+// or, for requests without a user, per client IP.
+type RateLimit struct {
+	Store  string   `json:"store" env:"RATE_LIMIT_STORE" envDefault:"memory"` // Store is "memory" or "database" to share limits between instances
+	Write  RateRule `json:"write" envPrefix:"RATE_LIMIT_WRITE_"`              // Write limits creating links over HTTP and gRPC
+	Auth   RateRule `json:"auth" envPrefix:"RATE_LIMIT_AUTH_"`                // Auth limits registrations and logins
+	Report RateRule `json:"report" envPrefix:"RATE_LIMIT_REPORT_"`            // Report limits abuse reports
+}
+
+// RateRule - structure for storing the limit of a route group: Requests per Period, with bursts
+// of up to Burst requests. Zero requests disable the limit.
+type RateRule struct {
+	Requests int      `json:"requests" env:"REQUESTS" envDefault:"60"` // Requests is the number of requests per Period
+	Period   Duration `json:"period" env:"PERIOD" envDefault:"1m"`     // Period is the refill period of Requests
+	Burst    int      `json:"burst" env:"BURST"`                       // Burst is the bucket size, Requests if unset
+}
+
+// Quota - structure for storing the default quota of users, which admins may override per user.
+// Zero limits are unlimited.
+type Quota struct {
+	MaxLinks      int `json:"max_links" env:"QUOTA_MAX_LINKS"`             // MaxLinks is the maximum number of links a user keeps
+	MaxDailyLinks int `json:"max_daily_links" env:"QUOTA_MAX_DAILY_LINKS"` // MaxDailyLinks is the maximum number of links a user creates per UTC day
+}
+
+// Tracing - structure for storing the configuration of request tracing. Tracing is disabled
+// without an exporter.
+type Tracing struct {
+	Exporter    string   `json:"exporter" env:"TRACING_EXPORTER"`                                                   // Exporter is "file", "stdout", "otlp" or empty
+	File        string   `json:"file" env:"TRACING_FILE" envDefault:"traces.json"`                                  // File receives the spans of the "file" exporter
+	Endpoint    string   `json:"endpoint" env:"TRACING_OTLP_ENDPOINT" envDefault:"http://localhost:4318/v1/traces"` // Endpoint is the OTLP/HTTP traces endpoint of the collector
+	Headers     []string `json:"headers" env:"TRACING_OTLP_HEADERS"`                                                // Headers are "key=value" pairs sent to the collector
+	ServiceName string   `json:"service_name" env:"TRACING_SERVICE_NAME" envDefault:"shortener"`                    // ServiceName identifies the service in the collector
+	SampleRatio float64  `json:"sample_ratio" env:"TRACING_SAMPLE_RATIO" envDefault:"1"`                            // SampleRatio is the share of new traces recorded, from 0 to 1
+}
+
+// Log - structure for storing the configuration of the application log. The level may be
+// changed at runtime through the admin API.
+type Log struct {
+	Level      string `json:"level" env:"LOG_LEVEL" envDefault:"info"`                            // Level is "debug", "info", "warn" or "error"
+	Format     string `json:"format" env:"LOG_FORMAT" envDefault:"console"`                       // Format is "json" or "console"
+	Output     string `json:"output" env:"LOG_OUTPUT" envDefault:"stderr"`                        // Output is "stdout", "stderr" or a file path
+	Sampling   int    `json:"sampling" env:"LOG_SAMPLING"`                                        // Sampling is the number of identical entries logged per second, 0 logs all
+	Thereafter int    `json:"sampling_thereafter" env:"LOG_SAMPLING_THEREAFTER" envDefault:"100"` // Thereafter logs every Thereafter-th identical entry beyond Sampling
+}
+
+// Health - structure for storing the configuration of the readiness checks.
+type Health struct {
+	Timeout          Duration `json:"timeout" env:"HEALTH_TIMEOUT" envDefault:"2s"`                          // Timeout of a single dependency check
+	MaxDeletionQueue int      `json:"max_deletion_queue" env:"HEALTH_MAX_DELETION_QUEUE" envDefault:"10000"` // MaxDeletionQueue is the deletion backlog beyond which the service is not ready, 0 for no limit
+	ShutdownDelay    Duration `json:"shutdown_delay" env:"HEALTH_SHUTDOWN_DELAY" envDefault:"0s"`            // ShutdownDelay is the time between reporting unready and closing the servers
+	Interval         Duration `json:"interval" env:"HEALTH_INTERVAL" envDefault:"5s"`                        // Interval between the checks reported by the gRPC health service
 }
 
 // Load initializes the configuration by reading command line flags and environment variables.
@@ -48,9 +174,10 @@ func Load() (*Config, error) {
 		flag.StringVar(&cfg.DSN, "d", cfg.DSN, "Connect to database")
 		flag.StringVar(&cfg.ConfigPath, "c", cfg.ConfigPath, "Config name file")
 		flag.BoolVar(&cfg.EnableHTTPS, "s", cfg.EnableHTTPS, "enabling HTTPS connection")
-		flag.StringVar(&cfg.TrustedSubnet, "t", cfg.TrustedSubnet, "trusted subnet address")
+		flag.StringVar(&cfg.TrustedSubnet, "t", cfg.TrustedSubnet, "trusted subnets, comma-separated CIDRs")
 		flag.BoolVar(&cfg.EnableGRPC, "g", cfg.EnableGRPC, "enabling gRPC connection")
 		flag.StringVar(&cfg.RPCPort, "gp", cfg.RPCPort, "gRPC port")
+		flag.Var(&cfg.LinkCheck.Interval, "lc", "link target liveness check interval")
 
 		// Получаем путь к конфигурационному файлу из переменных окружения, если указан
 		if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
@@ -1,11 +1,14 @@
 package configuration
 
 import (
+	"encoding/json"
 	"log"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/caarlos0/env/v6"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +41,17 @@ func TestMakeConfig(t *testing.T) {
 	ast.Equal(expectedHost, cfg.Host, "Host should be equal")
 	ast.Equal(expectedBaseURL, cfg.BaseURL, "Base URL should be equal")
 }
+
+func TestDurationUnmarshal(t *testing.T) {
+	var lc LinkCheck
+	err := json.Unmarshal([]byte(`{"interval":"5m","host_interval":"250ms"}`), &lc)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, time.Duration(lc.Interval))
+	assert.Equal(t, 250*time.Millisecond, time.Duration(lc.HostInterval))
+
+	t.Setenv("LINK_CHECK_INTERVAL", "90s")
+	var c Config
+	assert.NoError(t, env.Parse(&c))
+	assert.Equal(t, 90*time.Second, time.Duration(c.LinkCheck.Interval))
+	assert.Equal(t, 10*time.Second, time.Duration(c.LinkCheck.Timeout))
+}
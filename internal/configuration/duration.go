@@ -0,0 +1,32 @@
+package configuration
+
+import "time"
+
+// Duration is a time.Duration that can be read from JSON, environment variables
+// and command-line flags in the time.ParseDuration format, e.g. "1m30s".
+type Duration time.Duration
+
+// UnmarshalText parses a duration such as "5m".
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration in the time.Duration string format.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Set implements flag.Value.
+func (d *Duration) Set(s string) error {
+	return d.UnmarshalText([]byte(s))
+}
+
+// String implements flag.Value.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+// AdminServer is a gRPC server that implements the Admin service. The Authenticator
+// interceptor admits only users with the admin role.
+type AdminServer struct {
+	pb.UnimplementedAdminServer
+	DB   *usecase.UseCase
+	Auth *auth.Auth
+}
+
+// SearchLinks retrieves the links of all users matching the request.
+func (s *AdminServer) SearchLinks(ctx context.Context, in *pb.SearchLinksRequest) (*pb.AdminLinks, error) {
+	urls, err := s.DB.DoSearchLinks(ctx, entity.LinkFilter{
+		Query:  in.Query,
+		UserID: in.UserId,
+		Limit:  int(in.Limit),
+		Offset: int(in.Offset),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Error searching links")
+	}
+
+	var response pb.AdminLinks
+	for _, url := range urls {
+		response.Links = append(response.Links, &pb.AdminLink{
+			ShortLink: url.Alias,
+			LongLink:  url.URL,
+			UserId:    url.UUID,
+			Deleted:   url.IsDeleted,
+			Disabled:  url.Disabled,
+		})
+	}
+	return &response, nil
+}
+
+// SetLinkDisabled disables or enables the redirect of a link.
+func (s *AdminServer) SetLinkDisabled(ctx context.Context, in *pb.SetLinkDisabledRequest) (*pb.Empty, error) {
+	err := s.DB.DoSetDisabled(ctx, in.ShortLink, in.Disabled)
+	if errors.Is(err, admin.ErrLinkNotFound) {
+		return nil, status.Errorf(codes.NotFound, "Link not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Error updating link")
+	}
+	return &pb.Empty{}, nil
+}
+
+// BanUser bans a user or lifts the ban.
+func (s *AdminServer) BanUser(ctx context.Context, in *pb.BanUserRequest) (*pb.Empty, error) {
+	adminID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !in.Banned {
+		err = s.Auth.Unban(ctx, in.UserId)
+		if errors.Is(err, auth.ErrBanNotFound) {
+			return nil, status.Errorf(codes.NotFound, "User is not banned")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Error lifting ban")
+		}
+		return &pb.Empty{}, nil
+	}
+
+	if in.UserId == adminID {
+		return nil, status.Errorf(codes.InvalidArgument, "Admins cannot ban themselves")
+	}
+	if _, err = s.Auth.Ban(ctx, in.UserId, adminID, in.Reason); err != nil {
+		return nil, status.Errorf(codes.Internal, "Error banning user")
+	}
+	return &pb.Empty{}, nil
+}
+
+// GetUserCounts counts the links of every user.
+func (s *AdminServer) GetUserCounts(ctx context.Context, _ *pb.Empty) (*pb.UserCounts, error) {
+	counts, err := s.DB.DoGetUserCounts(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Error counting links")
+	}
+
+	var response pb.UserCounts
+	for _, count := range counts {
+		response.Users = append(response.Users, &pb.UserCount{
+			UserId:   count.UserID,
+			Links:    int64(count.Links),
+			Deleted:  int64(count.Deleted),
+			Disabled: int64(count.Disabled),
+			Banned:   count.Banned,
+		})
+	}
+	return &response, nil
+}
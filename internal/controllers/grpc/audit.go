@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+)
+
+// MetadataRequestID is the metadata key of the request ID of a call. Calls without
+// one get a generated ID.
+const MetadataRequestID = "x-request-id"
+
+// AuditUnary returns the unary server interceptor storing the source of calls in their
+// context for the audit log.
+func AuditUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(auditSource(ctx), req)
+	}
+}
+
+// AuditStream returns the stream server interceptor storing the source of calls in
+// their context for the audit log.
+func AuditStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: auditSource(ss.Context())})
+	}
+}
+
+// auditSource returns a copy of ctx carrying the source of the call.
+func auditSource(ctx context.Context) context.Context {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataRequestID); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = generatestring.GenerateUUID()
+	}
+	return audit.NewContext(ctx, audit.Source{
+		Transport: entity.TransportGRPC,
+		RequestID: requestID,
+		ClientIP:  ratelimit.PeerIP(ctx),
+	})
+}
@@ -3,31 +3,38 @@ package grpc
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strconv"
 
-	"github.com/jackc/pgerrcode"
-	"github.com/jackc/pgx/v5/pgconn"
-
-	"github.com/nextlag/shortenerURL/internal/configuration"
 	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/nextlag/shortenerURL/proto"
 )
 
+// Deleter deletes links in the background, after the call that requested it returned.
+type Deleter interface {
+	DeleteLater(ctx context.Context, userID string, aliases []string)
+}
+
 // LinksServer is a gRPC server that implements the Links service.
 type LinksServer struct {
 	pb.UnimplementedLinksServer
-	DB *usecase.UseCase
+	DB   *usecase.UseCase
+	Auth *auth.Auth
+	// Deleter deletes the links of Del in the background, sharing the deletion queue of
+	// the HTTP server. Without it, Del deletes them before returning.
+	Deleter Deleter
+	// BaseURL prefixes the aliases of the links of GetAll, BatchShorten, ShortenStream
+	// and ListLinks. Without it, they return bare aliases.
+	BaseURL string
 }
 
-var pgErr *pgconn.PgError
-
-// Get retrieves a long link by its short link.
+// Get retrieves a long link by its short link. Disabled links are not resolved.
 func (s *LinksServer) Get(ctx context.Context, in *pb.ShortenLink) (*pb.ShortenLinkResponse, error) {
 	var response pb.ShortenLinkResponse
 	url, err := s.DB.DoGet(ctx, in.ShortenLink)
@@ -38,6 +45,9 @@ func (s *LinksServer) Get(ctx context.Context, in *pb.ShortenLink) (*pb.ShortenL
 	if url == nil {
 		return nil, status.Errorf(codes.NotFound, "Link not found")
 	}
+	if url.Disabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "Link is disabled")
+	}
 
 	response.LongLink = url.URL
 	response.DeleteStatus = url.IsDeleted
@@ -45,40 +55,37 @@ func (s *LinksServer) Get(ctx context.Context, in *pb.ShortenLink) (*pb.ShortenL
 	return &response, nil
 }
 
-// Save stores a long link and returns the corresponding short link.
+// Save stores a long link and returns the corresponding short link. A link the user
+// already shortened returns its existing short link.
 func (s *LinksServer) Save(ctx context.Context, in *pb.LongLink) (*pb.LongLinkResponse, error) {
 	var response pb.LongLinkResponse
-	userID, err := getUserID(ctx)
+	userID, err := caller(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	shortLink, err := s.DB.DoPut(ctx, in.LongLink, "", userID)
-	if err != nil {
-		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
-			return &response, nil
+	if err != nil && !errors.Is(err, psql.ErrConflict) {
+		if errors.Is(err, quota.ErrExceeded) {
+			return nil, quotaError(userID, err)
 		}
 		return nil, status.Errorf(codes.Internal, "Error saving link")
 	}
 
 	response.ShortenLink = shortLink
+	response.UserId = userID
 	return &response, nil
 }
 
 // GetAll retrieves all links for a user.
 func (s *LinksServer) GetAll(ctx context.Context, in *pb.Empty) (*pb.ListShortenLinks, error) {
-	cfg, err := configuration.Load()
-	if err != nil {
-		return nil, err
-	}
-
-	userID, err := getUserID(ctx)
+	userID, err := caller(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var response pb.ListShortenLinks
-	urls, err := s.DB.DoGetAll(ctx, userID, cfg.BaseURL)
+	response := pb.ListShortenLinks{UserId: userID}
+	urls, err := s.DB.DoGetAll(ctx, userID, s.BaseURL)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Error getting links")
 	}
@@ -95,69 +102,134 @@ func (s *LinksServer) GetAll(ctx context.Context, in *pb.Empty) (*pb.ListShorten
 
 // Del deletes links for a user with correlation_id.
 func (s *LinksServer) Del(ctx context.Context, in *pb.ListShortenLinksToDelete) (*pb.Empty, error) {
-	userID, err := getUserID(ctx)
+	userID, err := caller(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var result pb.Empty
-
-	go func() {
-		for _, alias := range in.UserLinks {
-			s.DB.DoDel(ctx, userID, []string{alias})
-		}
-	}()
+	if s.Deleter != nil {
+		s.Deleter.DeleteLater(ctx, userID, in.UserLinks)
+		return &pb.Empty{}, nil
+	}
+	for _, alias := range in.UserLinks {
+		s.DB.DoDel(ctx, userID, []string{alias})
+	}
+	return &pb.Empty{}, nil
+}
 
-	return &result, nil
+// Healthcheck reports whether the repository is available.
+func (s *LinksServer) Healthcheck(_ context.Context, _ *pb.Empty) (*pb.HealthcheckResponse, error) {
+	ok, err := s.DB.DoHealthcheck()
+	return &pb.HealthcheckResponse{IsHealthy: ok && err == nil}, nil
 }
 
-// BatchShorten processes multiple URLs in a batch and returns their shortened versions.
+// BatchShorten shortens the URLs of a batch, all or none, and returns their short links.
+// Items without a URL are skipped.
 func (s *LinksServer) BatchShorten(ctx context.Context, in *pb.BatchShortenRequest) (*pb.BatchShortenResponse, error) {
-	userID, err := getUserID(ctx)
+	userID, err := caller(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var response pb.BatchShortenResponse
+	response := pb.BatchShortenResponse{UserId: userID}
 
+	var items []*pb.BatchShortenItem
+	var urls []string
 	for _, item := range in.Items {
-		if item.OriginalUrl == "" {
-			continue
-		}
-
-		alias, err := s.DB.DoPut(ctx, item.OriginalUrl, "", userID)
-		if err != nil {
-			if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
-				// Skip URLs that fail due to integrity constraints
-				continue
-			}
-			return nil, status.Errorf(codes.Internal, "Error shortening URL")
+		if item.OriginalUrl != "" {
+			items = append(items, item)
+			urls = append(urls, item.OriginalUrl)
 		}
+	}
+	aliases, err := s.DB.DoPutBatch(ctx, urls, userID)
+	if errors.Is(err, quota.ErrExceeded) {
+		return nil, quotaError(userID, err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Error shortening URLs")
+	}
 
+	for i, item := range items {
 		response.Items = append(response.Items, &pb.BatchShortenResponseItem{
 			CorrelationId: item.CorrelationId,
-			ShortUrl:      fmt.Sprintf("%s/%s", "http://localhost:3200", alias),
+			ShortUrl:      s.shortURL(aliases[i]),
 		})
 	}
 
 	return &response, nil
 }
 
-func getUserID(ctx context.Context) (int, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return 0, status.Errorf(codes.DataLoss, "No metadata")
+// Register creates an account and returns the tokens of its first session.
+func (s *LinksServer) Register(ctx context.Context, in *pb.Credentials) (*pb.AuthResponse, error) {
+	account, tokens, err := s.Auth.Register(ctx, in.Username, in.Password)
+	switch {
+	case errors.Is(err, auth.ErrInvalidUsername), errors.Is(err, auth.ErrInvalidPassword):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, auth.ErrUsernameTaken):
+		return nil, status.Errorf(codes.AlreadyExists, "Username is already taken")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "Error registering account")
 	}
+	return authResponse(account.UserID, tokens), nil
+}
 
-	values := md.Get("userID")
-	if len(values) == 0 {
-		return 0, status.Errorf(codes.Unauthenticated, "No userID in metadata")
+// Login verifies the credentials of an account and returns the tokens of a new session.
+func (s *LinksServer) Login(ctx context.Context, in *pb.Credentials) (*pb.AuthResponse, error) {
+	account, tokens, err := s.Auth.Login(ctx, in.Username, in.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		return nil, status.Errorf(codes.Unauthenticated, "Invalid username or password")
 	}
-
-	id, err := strconv.Atoi(values[0])
 	if err != nil {
-		return 0, status.Errorf(codes.Internal, "Invalid userID format")
+		return nil, status.Errorf(codes.Internal, "Error logging in")
+	}
+	return authResponse(account.UserID, tokens), nil
+}
+
+// Refresh exchanges a refresh token for new tokens of its session.
+func (s *LinksServer) Refresh(ctx context.Context, in *pb.RefreshRequest) (*pb.AuthResponse, error) {
+	userID, tokens, err := s.Auth.Refresh(ctx, in.RefreshToken)
+	switch {
+	case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrRefreshTokenReused),
+		errors.Is(err, auth.ErrSessionNotActive), errors.Is(err, auth.ErrSessionNotFound):
+		return nil, status.Errorf(codes.Unauthenticated, "Invalid refresh token")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "Error refreshing tokens")
+	}
+	return authResponse(userID, tokens), nil
+}
+
+// authResponse converts the tokens of a session into a response.
+func authResponse(userID string, tokens *auth.Tokens) *pb.AuthResponse {
+	return &pb.AuthResponse{
+		UserId:           userID,
+		AccessToken:      tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt.Unix(),
 	}
+}
 
+// caller returns the user verified by the Authenticator interceptor.
+func caller(ctx context.Context) (string, error) {
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
 	return id, nil
 }
+
+// quotaError returns a ResourceExhausted status error with QuotaFailure details naming
+// the user and the exceeded limit.
+func quotaError(userID string, err error) error {
+	violation := &errdetails.QuotaFailure_Violation{Subject: "user:" + userID, Description: err.Error()}
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		violation.Description = exceeded.Limit + ": " + err.Error()
+	}
+	st, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{violation},
+	})
+	if detailsErr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+func TestGet(t *testing.T) {
+	store, err := inmemory.New(&configuration.Config{}, zap.NewNop())
+	require.NoError(t, err)
+	uc := usecase.New(store)
+	ctx := auth.NewContext(context.Background(), "user-1")
+	_, err = uc.DoPut(ctx, "https://example.com", "first", "user-1")
+	require.NoError(t, err)
+
+	s := &LinksServer{DB: uc}
+	got, err := s.Get(ctx, &pb.ShortenLink{ShortenLink: "first"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", got.LongLink)
+
+	require.NoError(t, uc.DoSetDisabled(ctx, "first", true))
+	_, err = s.Get(ctx, &pb.ShortenLink{ShortenLink: "first"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "disabled links are not resolved")
+}
+
+func TestSave(t *testing.T) {
+	repo := repository.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetQuota(gomock.Any(), "user-1").Return(nil, quota.ErrQuotaNotFound).AnyTimes()
+	repo.EXPECT().Put(gomock.Any(), "https://example.com", gomock.Any(), "user-1").Return("existing", psql.ErrConflict)
+	s := &LinksServer{DB: usecase.New(repo)}
+
+	got, err := s.Save(auth.NewContext(context.Background(), "user-1"), &pb.LongLink{LongLink: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "existing", got.ShortenLink, "a shortened URL returns its existing link")
+	assert.Equal(t, "user-1", got.UserId)
+}
+
+type recordingDeleter struct {
+	userID  string
+	aliases []string
+}
+
+func (d *recordingDeleter) DeleteLater(_ context.Context, userID string, aliases []string) {
+	d.userID, d.aliases = userID, aliases
+}
+
+func TestDel(t *testing.T) {
+	store, err := inmemory.New(&configuration.Config{}, zap.NewNop())
+	require.NoError(t, err)
+	uc := usecase.New(store)
+	ctx := auth.NewContext(context.Background(), "user-1")
+	_, err = uc.DoPut(ctx, "https://example.com", "first", "user-1")
+	require.NoError(t, err)
+
+	deleter := &recordingDeleter{}
+	s := &LinksServer{DB: uc, Deleter: deleter}
+	_, err = s.Del(ctx, &pb.ListShortenLinksToDelete{UserLinks: []string{"first"}})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", deleter.userID)
+	assert.Equal(t, []string{"first"}, deleter.aliases)
+	_, err = uc.DoGet(ctx, "first")
+	require.NoError(t, err, "the deleter deletes the links")
+
+	s.Deleter = nil
+	_, err = s.Del(ctx, &pb.ListShortenLinksToDelete{UserLinks: []string{"first"}})
+	require.NoError(t, err)
+	_, err = uc.DoGet(ctx, "first")
+	assert.Error(t, err, "without a deleter the links are deleted before returning")
+
+	_, err = s.Del(context.Background(), &pb.ListShortenLinksToDelete{UserLinks: []string{"first"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/nextlag/shortenerURL/internal/health"
+)
+
+// defaultHealthInterval is the interval of ReportHealth if none is given.
+const defaultHealthInterval = 5 * time.Second
+
+// ReportHealth updates the serving status of the grpc.health.v1 server, for the whole
+// server and the services, from the readiness checks every interval until ctx is done or
+// the checker shuts down. The services then stop serving at once, as the server is
+// shutting down.
+func ReportHealth(ctx context.Context, server *grpchealth.Server, checker *health.Checker, interval time.Duration, services ...string) {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+		if report := checker.Check(ctx); report.Ready() {
+			servingStatus = healthpb.HealthCheckResponse_SERVING
+		}
+		server.SetServingStatus("", servingStatus)
+		for _, service := range services {
+			server.SetServingStatus(service, servingStatus)
+		}
+
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-checker.ShuttingDown():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
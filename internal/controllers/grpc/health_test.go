@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/health"
+	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+func TestHealthcheck(t *testing.T) {
+	cfg := &configuration.Config{}
+	store, err := inmemory.New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	s := &LinksServer{DB: usecase.New(store)}
+
+	resp, err := s.Healthcheck(context.Background(), &pb.Empty{})
+	require.NoError(t, err)
+	assert.True(t, resp.IsHealthy)
+
+	cfg.FileStorage = t.TempDir() + "/missing/data.json"
+	resp, err = s.Healthcheck(context.Background(), &pb.Empty{})
+	require.NoError(t, err)
+	assert.False(t, resp.IsHealthy)
+}
+
+func TestReportHealth(t *testing.T) {
+	var failing atomic.Bool
+	checker := health.New(time.Second)
+	checker.Register("database", func(context.Context) error {
+		if failing.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	server := grpchealth.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ReportHealth(ctx, server, checker, 10*time.Millisecond, pb.Links_ServiceDesc.ServiceName)
+		close(done)
+	}()
+
+	servingStatus := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return resp.Status
+	}
+	assert.Eventually(t, func() bool {
+		return servingStatus(pb.Links_ServiceDesc.ServiceName) == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(""))
+
+	failing.Store(true)
+	assert.Eventually(t, func() bool {
+		return servingStatus("") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	failing.Store(false)
+	assert.Eventually(t, func() bool {
+		return servingStatus("") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(pb.Links_ServiceDesc.ServiceName))
+	_, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestReportHealthShutdown(t *testing.T) {
+	checker := health.New(time.Second)
+	server := grpchealth.NewServer()
+	done := make(chan struct{})
+	go func() {
+		ReportHealth(context.Background(), server, checker, time.Hour, pb.Links_ServiceDesc.ServiceName)
+		close(done)
+	}()
+
+	servingStatus := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: pb.Links_ServiceDesc.ServiceName})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return resp.Status
+	}
+	assert.Eventually(t, func() bool {
+		return servingStatus() == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	checker.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown waited for the next check")
+	}
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus())
+}
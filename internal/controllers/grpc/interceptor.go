@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+// errorDomain is the domain of the ErrorInfo details of authentication errors.
+const errorDomain = "shortener"
+
+// Reasons of the ErrorInfo details of authentication errors.
+const (
+	ReasonMissingToken = "MISSING_TOKEN" // no authorization metadata
+	ReasonInvalidToken = "INVALID_TOKEN" // the token is malformed, expired, revoked or unknown
+	ReasonScopeDenied  = "SCOPE_DENIED"  // the token lacks the scope of the method
+	ReasonRoleDenied   = "ROLE_DENIED"   // the user lacks the admin role
+	ReasonUserBanned   = "USER_BANNED"   // the user is banned
+	ReasonNotPublic    = "NOT_PUBLIC"    // the method is neither public nor scoped
+)
+
+// methodScopes contains the scope each authenticated method requires.
+// Methods that are listed neither here nor in publicMethods are denied.
+var methodScopes = map[string]string{
+	pb.Links_Save_FullMethodName:          entity.ScopeLinksWrite,
+	pb.Links_GetAll_FullMethodName:        entity.ScopeLinksRead,
+	pb.Links_Del_FullMethodName:           entity.ScopeLinksWrite,
+	pb.Links_BatchShorten_FullMethodName:  entity.ScopeLinksWrite,
+	pb.Links_ShortenStream_FullMethodName: entity.ScopeLinksWrite,
+	pb.Links_ListLinks_FullMethodName:     entity.ScopeLinksRead,
+
+	pb.Admin_SearchLinks_FullMethodName:     entity.ScopeAdmin,
+	pb.Admin_SetLinkDisabled_FullMethodName: entity.ScopeAdmin,
+	pb.Admin_BanUser_FullMethodName:         entity.ScopeAdmin,
+	pb.Admin_GetUserCounts_FullMethodName:   entity.ScopeAdmin,
+}
+
+// publicMethods contains the methods that are called without a token.
+var publicMethods = map[string]bool{
+	pb.Links_Get_FullMethodName:         true,
+	pb.Links_Healthcheck_FullMethodName: true,
+	pb.Links_Register_FullMethodName:    true,
+	pb.Links_Login_FullMethodName:       true,
+	pb.Links_Refresh_FullMethodName:     true,
+
+	healthpb.Health_Check_FullMethodName:                                     true,
+	healthpb.Health_Watch_FullMethodName:                                     true,
+	reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName:        true,
+	reflectionv1alphapb.ServerReflection_ServerReflectionInfo_FullMethodName: true,
+}
+
+// Authenticator verifies the "authorization: Bearer <token>" metadata of calls,
+// accepting the access tokens auth issues for HTTP cookies as well as API keys,
+// and stores the verified user in the context of the call.
+type Authenticator struct {
+	auth *auth.Auth
+	log  *zap.Logger
+}
+
+// NewAuthenticator creates a new Authenticator.
+func NewAuthenticator(a *auth.Auth, log *zap.Logger) *Authenticator {
+	return &Authenticator{auth: a, log: log}
+}
+
+// Unary returns the unary server interceptor.
+func (a *Authenticator) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor.
+func (a *Authenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate verifies the token of a call to the method and returns the context
+// carrying its user and the quota of its API key. Calls of public methods pass unchanged,
+// calls of methods that are not listed are rejected.
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if publicMethods[method] {
+		return ctx, nil
+	}
+	scope, ok := methodScopes[method]
+	if !ok {
+		return nil, authError(codes.Unauthenticated, ReasonNotPublic, "Method is not public", method, "")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, authError(codes.Unauthenticated, ReasonMissingToken, "Missing authorization metadata", method, scope)
+	}
+	token, ok := auth.ParseBearer(values[0])
+	if !ok {
+		return nil, authError(codes.Unauthenticated, ReasonInvalidToken, "Authorization metadata is not a Bearer token", method, scope)
+	}
+
+	userID, key, err := a.auth.CheckTokenKey(ctx, token, scope)
+	switch {
+	case errors.Is(err, auth.ErrScopeDenied):
+		return nil, authError(codes.PermissionDenied, ReasonScopeDenied, "Token lacks scope "+scope, method, scope)
+	case errors.Is(err, auth.ErrRoleDenied):
+		return nil, authError(codes.PermissionDenied, ReasonRoleDenied, "Admin role required", method, scope)
+	case errors.Is(err, auth.ErrUserBanned):
+		return nil, authError(codes.PermissionDenied, ReasonUserBanned, "User is banned", method, scope)
+	case err != nil:
+		a.log.Info("gRPC token rejected", zap.String("method", method), zap.Error(err))
+		return nil, authError(codes.Unauthenticated, ReasonInvalidToken, "Invalid token", method, scope)
+	}
+	if key != nil && key.Quota != nil {
+		ctx = quota.NewContext(ctx, *key.Quota)
+	}
+	return auth.NewContext(ctx, userID), nil
+}
+
+// authError returns a status error with ErrorInfo details naming the reason,
+// the method and its scope.
+func authError(code codes.Code, reason, msg, method, scope string) error {
+	st, err := status.New(code, msg).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"method": method, "scope": scope},
+	})
+	if err != nil {
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
+
+// contextStream is a server stream with the context set up by an interceptor, such as
+// the one carrying the verified user.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context set up by the interceptor.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/middleware/logger"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+func TestAuthenticator(t *testing.T) {
+	l := logger.SetupLogger()
+	cfg, err := configuration.Load()
+	require.NoError(t, err)
+	store, err := inmemory.New(cfg, l)
+	require.NoError(t, err)
+	a, err := auth.New(cfg.JWT, store, l)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	account, tokens, err := a.Register(ctx, "grpc-user", "correct horse")
+	require.NoError(t, err)
+	key, err := a.CreateAPIKey(ctx, account.UserID, "reader", []string{entity.ScopeLinksRead}, nil)
+	require.NoError(t, err)
+
+	interceptor := NewAuthenticator(a, l).Unary()
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return caller(ctx)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		wantUser      string
+		wantCode      codes.Code
+		wantReason    string
+	}{
+		{name: "Access token", method: pb.Links_Save_FullMethodName, authorization: "Bearer " + tokens.AccessToken, wantUser: account.UserID},
+		{name: "API key with scope", method: pb.Links_GetAll_FullMethodName, authorization: "Bearer " + key.Key, wantUser: account.UserID},
+		{name: "API key without scope", method: pb.Links_Save_FullMethodName, authorization: "Bearer " + key.Key, wantCode: codes.PermissionDenied, wantReason: ReasonScopeDenied},
+		{name: "Missing token", method: pb.Links_Del_FullMethodName, wantCode: codes.Unauthenticated, wantReason: ReasonMissingToken},
+		{name: "Not a bearer token", method: pb.Links_Del_FullMethodName, authorization: "Basic dXNlcjpwYXNz", wantCode: codes.Unauthenticated, wantReason: ReasonInvalidToken},
+		{name: "Unknown key", method: pb.Links_BatchShorten_FullMethodName, authorization: "Bearer sk_unknown", wantCode: codes.Unauthenticated, wantReason: ReasonInvalidToken},
+		{name: "Admin method without role", method: pb.Admin_GetUserCounts_FullMethodName, authorization: "Bearer " + tokens.AccessToken, wantCode: codes.PermissionDenied, wantReason: ReasonRoleDenied},
+		{name: "Public method", method: pb.Links_Get_FullMethodName, wantCode: codes.Unauthenticated},
+		{name: "Unlisted method", method: "/proto.Links/Unlisted", authorization: "Bearer " + tokens.AccessToken, wantCode: codes.Unauthenticated, wantReason: ReasonNotPublic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.Pairs("userID", account.UserID)
+			if tt.authorization != "" {
+				md.Set("authorization", tt.authorization)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			got, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if tt.wantCode == codes.OK {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantUser, got)
+				return
+			}
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, st.Code())
+			if tt.wantReason == "" {
+				return
+			}
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantReason, info.Reason)
+			assert.Equal(t, errorDomain, info.Domain)
+			assert.Equal(t, tt.method, info.Metadata["method"])
+		})
+	}
+}
+
+func TestEveryMethodIsListed(t *testing.T) {
+	for _, desc := range []grpc.ServiceDesc{pb.Links_ServiceDesc, pb.Admin_ServiceDesc} {
+		var methods []string
+		for _, m := range desc.Methods {
+			methods = append(methods, m.MethodName)
+		}
+		for _, s := range desc.Streams {
+			methods = append(methods, s.StreamName)
+		}
+		for _, name := range methods {
+			method := "/" + desc.ServiceName + "/" + name
+			_, scoped := methodScopes[method]
+			assert.True(t, scoped != publicMethods[method], "%s must be either scoped or public", method)
+		}
+	}
+}
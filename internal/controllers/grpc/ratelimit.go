@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+// NewRateLimiter creates the rate limiting interceptor of the Links service. Calls are
+// limited per peer address, and authenticated calls per user as well, so that users
+// created in bulk share the bucket of their address. It must be chained after the
+// Authenticator.
+func NewRateLimiter(cfg configuration.RateLimit, store ratelimit.Store, log *zap.Logger) *ratelimit.Interceptor {
+	write := ratelimit.New(ratelimit.GroupWrite, ratelimit.LimitOf(cfg.Write), store, log)
+	login := ratelimit.New(ratelimit.GroupAuth, ratelimit.LimitOf(cfg.Auth), store, log)
+	return ratelimit.NewInterceptor(map[string]*ratelimit.Limiter{
+		pb.Links_Save_FullMethodName:          write,
+		pb.Links_BatchShorten_FullMethodName:  write,
+		pb.Links_ShortenStream_FullMethodName: write,
+		pb.Links_Register_FullMethodName:      login,
+		pb.Links_Login_FullMethodName:         login,
+	}, addressKey, userKey)
+}
+
+// addressKey identifies the caller by the peer address.
+func addressKey(ctx context.Context) string {
+	return "ip:" + ratelimit.PeerIP(ctx)
+}
+
+// userKey identifies the caller by the authenticated user, if any.
+func userKey(ctx context.Context) string {
+	if userID, ok := auth.FromContext(ctx); ok {
+		return "user:" + userID
+	}
+	return ""
+}
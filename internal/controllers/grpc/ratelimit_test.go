@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/middleware/logger"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+func TestRateLimiter(t *testing.T) {
+	cfg := configuration.RateLimit{
+		Write: configuration.RateRule{Requests: 1, Period: configuration.Duration(time.Hour)},
+		Auth:  configuration.RateRule{Requests: 1, Period: configuration.Duration(time.Hour)},
+	}
+	interceptor := NewRateLimiter(cfg, ratelimit.NewMemoryStore(), logger.SetupLogger()).Unary()
+	handler := func(context.Context, any) (any, error) { return &pb.Empty{}, nil }
+
+	anonymous := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234}})
+	other := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 1234}})
+	user := auth.NewContext(other, "user-1")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "Login", ctx: anonymous, method: pb.Links_Login_FullMethodName},
+		{name: "Register after login", ctx: anonymous, method: pb.Links_Register_FullMethodName, wantCode: codes.ResourceExhausted},
+		{name: "Save by user", ctx: user, method: pb.Links_Save_FullMethodName},
+		{name: "Batch by user", ctx: user, method: pb.Links_BatchShorten_FullMethodName, wantCode: codes.ResourceExhausted},
+		{name: "Save by other user from same address", ctx: auth.NewContext(other, "user-2"), method: pb.Links_Save_FullMethodName, wantCode: codes.ResourceExhausted},
+		{name: "Save from other address", ctx: anonymous, method: pb.Links_Save_FullMethodName},
+		{name: "Unlimited method", ctx: user, method: pb.Links_GetAll_FullMethodName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if tt.wantCode == codes.OK {
+				require.NoError(t, err)
+				return
+			}
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantCode, st.Code())
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.RetryInfo)
+			require.True(t, ok)
+			assert.Equal(t, time.Hour, info.RetryDelay.AsDuration())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+// Page sizes of ListLinks.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// ShortenStream shortens the URLs of a stream one by one, sending the result of each URL
+// as soon as it is stored. Failed URLs get the status code and the reason in their result,
+// so one bad URL does not end the stream; URLs the user already shortened fail with
+// AlreadyExists and get their existing short URL. The stream ends when the client closes it.
+func (s *LinksServer) ShortenStream(stream pb.Links_ShortenStreamServer) error {
+	ctx := stream.Context()
+	userID, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		item, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		response := &pb.ShortenStreamResponse{CorrelationId: item.CorrelationId}
+		code, reason := codes.OK, ""
+		if item.OriginalUrl == "" {
+			code, reason = codes.InvalidArgument, "URL is empty"
+		} else {
+			alias, err := s.DB.DoPut(ctx, item.OriginalUrl, "", userID)
+			switch {
+			case errors.Is(err, quota.ErrExceeded):
+				code, reason = codes.ResourceExhausted, err.Error()
+			case errors.Is(err, psql.ErrConflict):
+				code, reason = codes.AlreadyExists, "URL is already shortened"
+				response.ShortUrl = s.shortURL(alias)
+			case err != nil:
+				code, reason = codes.Internal, "Error shortening URL"
+			default:
+				response.ShortUrl = s.shortURL(alias)
+			}
+		}
+		response.Code, response.Error = int32(code), reason
+
+		if err = stream.Send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// ListLinks streams the links of the user, reading them from the storage a page at a time,
+// so that the links of large accounts are never all held in memory.
+func (s *LinksServer) ListLinks(in *pb.ListLinksRequest, stream pb.Links_ListLinksServer) error {
+	ctx := stream.Context()
+	userID, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+
+	pageSize := int(in.PageSize)
+	switch {
+	case pageSize < 0:
+		return status.Errorf(codes.InvalidArgument, "Page size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	after := ""
+	for {
+		urls, err := s.DB.DoListLinks(ctx, userID, after, pageSize)
+		if err != nil {
+			return status.Errorf(codes.Internal, "Error getting links")
+		}
+		for _, url := range urls {
+			if err = stream.Send(&pb.UserLink{LongLink: url.URL, ShortLink: s.shortURL(url.Alias)}); err != nil {
+				return err
+			}
+		}
+		if len(urls) < pageSize {
+			return nil
+		}
+		after = urls[len(urls)-1].Alias
+	}
+}
+
+// shortURL returns the shortened URL of an alias under the base URL of the service.
+func (s *LinksServer) shortURL(alias string) string {
+	if s.BaseURL == "" {
+		return alias
+	}
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + alias
+}
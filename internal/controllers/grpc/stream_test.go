@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
+	pb "github.com/nextlag/shortenerURL/proto"
+)
+
+// streamClient serves s over an in-memory connection to calls of the user.
+func streamClient(t *testing.T, s *LinksServer, userID string) pb.LinksClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: auth.NewContext(ss.Context(), userID)})
+	}))
+	pb.RegisterLinksServer(server, s)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewLinksClient(conn)
+}
+
+func TestShortenStream(t *testing.T) {
+	store, err := inmemory.New(&configuration.Config{}, zap.NewNop())
+	require.NoError(t, err)
+	uc := usecase.New(store, usecase.WithQuota(entity.Quota{MaxLinks: 2}))
+	client := streamClient(t, &LinksServer{DB: uc, BaseURL: "http://localhost:8080/"}, "user-1")
+
+	stream, err := client.ShortenStream(context.Background())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		url      string
+		code     codes.Code
+		shortURL bool
+	}{
+		{name: "Shortened", url: "https://example.com/1", code: codes.OK, shortURL: true},
+		{name: "Empty", url: "", code: codes.InvalidArgument},
+		{name: "Shortened after a failure", url: "https://example.com/2", code: codes.OK, shortURL: true},
+		{name: "Over quota", url: "https://example.com/3", code: codes.ResourceExhausted},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := fmt.Sprint(i)
+			require.NoError(t, stream.Send(&pb.ShortenStreamRequest{CorrelationId: id, OriginalUrl: tt.url}))
+			resp, err := stream.Recv()
+			require.NoError(t, err, "every item is answered before the next is sent")
+			assert.Equal(t, id, resp.CorrelationId)
+			assert.Equal(t, tt.code, codes.Code(resp.Code))
+			if tt.shortURL {
+				assert.Regexp(t, `^http://localhost:8080/\w+$`, resp.ShortUrl)
+				assert.Empty(t, resp.Error)
+			} else {
+				assert.Empty(t, resp.ShortUrl)
+				assert.NotEmpty(t, resp.Error)
+			}
+		})
+	}
+
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestShortenStreamConflict(t *testing.T) {
+	repo := repository.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().GetQuota(gomock.Any(), "user-1").Return(nil, quota.ErrQuotaNotFound)
+	repo.EXPECT().Put(gomock.Any(), "https://example.com", gomock.Any(), "user-1").Return("existing", psql.ErrConflict)
+	client := streamClient(t, &LinksServer{DB: usecase.New(repo), BaseURL: "http://localhost:8080"}, "user-1")
+
+	stream, err := client.ShortenStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.ShortenStreamRequest{CorrelationId: "1", OriginalUrl: "https://example.com"}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, codes.AlreadyExists, codes.Code(resp.Code))
+	assert.Equal(t, "http://localhost:8080/existing", resp.ShortUrl)
+	require.NoError(t, stream.CloseSend())
+}
+
+func TestListLinks(t *testing.T) {
+	ctx := context.Background()
+	cfg := &configuration.Config{}
+	cfg.FileStorage = filepath.Join(t.TempDir(), "data.json")
+	store, err := inmemory.New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err = store.Put(ctx, fmt.Sprintf("https://example.com/%d", i), fmt.Sprintf("alias%d", i), "user-1")
+		require.NoError(t, err)
+	}
+	_, err = store.Put(ctx, "https://example.com/other", "other", "user-2")
+	require.NoError(t, err)
+	// The in-memory storage records deletions in del.json of the working directory.
+	t.Cleanup(func() { os.Remove("del.json") })
+	require.NoError(t, store.Del(ctx, "user-1", []string{"alias2"}))
+	client := streamClient(t, &LinksServer{DB: usecase.New(store), BaseURL: "http://localhost:8080"}, "user-1")
+
+	list := func(pageSize int32) ([]string, error) {
+		stream, err := client.ListLinks(ctx, &pb.ListLinksRequest{PageSize: pageSize})
+		require.NoError(t, err)
+		var links []string
+		for {
+			link, err := stream.Recv()
+			if err == io.EOF {
+				return links, nil
+			}
+			if err != nil {
+				return links, err
+			}
+			links = append(links, link.ShortLink)
+		}
+	}
+
+	want := []string{
+		"http://localhost:8080/alias0",
+		"http://localhost:8080/alias1",
+		"http://localhost:8080/alias3",
+		"http://localhost:8080/alias4",
+	}
+	for _, pageSize := range []int32{0, 1, 2, 4} {
+		links, err := list(pageSize)
+		require.NoError(t, err)
+		assert.Equal(t, want, links, "page size %d", pageSize)
+	}
+
+	_, err = list(-1)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
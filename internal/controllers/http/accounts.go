@@ -0,0 +1,95 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+// credentialsRequest is the body of a registration or login request. With Claim set,
+// the links of the anonymous user of the request's cookie move into the account.
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Claim    bool   `json:"claim"`
+}
+
+// loginFunc opens a session of an account.
+type loginFunc func(ctx context.Context, username, password string) (*entity.Account, *auth.Tokens, error)
+
+// Register handles the HTTP request for creating an account. The new account is logged in.
+func (c *Controller) Register(w http.ResponseWriter, r *http.Request) {
+	c.logIn(w, r, c.auth.Register, http.StatusCreated)
+}
+
+// Login handles the HTTP request for logging in to an account, replacing the
+// anonymous identity of the browser.
+func (c *Controller) Login(w http.ResponseWriter, r *http.Request) {
+	c.logIn(w, r, c.auth.Login, http.StatusOK)
+}
+
+// logIn decodes the credentials, opens a session with login, claims the anonymous
+// links if requested, sets the session cookies and responds with the account.
+func (c *Controller) logIn(w http.ResponseWriter, r *http.Request, login loginFunc, status int) {
+	var req credentialsRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	account, tokens, err := login(r.Context(), req.Username, req.Password)
+	switch {
+	case errors.Is(err, auth.ErrInvalidUsername), errors.Is(err, auth.ErrInvalidPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, auth.ErrUsernameTaken):
+		http.Error(w, "Username is already taken", http.StatusConflict)
+		return
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		c.log.Info("failed login", zap.String("username", req.Username))
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	case err != nil:
+		c.log.Error("failed to log in", zap.Error(err))
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Claim {
+		if account.Claimed, err = c.claim(r, account.UserID); err != nil {
+			c.log.Error("failed to claim links", zap.Error(err))
+			http.Error(w, "Failed to claim links", http.StatusInternalServerError)
+			return
+		}
+	}
+	c.auth.SetCookies(w, tokens)
+	writeJSON(w, status, account, c.log)
+}
+
+// claim moves the links of the anonymous user of the request to the user and ends the
+// anonymous session. It returns the number of claimed links; requests without an
+// anonymous session have nothing to claim.
+func (c *Controller) claim(r *http.Request, userID string) (int, error) {
+	anonymous, sessionID, err := c.auth.AnonymousUser(r)
+	if err != nil {
+		c.log.Info("no anonymous links to claim", zap.Error(err))
+		return 0, nil
+	}
+	claimed, err := c.uc.DoClaim(r.Context(), anonymous, userID)
+	if err != nil {
+		return 0, err
+	}
+	c.log.Info("links claimed", zap.String("from", anonymous), zap.String("to", userID), zap.Int("count", claimed))
+	if err = c.auth.Revoke(r.Context(), anonymous, sessionID); err != nil {
+		c.log.Error("failed to end anonymous session", zap.Error(err))
+	}
+	return claimed, nil
+}
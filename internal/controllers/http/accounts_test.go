@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+func TestRegisterClaimAndLogin(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	// An anonymous user shortens a link and gets a cookie.
+	var anonymous string
+	db.EXPECT().DoPut(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, _, _, userID string) (string, error) {
+			anonymous = userID
+			return "alias", nil
+		}).Times(1)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("http://example.com"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	cookies := w.Result().Cookies()
+
+	// Registering with claim moves the anonymous links into the account.
+	db.EXPECT().DoClaim(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, from, _ string) (int, error) {
+			assert.Equal(t, anonymous, from)
+			return 1, nil
+		}).Times(1)
+	req = httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"username": "alice", "password": "correct horse", "claim": true}`))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var account entity.Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	assert.Equal(t, 1, account.Claimed)
+	assert.NotEqual(t, anonymous, account.UserID)
+	assert.NotEmpty(t, w.Result().Cookies())
+
+	tests := []struct {
+		name           string
+		target         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Username taken", target: "/api/user/register", body: `{"username": "alice", "password": "another password"}`, expectedStatus: http.StatusConflict},
+		{name: "Invalid username", target: "/api/user/register", body: `{"username": "a b", "password": "correct horse"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Wrong password", target: "/api/user/login", body: `{"username": "alice", "password": "wrong password"}`, expectedStatus: http.StatusUnauthorized},
+		{name: "Other browser", target: "/api/user/login", body: `{"username": "alice", "password": "correct horse"}`, expectedStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var logged entity.Account
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&logged))
+				assert.Equal(t, account.UserID, logged.UserID)
+			}
+		})
+	}
+}
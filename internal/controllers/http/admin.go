@@ -0,0 +1,150 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+// banRequest is the optional body of a request to ban a user.
+type banRequest struct {
+	Reason string `json:"reason"`
+}
+
+// adminOnly passes on the requests of users with the admin role, identified by a Bearer
+// token with the admin scope or by the user cookie, with the admin stored in the context.
+func (c *Controller) adminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := c.authorize(w, r, entity.ScopeAdmin)
+		if !ok {
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), userID)))
+	})
+}
+
+// SearchLinks handles the HTTP request of an admin for the links of all users. The query
+// parameters q, user_id, limit and offset filter and page the links.
+func (c *Controller) SearchLinks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := entity.LinkFilter{Query: q.Get("q"), UserID: q.Get("user_id")}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	urls, err := c.uc.DoSearchLinks(r.Context(), filter)
+	if err != nil {
+		c.log.Error("Error searching links", zap.Error(err))
+		http.Error(w, "Error searching links", http.StatusInternalServerError)
+		return
+	}
+	if len(urls) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, urls, c.log)
+}
+
+// DisableLink handles the HTTP request of an admin for disabling the redirect of a link.
+func (c *Controller) DisableLink(w http.ResponseWriter, r *http.Request) {
+	c.setDisabled(w, r, true)
+}
+
+// EnableLink handles the HTTP request of an admin for enabling the redirect of a link again.
+func (c *Controller) EnableLink(w http.ResponseWriter, r *http.Request) {
+	c.setDisabled(w, r, false)
+}
+
+// setDisabled disables or enables the link of the request.
+func (c *Controller) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	alias := chi.URLParam(r, "id")
+	err := c.uc.DoSetDisabled(r.Context(), alias, disabled)
+	if errors.Is(err, admin.ErrLinkNotFound) {
+		http.Error(w, "URL not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error updating link", zap.Error(err))
+		http.Error(w, "Error updating link", http.StatusInternalServerError)
+		return
+	}
+	adminID, _ := auth.FromContext(r.Context())
+	c.log.Info("link moderated", zap.String("alias", alias), zap.Bool("disabled", disabled), zap.String("admin", adminID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUserCounts handles the HTTP request of an admin for the link counts of every user.
+func (c *Controller) GetUserCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := c.uc.DoGetUserCounts(r.Context())
+	if err != nil {
+		c.log.Error("Error counting links", zap.Error(err))
+		http.Error(w, "Error counting links", http.StatusInternalServerError)
+		return
+	}
+	if len(counts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, counts, c.log)
+}
+
+// BanUser handles the HTTP request of an admin for banning a user. The JSON body may
+// give the reason of the ban.
+func (c *Controller) BanUser(w http.ResponseWriter, r *http.Request) {
+	var req banRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil && !errors.Is(err, io.EOF) {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	adminID, _ := auth.FromContext(r.Context())
+	if userID == adminID {
+		http.Error(w, "Admins cannot ban themselves", http.StatusBadRequest)
+		return
+	}
+	ban, err := c.auth.Ban(r.Context(), userID, adminID, req.Reason)
+	if err != nil {
+		c.log.Error("Error banning user", zap.Error(err))
+		http.Error(w, "Error banning user", http.StatusInternalServerError)
+		return
+	}
+	c.log.Info("user banned", zap.String("user", userID), zap.String("admin", adminID))
+	writeJSON(w, http.StatusCreated, ban, c.log)
+}
+
+// UnbanUser handles the HTTP request of an admin for lifting the ban of a user.
+func (c *Controller) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	err := c.auth.Unban(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, auth.ErrBanNotFound) {
+		http.Error(w, "User is not banned", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error lifting ban", zap.Error(err))
+		http.Error(w, "Error lifting ban", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
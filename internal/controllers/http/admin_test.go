@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+)
+
+func TestAdmin(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	register := func(username string) (*entity.Account, []*http.Cookie) {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"username": "`+username+`", "password": "correct horse"}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var account entity.Account
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+		return &account, w.Result().Cookies()
+	}
+	root, rootCookies := registerAdmin(t, ctrl, r, "root")
+	user, userCookies := register("mallory")
+
+	db.EXPECT().DoSearchLinks(gomock.Any(), entity.LinkFilter{Query: "example", Limit: 10}).
+		Return([]*entity.URL{{UUID: user.UserID, Alias: "abc", URL: "http://example.com"}}, nil).Times(1)
+	db.EXPECT().DoSetDisabled(gomock.Any(), "abc", true).Return(nil).Times(1)
+	db.EXPECT().DoSetDisabled(gomock.Any(), "missing", false).Return(admin.ErrLinkNotFound).Times(1)
+	db.EXPECT().DoGetUserCounts(gomock.Any()).Return([]*entity.UserCount{{UserID: user.UserID, Links: 1, Disabled: 1, Banned: true}}, nil).Times(1)
+	db.EXPECT().DoGetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		cookies        []*http.Cookie
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Not an admin", method: http.MethodGet, target: "/api/admin/users", cookies: userCookies, expectedStatus: http.StatusForbidden},
+		{name: "No cookie", method: http.MethodGet, target: "/api/admin/links", expectedStatus: http.StatusForbidden},
+		{name: "Search links", method: http.MethodGet, target: "/api/admin/links?q=example&limit=10", cookies: rootCookies, expectedStatus: http.StatusOK, expectedBody: `"short_url":"abc"`},
+		{name: "Invalid limit", method: http.MethodGet, target: "/api/admin/links?limit=many", cookies: rootCookies, expectedStatus: http.StatusBadRequest},
+		{name: "Disable link", method: http.MethodPost, target: "/api/admin/links/abc/disable", cookies: rootCookies, expectedStatus: http.StatusNoContent},
+		{name: "Enable missing link", method: http.MethodPost, target: "/api/admin/links/missing/enable", cookies: rootCookies, expectedStatus: http.StatusNotFound},
+		{name: "Ban self", method: http.MethodPost, target: "/api/admin/users/" + root.UserID + "/ban", cookies: rootCookies, expectedStatus: http.StatusBadRequest},
+		{name: "Ban user", method: http.MethodPost, target: "/api/admin/users/" + user.UserID + "/ban", cookies: rootCookies, expectedStatus: http.StatusCreated, expectedBody: `"banned_by":"` + root.UserID + `"`},
+		{name: "User counts", method: http.MethodGet, target: "/api/admin/users", cookies: rootCookies, expectedStatus: http.StatusOK, expectedBody: `"banned":true`},
+		{name: "Banned user", method: http.MethodGet, target: "/api/user/urls", cookies: userCookies, expectedStatus: http.StatusForbidden},
+		{name: "Unban user", method: http.MethodDelete, target: "/api/admin/users/" + user.UserID + "/ban", cookies: rootCookies, expectedStatus: http.StatusNoContent},
+		{name: "Unbanned user", method: http.MethodGet, target: "/api/user/urls", cookies: userCookies, expectedStatus: http.StatusNoContent},
+		{name: "Unban again", method: http.MethodDelete, target: "/api/admin/users/" + user.UserID + "/ban", cookies: rootCookies, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminController(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	cfg := *ctrl.cfg
+	cfg.AdminServer.Address = "unix:/run/shortener/admin.sock"
+	ctrl.cfg = &cfg
+	public := ctrl.Controller(chi.NewRouter())
+	admin := ctrl.AdminController(chi.NewRouter())
+
+	db.EXPECT().DoHealthcheck().Return(true, nil).Times(1)
+	db.EXPECT().DoGetStats(gomock.Any()).Return([]byte(`{"urls":1,"users":1}`), nil).Times(1)
+
+	tests := []struct {
+		name           string
+		handler        http.Handler
+		target         string
+		expectedStatus int
+	}{
+		{name: "Public pprof", handler: public, target: "/debug/pprof/", expectedStatus: http.StatusNotFound},
+		{name: "Public stats", handler: public, target: "/api/internal/stats", expectedStatus: http.StatusNotFound},
+		{name: "Public admin API", handler: public, target: "/api/admin/users", expectedStatus: http.StatusNotFound},
+		{name: "Admin pprof", handler: admin, target: "/debug/pprof/", expectedStatus: http.StatusOK},
+		{name: "Admin stats", handler: admin, target: "/api/internal/stats", expectedStatus: http.StatusOK},
+		{name: "Admin health", handler: admin, target: "/ping", expectedStatus: http.StatusOK},
+		{name: "Admin API requires admin", handler: admin, target: "/api/admin/users", expectedStatus: http.StatusForbidden},
+		{name: "No redirects on admin listener", handler: admin, target: "/abc", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.RemoteAddr = "@"
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
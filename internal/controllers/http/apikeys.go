@@ -0,0 +1,135 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+)
+
+// apiKeyRequest is the body of a request to create an API key.
+type apiKeyRequest struct {
+	Name   string        `json:"name"`
+	Scopes []string      `json:"scopes"`
+	Quota  *entity.Quota `json:"quota,omitempty"`
+}
+
+// authorize identifies the user of the request by a Bearer token with the scope or by the
+// user cookie. If the request is rejected, it writes the error response and returns false.
+func (c *Controller) authorize(w http.ResponseWriter, r *http.Request, scope string) (string, bool) {
+	_, userID, ok := c.authorizeContext(w, r, scope)
+	return userID, ok
+}
+
+// authorizeContext authorizes the request as authorize does and also returns its context,
+// carrying the quota of the API key the request was made with.
+func (c *Controller) authorizeContext(w http.ResponseWriter, r *http.Request, scope string) (context.Context, string, bool) {
+	userID, key, err := c.auth.AuthenticateKey(w, r, scope)
+	switch {
+	case err == nil:
+		ctx := r.Context()
+		if key != nil && key.Quota != nil {
+			ctx = quota.NewContext(ctx, *key.Quota)
+		}
+		return ctx, userID, true
+	case errors.Is(err, auth.ErrScopeDenied):
+		c.log.Info("token scope denied", zap.String("scope", scope))
+		http.Error(w, "Forbidden: token lacks scope "+scope, http.StatusForbidden)
+	case errors.Is(err, auth.ErrRoleDenied):
+		c.log.Info("role denied", zap.String("scope", scope))
+		http.Error(w, "Forbidden: admin role required", http.StatusForbidden)
+	case errors.Is(err, auth.ErrUserBanned):
+		http.Error(w, "Forbidden: user is banned", http.StatusForbidden)
+	default:
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return nil, "", false
+}
+
+// AddAPIKey handles the HTTP request for creating an API key. It decodes the name and
+// scopes from the JSON body and responds with the key including its secret, which
+// is not shown again.
+func (c *Controller) AddAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req apiKeyRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	key, err := c.auth.CreateAPIKey(r.Context(), userID, req.Name, req.Scopes, req.Quota)
+	if errors.Is(err, auth.ErrNoScopes) || errors.Is(err, auth.ErrUnknownScope) || errors.Is(err, quota.ErrInvalidQuota) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, auth.ErrRoleDenied) {
+		http.Error(w, "Forbidden: admin role required", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		c.log.Error("failed to create API key", zap.Error(err))
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, key, c.log)
+}
+
+// GetAPIKeys handles the HTTP request for listing the API keys of a user.
+func (c *Controller) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := c.auth.APIKeys(r.Context(), userID)
+	if err != nil {
+		c.log.Error("Error getting API keys", zap.Error(err))
+		http.Error(w, "Error retrieving API keys", http.StatusInternalServerError)
+		return
+	}
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, keys, c.log)
+}
+
+// DelAPIKey handles the HTTP request for revoking an API key of a user.
+func (c *Controller) DelAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = c.auth.RevokeAPIKey(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, auth.ErrAPIKeyNotFound) {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error revoking API key", zap.Error(err))
+		http.Error(w, "Error revoking API key", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+func TestAPIKeyScopes(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/keys", strings.NewReader(`{"name": "ci", "scopes": ["links:read"]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var key entity.APIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&key))
+	require.NotEmpty(t, key.Key)
+
+	db.EXPECT().DoGetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*entity.URL{{Alias: "a", URL: "http://example.com"}}, nil).Times(1)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "Scope granted", method: http.MethodGet, target: "/api/user/urls", authorization: "Bearer " + key.Key, expectedStatus: http.StatusOK},
+		{name: "Scope missing", method: http.MethodPost, target: "/api/shorten", body: `{"url": "http://example.com"}`, authorization: "Bearer " + key.Key, expectedStatus: http.StatusForbidden},
+		{name: "Unknown key", method: http.MethodGet, target: "/api/user/urls", authorization: "Bearer sk_unknown", expectedStatus: http.StatusUnauthorized},
+		{name: "Not a bearer token", method: http.MethodGet, target: "/api/user/urls", authorization: "Basic dXNlcjpwYXNz", expectedStatus: http.StatusUnauthorized},
+		{name: "Stats without stats scope", method: http.MethodGet, target: "/api/internal/stats", authorization: "Bearer " + key.Key, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Empty(t, w.Result().Cookies(), "API key requests get no cookies")
+		})
+	}
+}
+
+func TestStatsKeyNeedsAdmin(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+	db.EXPECT().DoGetStats(gomock.Any()).Return([]byte(`{"urls":1,"users":1}`), nil).Times(1)
+
+	createKey := func(cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/keys", strings.NewReader(`{"name": "stats", "scopes": ["stats:read"]}`))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusForbidden, createKey(nil).Code, "anonymous users get no stats keys")
+
+	_, cookies := registerAdmin(t, ctrl, r, "root")
+	w := createKey(cookies)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var key entity.APIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&key))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+	req.RemoteAddr = "203.0.113.5:1234"
+	req.Header.Set("Authorization", "Bearer "+key.Key)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "keys of admins read the stats from anywhere")
+}
@@ -0,0 +1,75 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+)
+
+// auditSource stores the source of the request in its context for the audit log.
+func (c *Controller) auditSource(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.NewContext(r.Context(), audit.Source{
+			Transport: entity.TransportHTTP,
+			RequestID: middleware.GetReqID(r.Context()),
+			ClientIP:  c.clientIP(r),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetAudit handles the HTTP request of an admin for the audit log. The query parameters
+// alias and actor select a link or a user, from and to (RFC 3339) a time range, and
+// limit and offset page the entries.
+func (c *Controller) GetAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := entity.AuditFilter{Alias: q.Get("alias"), Actor: q.Get("actor")}
+	var err error
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if v := q.Get(param.name); v != "" {
+			if *param.dst, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(w, "Invalid "+param.name+", expected RFC 3339", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := c.uc.DoGetAudit(r.Context(), filter)
+	if errors.Is(err, audit.ErrInvalidRange) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error getting audit log", zap.Error(err))
+		http.Error(w, "Error retrieving audit log", http.StatusInternalServerError)
+		return
+	}
+	if len(entries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries, c.log)
+}
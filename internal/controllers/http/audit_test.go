@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+)
+
+func TestGetAudit(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	_, adminCookies := registerAdmin(t, ctrl, r, "auditor")
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	db.EXPECT().DoGetAudit(gomock.Any(), entity.AuditFilter{Alias: "abc", Actor: "admin", From: from, To: from.Add(24 * time.Hour), Limit: 10}).
+		Return([]*entity.AuditEntry{{ID: "1", Action: audit.ActionLinkDelete, Alias: "abc", Actor: "admin", Transport: entity.TransportHTTP}}, nil).Times(1)
+	db.EXPECT().DoGetAudit(gomock.Any(), entity.AuditFilter{Actor: "nobody"}).Return(nil, nil).Times(1)
+	db.EXPECT().DoGetAudit(gomock.Any(), entity.AuditFilter{From: from, To: from.Add(-time.Hour)}).Return(nil, audit.ErrInvalidRange).Times(1)
+
+	tests := []struct {
+		name           string
+		target         string
+		cookies        []*http.Cookie
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Not an admin", target: "/api/admin/audit", expectedStatus: http.StatusForbidden},
+		{name: "Entries", target: "/api/admin/audit?alias=abc&actor=admin&from=2024-05-01T00:00:00Z&to=2024-05-02T00:00:00Z&limit=10", cookies: adminCookies, expectedStatus: http.StatusOK, expectedBody: `"action":"link.delete"`},
+		{name: "No entries", target: "/api/admin/audit?actor=nobody", cookies: adminCookies, expectedStatus: http.StatusNoContent},
+		{name: "Invalid time", target: "/api/admin/audit?from=yesterday", cookies: adminCookies, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid range", target: "/api/admin/audit?from=2024-05-01T00:00:00Z&to=2024-04-30T23:00:00Z", cookies: adminCookies, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
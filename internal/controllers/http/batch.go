@@ -11,7 +11,7 @@ import (
 	"github.com/go-chi/render"
 	"go.uber.org/zap"
 
-	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/entity"
 )
 
 // BatchShortenRequest represents a request structure for shortening multiple URLs.
@@ -27,7 +27,7 @@ type BatchShortenResponse []struct {
 }
 
 // Batch handles the HTTP request for shortening multiple URLs.
-// It decodes the JSON request, processes each URL, and returns the shortened URLs in the response.
+// It decodes the JSON request, shortens the URLs all or none, and returns the shortened URLs in the response.
 // If an error occurs during processing, it logs the error and responds with an appropriate message.
 func (c *Controller) Batch(w http.ResponseWriter, r *http.Request) {
 	var req BatchShortenRequest
@@ -46,25 +46,31 @@ func (c *Controller) Batch(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, Error("failed to decode request"))
 		return
 	}
-	uuid, err := auth.CheckCookie(w, r, c.log)
+	ctx, uuid, ok := c.authorizeContext(w, r, entity.ScopeLinksWrite)
+	if !ok {
+		return
+	}
+	urls := make([]string, len(req))
+	for i, url := range req {
+		urls[i] = url.OriginalURL
+	}
+	aliases, err := c.uc.DoPutBatch(ctx, urls, uuid)
+	if c.quotaExceeded(w, err) {
+		return
+	}
 	if err != nil {
-		c.log.Error("Error getting cookie: ", zap.Error(err))
+		c.log.Error("failed to shorten batch", zap.Error(err))
+		http.Error(w, "Error shortening URLs", http.StatusInternalServerError)
 		return
 	}
 
-	for _, url := range req {
-		alias, err := c.uc.DoPut(r.Context(), url.OriginalURL, "", uuid)
-		if err != nil {
-			c.log.Error("URL", zap.Error(err))
-			return
-		}
-
+	for i, url := range req {
 		resp = append(resp, struct {
 			CorrelationID string `json:"correlation_id"`
 			ShortURL      string `json:"short_url"`
 		}{
 			CorrelationID: url.CorrelationID,
-			ShortURL:      fmt.Sprintf("%s/%s", c.cfg.BaseURL, alias),
+			ShortURL:      fmt.Sprintf("%s/%s", c.cfg.BaseURL, aliases[i]),
 		})
 	}
 
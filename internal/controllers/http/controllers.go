@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"sync"
+	"sync/atomic"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -12,8 +13,15 @@ import (
 
 	"github.com/nextlag/shortenerURL/internal/configuration"
 	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/health"
+	"github.com/nextlag/shortenerURL/internal/metrics"
 	"github.com/nextlag/shortenerURL/internal/middleware/gzip"
 	mwLogger "github.com/nextlag/shortenerURL/internal/middleware/logger"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+	"github.com/nextlag/shortenerURL/internal/middleware/trusted"
+	"github.com/nextlag/shortenerURL/internal/tracing"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc"
 )
 
 // UseCase defines the interface for the application's use case layer.
@@ -21,54 +29,204 @@ import (
 //go:generate mockgen -destination=mocks/mocks.go -package=mocks github.com/nextlag/shortenerURL/internal/controllers/http UseCase
 type UseCase interface {
 	DoGet(ctx context.Context, alias string) (*entity.URL, error)
-	DoGetAll(ctx context.Context, userID int, url string) ([]*entity.URL, error)
-	DoPut(ctx context.Context, url string, alias string, uuid int) (string, error)
-	DoDel(ctx context.Context, id int, aliases []string)
+	DoGetAll(ctx context.Context, userID string, url string) ([]*entity.URL, error)
+	DoPut(ctx context.Context, url string, alias string, uuid string) (string, error)
+	DoPutBatch(ctx context.Context, urls []string, uuid string) ([]string, error)
+	DoDel(ctx context.Context, id string, aliases []string)
+	DoClaim(ctx context.Context, fromUserID, toUserID string) (int, error)
+	DoSearchLinks(ctx context.Context, filter entity.LinkFilter) ([]*entity.URL, error)
+	DoSetDisabled(ctx context.Context, alias string, disabled bool) error
+	DoGetUserCounts(ctx context.Context) ([]*entity.UserCount, error)
+	DoReport(ctx context.Context, alias, reason string) (*entity.Report, error)
+	DoGetReports(ctx context.Context, status string) ([]*entity.Report, error)
+	DoReviewReport(ctx context.Context, id, adminID, action string) (*entity.Report, error)
+	DoGetAudit(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error)
+	DoGetQuota(ctx context.Context, userID string) (*entity.QuotaUsage, error)
+	DoSetQuota(ctx context.Context, userID string, q *entity.Quota) error
 	DoHealthcheck() (bool, error)
 	DoGetStats(ctx context.Context) ([]byte, error)
+	DoAddWebhook(ctx context.Context, userID string, hook *entity.Webhook) (*entity.Webhook, error)
+	DoGetWebhooks(ctx context.Context, userID string) ([]*entity.Webhook, error)
+	DoDelWebhook(ctx context.Context, userID string, id string) error
+	DoGetDeliveries(ctx context.Context, userID string, webhookID string) ([]*entity.Delivery, error)
+	DoGetDeadLetters(ctx context.Context, userID string, webhookID string) ([]*entity.Delivery, error)
 }
 
 // Controller represents the application's HTTP controller.
 type Controller struct {
-	uc  UseCase
-	wg  *sync.WaitGroup
-	log *zap.Logger
-	cfg *configuration.Config
+	uc     UseCase
+	auth   *auth.Auth
+	oidc   *oidc.Provider  // provider of OpenID Connect logins, may be nil
+	limits ratelimit.Store // store of the rate limit buckets
+	trust  *trusted.Resolver
+	guard  *trusted.Guard // guard of the routes for trusted subnets only
+	// metrics of the requests, served on /metrics, may be nil
+	metrics *metrics.Metrics
+	// tracer of the requests, may be nil
+	tracer *tracing.Tracer
+	// logLevel is the level of the application log admins may change, may be nil
+	logLevel *zap.AtomicLevel
+	// health checks the dependencies for /readyz, may be nil
+	health *health.Checker
+	// deleting is the number of links accepted for deletion and not deleted yet
+	deleting atomic.Int64
+	wg       *sync.WaitGroup
+	log      *zap.Logger
+	cfg      *configuration.Config
+}
+
+// Option configures a Controller.
+type Option func(*Controller)
+
+// WithOIDC enables logins through the OpenID Connect provider.
+func WithOIDC(p *oidc.Provider) Option {
+	return func(c *Controller) {
+		c.oidc = p
+	}
 }
 
 // New creates a new Controller.
-func New(uc UseCase, wg *sync.WaitGroup, cfg *configuration.Config, log *zap.Logger) *Controller {
-	return &Controller{uc: uc, wg: wg, cfg: cfg, log: log}
+func New(uc UseCase, a *auth.Auth, wg *sync.WaitGroup, cfg *configuration.Config, log *zap.Logger, opts ...Option) *Controller {
+	c := &Controller{uc: uc, auth: a, wg: wg, cfg: cfg, log: log, limits: ratelimit.NewMemoryStore()}
+	c.trust, c.guard = newTrust(cfg, log)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// Controller sets up the application's HTTP routing and middleware.
+// Controller sets up the application's HTTP routing and middleware. Without a separate
+// admin listener, the profiling, stats, metrics, health and admin routes are served here
+// as well, with pprof and metrics restricted to the trusted subnets.
 func (c *Controller) Controller(handler *chi.Mux) *chi.Mux {
 	handler.Use(middleware.RequestID)
+	if c.metrics != nil {
+		handler.Use(c.metrics.Handler)
+	}
+	if c.tracer != nil {
+		handler.Use(c.tracer.Handler)
+	}
+	handler.Use(c.auditSource)
 	handler.Use(mwLogger.New(c.log, c.cfg))
-	handler.Use(middleware.Logger)
 	handler.Use(gzip.New())
 	handler.Use(middleware.Recoverer)
 
+	write := c.rateLimit(ratelimit.GroupWrite, c.cfg.RateLimit.Write)
+	login := c.rateLimit(ratelimit.GroupAuth, c.cfg.RateLimit.Auth)
+	reports := c.rateLimit(ratelimit.GroupReport, c.cfg.RateLimit.Report)
+
 	// Set up routes with middleware
 	handler.Group(func(r chi.Router) {
 		r.Get("/{id}", c.Get)
+		r.With(reports).Post("/{id}/report", c.Report)
 		r.Get("/api/user/urls", c.GetAll)
-		r.Get("/ping", c.HealthCheck)
-		r.Get("/api/internal/stats", c.GetStatsHandler)
-		r.Post("/api/shorten", c.Shorten)
-		r.Post("/api/shorten/batch", c.Batch)
-		r.Post("/", c.Save)
+		r.With(write).Post("/api/shorten", c.Shorten)
+		r.With(write).Post("/api/shorten/batch", c.Batch)
+		r.With(write).Post("/", c.Save)
 		r.Delete("/api/user/urls", c.Del)
+		r.Post("/api/user/webhooks", c.AddWebhook)
+		r.Get("/api/user/webhooks", c.GetWebhooks)
+		r.Delete("/api/user/webhooks/{id}", c.DelWebhook)
+		r.Get("/api/user/webhooks/{id}/{kind:deliveries|dead-letters}", c.GetDeliveries)
+		r.Get("/api/user/sessions", c.GetSessions)
+		r.Delete("/api/user/sessions/{id}", c.DelSession)
+		r.Post("/api/user/logout", c.Logout)
+		r.With(login).Post("/api/user/register", c.Register)
+		r.With(login).Post("/api/user/login", c.Login)
+		if c.oidc != nil {
+			r.With(login).Get("/api/user/oidc/login", c.OIDCLogin)
+			r.Get(OIDCCallbackPath, c.OIDCCallback)
+		}
+		r.Post("/api/user/keys", c.AddAPIKey)
+		r.Get("/api/user/keys", c.GetAPIKeys)
+		r.Delete("/api/user/keys/{id}", c.DelAPIKey)
+		r.Get("/api/user/quota", c.GetQuota)
+	})
+
+	if c.cfg.AdminServer.Address == "" {
+		c.internalRoutes(handler, false)
+	}
+	return handler
+}
+
+// AdminController sets up the routing of the separate admin listener: profiling, stats,
+// metrics, health and the admin API. The listener is trusted by its configuration, being
+// bound to a private address or a unix socket, so its profiling, stats and metrics routes
+// skip the trusted subnet check. The admin API still requires the admin role.
+func (c *Controller) AdminController(handler *chi.Mux) *chi.Mux {
+	handler.Use(middleware.RequestID)
+	if c.metrics != nil {
+		handler.Use(c.metrics.Handler)
+	}
+	if c.tracer != nil {
+		handler.Use(c.tracer.Handler)
+	}
+	handler.Use(c.auditSource)
+	handler.Use(mwLogger.New(c.log, c.cfg))
+	handler.Use(middleware.Recoverer)
+
+	c.internalRoutes(handler, true)
+	return handler
+}
+
+// internalRoutes sets up the profiling, stats, metrics, health and admin routes. Unless
+// they are served by the trusted admin listener, pprof, stats and metrics require a
+// trusted client, and the readiness details are reported to trusted clients only.
+func (c *Controller) internalRoutes(handler *chi.Mux, trustedListener bool) {
+	handler.Get("/ping", c.HealthCheck)
+	handler.Get("/livez", c.Livez)
+	if c.health != nil {
+		if trustedListener {
+			handler.Get("/readyz", c.Readyz)
+		} else {
+			handler.Get("/readyz", c.PublicReadyz)
+		}
+	}
+	if trustedListener {
+		handler.Get("/api/internal/stats", c.writeStats)
+	} else {
+		handler.Get("/api/internal/stats", c.GetStatsHandler)
+	}
+	if c.metrics != nil {
+		if trustedListener {
+			handler.Method(http.MethodGet, "/metrics", c.metrics.Endpoint())
+		} else {
+			handler.With(c.guard.Handler).Method(http.MethodGet, "/metrics", c.metrics.Endpoint())
+		}
+	}
+
+	// Admin routes check the role of the user
+	handler.Route("/api/admin", func(r chi.Router) {
+		if c.cfg.Admin.TrustedOnly && !trustedListener {
+			r.Use(c.guard.Handler)
+		}
+		r.Use(c.adminOnly)
+		r.Get("/links", c.SearchLinks)
+		r.Post("/links/{id}/disable", c.DisableLink)
+		r.Post("/links/{id}/enable", c.EnableLink)
+		r.Get("/users", c.GetUserCounts)
+		r.Post("/users/{id}/ban", c.BanUser)
+		r.Delete("/users/{id}/ban", c.UnbanUser)
+		r.Put("/users/{id}/quota", c.SetUserQuota)
+		r.Delete("/users/{id}/quota", c.DelUserQuota)
+		r.Get("/reports", c.GetReports)
+		r.Post("/reports/{id}/review", c.ReviewReport)
+		r.Get("/audit", c.GetAudit)
+		if c.logLevel != nil {
+			r.Get("/log/level", c.GetLogLevel)
+			r.Put("/log/level", c.SetLogLevel)
+		}
 	})
 
 	// Add pprof routes
 	handler.Route("/debug/pprof", func(r chi.Router) {
+		if !trustedListener {
+			r.Use(c.guard.Handler)
+		}
 		r.Handle("/", http.HandlerFunc(pprof.Index))
 		r.Handle("/cmdline", http.HandlerFunc(pprof.Cmdline))
 		r.Handle("/profile", http.HandlerFunc(pprof.Profile))
 		r.Handle("/symbol", http.HandlerFunc(pprof.Symbol))
 		r.Handle("/trace", http.HandlerFunc(pprof.Trace))
 	})
-
-	return handler
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"encoding/json"
 	"io"
 	"log"
 	"net/http"
@@ -12,13 +13,16 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/nextlag/shortenerURL/internal/configuration"
 	"github.com/nextlag/shortenerURL/internal/controllers/http/mocks"
 	"github.com/nextlag/shortenerURL/internal/entity"
 	"github.com/nextlag/shortenerURL/internal/middleware/logger"
 	"github.com/nextlag/shortenerURL/internal/usecase"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
 )
 
 func Ctrl(t *testing.T) (*Controller, *mocks.MockUseCase, *usecase.UseCase) {
@@ -36,10 +40,32 @@ func Ctrl(t *testing.T) (*Controller, *mocks.MockUseCase, *usecase.UseCase) {
 	repo := repository.NewMockRepository(mockCtl)
 	uc := usecase.New(repo)
 	wg := sync.WaitGroup{}
-	controller := New(db, &wg, cfg, l)
+	sessions, err := inmemory.New(cfg, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := auth.New(cfg.JWT, sessions, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := New(db, a, &wg, cfg, l)
 	return controller, db, uc
 }
 
+// registerAdmin registers an account through the router and grants its user the
+// admin role. It returns the account and its cookies.
+func registerAdmin(t *testing.T, c *Controller, r http.Handler, username string) (*entity.Account, []*http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"username": "`+username+`", "password": "correct horse"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var account entity.Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	auth.WithAdmins(account.UserID)(c.auth)
+	return &account, w.Result().Cookies()
+}
+
 func TestController(t *testing.T) {
 	ctrl, db, _ := Ctrl(t)
 	r := chi.NewRouter()
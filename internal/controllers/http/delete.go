@@ -2,47 +2,56 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"go.uber.org/zap"
 
-	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/entity"
 )
 
 // Del handles the HTTP request for deleting URLs associated with a user.
 // It checks the user's authentication, decodes the request body to get the list of URLs to delete,
 // and initiates the use case layer to perform the deletion asynchronously.
 func (c *Controller) Del(w http.ResponseWriter, r *http.Request) {
-	uuid, err := auth.CheckCookie(w, r, c.log)
-	if err != nil {
-		c.log.Error("Error getting cookie: ", zap.Error(err))
-		http.Error(w, "You have no links to delete", http.StatusUnauthorized)
+	uuid, ok := c.authorize(w, r, entity.ScopeLinksWrite)
+	if !ok {
 		return
 	}
 
 	var aliases []string
-	if err = json.NewDecoder(r.Body).Decode(&aliases); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&aliases); err != nil {
 		c.log.Error("Failed to read json: ", zap.Error(err))
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	c.wg.Add(1)
-	go func() {
-		defer c.wg.Done()
-		for _, alias := range aliases {
-			c.uc.DoDel(r.Context(), uuid, []string{alias})
-		}
-	}()
+	c.DeleteLater(r.Context(), uuid, aliases)
 
 	w.Header().Set("Content-Type", "application/json")
 	response := map[string]interface{}{
 		"aliases sent for deletion": aliases,
 	}
 	w.WriteHeader(http.StatusAccepted)
-	if err = json.NewEncoder(w).Encode(response); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		c.log.Error("Failed to write response: ", zap.Error(err))
 		http.Error(w, "Failed to write response", http.StatusInternalServerError)
 	}
 }
+
+// DeleteLater deletes the links of the user in the background, one by one, and returns at
+// once. The deletions outlive the request: they keep the values of ctx, such as its trace
+// and audit source, but not its cancellation, and shutdown waits for them.
+func (c *Controller) DeleteLater(ctx context.Context, userID string, aliases []string) {
+	ctx = context.WithoutCancel(ctx)
+	c.wg.Add(1)
+	c.deleting.Add(int64(len(aliases)))
+	go func() {
+		defer c.wg.Done()
+		for _, alias := range aliases {
+			c.uc.DoDel(ctx, userID, []string{alias})
+			c.deleting.Add(-1)
+		}
+	}()
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteLater(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, alias := range []string{"first", "second"} {
+		db.EXPECT().DoDel(gomock.Any(), "user", []string{alias}).Do(func(ctx context.Context, _ string, _ []string) {
+			assert.NoError(t, ctx.Err(), "deletions outlive the request")
+		})
+	}
+
+	ctrl.DeleteLater(ctx, "user", []string{"first", "second"})
+	cancel()
+	ctrl.wg.Wait()
+	assert.Equal(t, 0, ctrl.PendingDeletions())
+}
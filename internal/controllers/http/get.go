@@ -10,7 +10,8 @@ import (
 
 // Get handles GET requests for redirecting to the original URL.
 // It extracts the "id" parameter from the URL, searches for the original URL in the storage,
-// and redirects to it. If the URL is marked as deleted, it returns a 410 Gone status.
+// and redirects to it. If the URL is marked as deleted it returns a 410 Gone status, and if it
+// was disabled by an admin, a warning page with 410 Gone instead of the redirect.
 func (c *Controller) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -28,6 +29,11 @@ func (c *Controller) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if url.Disabled {
+		c.writeInterstitial(w, id)
+		return
+	}
+
 	w.Header().Set("Location", url.URL)
 	w.WriteHeader(http.StatusTemporaryRedirect)
 }
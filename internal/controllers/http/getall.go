@@ -7,7 +7,7 @@ import (
 
 	"go.uber.org/zap"
 
-	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/entity"
 )
 
 // GetAll handles the HTTP request for retrieving all URLs associated with a user.
@@ -15,10 +15,8 @@ import (
 // and responds with the list of URLs in JSON format. If any error occurs, it logs the error and responds
 // with an appropriate message.
 func (c *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
-	userID, err := auth.CheckCookie(w, r, c.log)
-	if err != nil {
-		c.log.Error("Unauthorized access: ", zap.Error(err))
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	userID, ok := c.authorize(w, r, entity.ScopeLinksRead)
+	if !ok {
 		return
 	}
 
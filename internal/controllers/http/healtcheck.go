@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/health"
 )
 
 // HealthCheck handles the HTTP request for checking the health of the service.
@@ -31,3 +33,48 @@ func (c *Controller) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
+
+// WithHealth serves the readiness report of the checker on /readyz.
+func WithHealth(h *health.Checker) Option {
+	return func(c *Controller) {
+		c.health = h
+	}
+}
+
+// Livez handles the HTTP request for the liveness of the service. It checks no
+// dependencies: a response means that the process serves requests.
+func (c *Controller) Livez(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok"))
+}
+
+// Readyz handles the HTTP request for the readiness of the service. It responds with the
+// JSON report of the dependencies, with 200 OK if the service is ready and 503 Service
+// Unavailable if a dependency is failing or the service is shutting down.
+func (c *Controller) Readyz(w http.ResponseWriter, r *http.Request) {
+	c.writeReadiness(w, r, true)
+}
+
+// PublicReadyz handles the HTTP request for the readiness of the service on the public
+// listener. Like Readyz it responds with 200 OK or 503 Service Unavailable, but the
+// results of the checks, whose errors reveal the internals of the service, are reported
+// only to clients in the trusted subnets.
+func (c *Controller) PublicReadyz(w http.ResponseWriter, r *http.Request) {
+	c.writeReadiness(w, r, c.guard.Trusted(r))
+}
+
+// writeReadiness writes the readiness of the service, with the results of the checks if
+// details is set.
+func (c *Controller) writeReadiness(w http.ResponseWriter, r *http.Request, details bool) {
+	report := c.health.Check(r.Context())
+	if !details {
+		report = &health.Report{Status: report.Status}
+	}
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, report, c.log)
+}
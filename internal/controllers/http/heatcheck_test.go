@@ -13,25 +13,31 @@ import (
 	"github.com/nextlag/shortenerURL/internal/configuration"
 	http2 "github.com/nextlag/shortenerURL/internal/controllers/http"
 	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
 )
 
-type mockUsecase struct{}
+// mockUsecase implements the methods used by the examples; the embedded interface
+// covers the rest of http2.UseCase.
+type mockUsecase struct {
+	http2.UseCase
+}
 
 func (m *mockUsecase) DoGet(ctx context.Context, alias string) (*entity.URL, error) {
 	return &entity.URL{URL: "http://example.com", Alias: alias, IsDeleted: false}, nil
 }
 
-func (m *mockUsecase) DoGetAll(ctx context.Context, userID int, url string) ([]*entity.URL, error) {
+func (m *mockUsecase) DoGetAll(ctx context.Context, userID string, url string) ([]*entity.URL, error) {
 	return []*entity.URL{
 		{Alias: "short1", URL: "http://example.com/original1"},
 	}, nil
 }
 
-func (m *mockUsecase) DoPut(ctx context.Context, url string, alias string, uuid int) (string, error) {
+func (m *mockUsecase) DoPut(ctx context.Context, url string, alias string, uuid string) (string, error) {
 	return "shortened_url", nil
 }
 
-func (m *mockUsecase) DoDel(ctx context.Context, id int, aliases []string) {}
+func (m *mockUsecase) DoDel(ctx context.Context, id string, aliases []string) {}
 
 func (m *mockUsecase) DoHealthcheck() (bool, error) {
 	return true, nil
@@ -47,7 +53,9 @@ func ExampleController_HealthCheck() {
 	uc := &mockUsecase{}
 
 	wg := sync.WaitGroup{}
-	ctrl := http2.New(uc, &wg, &cfg, log)
+	sessions, _ := inmemory.New(&cfg, log)
+	a, _ := auth.New(cfg.JWT, sessions, log)
+	ctrl := http2.New(uc, a, &wg, &cfg, log)
 	r := chi.NewRouter()
 	ctrl.Controller(r)
 
@@ -71,7 +79,9 @@ func TestController_HealthCheck(t *testing.T) {
 	uc := &mockUsecase{}
 	wg := sync.WaitGroup{}
 
-	ctrl := http2.New(uc, &wg, &cfg, log)
+	sessions, _ := inmemory.New(&cfg, log)
+	a, _ := auth.New(cfg.JWT, sessions, log)
+	ctrl := http2.New(uc, a, &wg, &cfg, log)
 	r := chi.NewRouter()
 
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,39 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"html/template"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// interstitial is the warning page served instead of the redirect of a disabled link.
+// The destination is deliberately not shown, since disabled links are mostly phishing.
+var interstitial = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Link disabled</title>
+</head>
+<body>
+<h1>This link has been disabled</h1>
+<p>The short link <code>{{.Link}}</code> was disabled by the moderators of this service
+after it was reported for abuse, such as phishing or malware.</p>
+<p>If you followed it from an email or a message, do not enter passwords or personal
+details on pages you reached through it.</p>
+</body>
+</html>
+`))
+
+// writeInterstitial responds to the request for a disabled link with the warning page
+// and 410 Gone.
+func (c *Controller) writeInterstitial(w http.ResponseWriter, alias string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusGone)
+	if err := interstitial.Execute(w, struct{ Link string }{Link: c.cfg.BaseURL + "/" + alias}); err != nil {
+		c.log.Error("error rendering interstitial", zap.Error(err))
+	}
+}
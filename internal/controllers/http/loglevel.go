@@ -0,0 +1,51 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+// logLevelRequest is the body of the log level requests and responses.
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// WithLogLevel lets admins read and change the level of the application log at runtime.
+func WithLogLevel(level zap.AtomicLevel) Option {
+	return func(c *Controller) {
+		c.logLevel = &level
+	}
+}
+
+// GetLogLevel handles the HTTP request of an admin for the current level of the log.
+func (c *Controller) GetLogLevel(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: c.logLevel.Level().String()}, c.log)
+}
+
+// SetLogLevel handles the HTTP request of an admin for changing the level of the log to
+// the level in the JSON body, until the next change or restart.
+func (c *Controller) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		http.Error(w, "Unknown log level", http.StatusBadRequest)
+		return
+	}
+
+	adminID, _ := auth.FromContext(r.Context())
+	previous := c.logLevel.Level()
+	c.logLevel.SetLevel(level)
+	c.log.Warn("log level changed", zap.String("admin", adminID), zap.Stringer("from", previous), zap.Stringer("to", level))
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: level.String()}, c.log)
+}
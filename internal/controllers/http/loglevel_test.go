@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLogLevel(t *testing.T) {
+	ctrl, _, _ := Ctrl(t)
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	WithLogLevel(level)(ctrl)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	register := func(username string) []*http.Cookie {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"username": "`+username+`", "password": "correct horse"}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		return w.Result().Cookies()
+	}
+	_, rootCookies := registerAdmin(t, ctrl, r, "root")
+	userCookies := register("mallory")
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		cookies        []*http.Cookie
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Not an admin", method: http.MethodPut, body: `{"level":"debug"}`, cookies: userCookies, expectedStatus: http.StatusForbidden},
+		{name: "Get level", method: http.MethodGet, cookies: rootCookies, expectedStatus: http.StatusOK, expectedBody: `{"level":"info"}`},
+		{name: "Unknown level", method: http.MethodPut, body: `{"level":"loud"}`, cookies: rootCookies, expectedStatus: http.StatusBadRequest},
+		{name: "Set level", method: http.MethodPut, body: `{"level":"debug"}`, cookies: rootCookies, expectedStatus: http.StatusOK, expectedBody: `{"level":"debug"}`},
+		{name: "Get changed level", method: http.MethodGet, cookies: rootCookies, expectedStatus: http.StatusOK, expectedBody: `{"level":"debug"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/admin/log/level", strings.NewReader(tt.body))
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+	assert.Equal(t, zap.DebugLevel, level.Level())
+}
@@ -0,0 +1,18 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"github.com/nextlag/shortenerURL/internal/metrics"
+)
+
+// WithMetrics counts the requests and their latencies in m and serves m on /metrics.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(c *Controller) {
+		c.metrics = m
+	}
+}
+
+// PendingDeletions returns the number of links accepted for deletion and not deleted yet.
+func (c *Controller) PendingDeletions() int {
+	return int(c.deleting.Load())
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/shortenerURL/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	ctrl, _, _ := Ctrl(t)
+	cfg := *ctrl.cfg
+	cfg.TrustedSubnet = "192.168.0.0/16"
+	ctrl.cfg = &cfg
+	ctrl.trust, ctrl.guard = newTrust(&cfg, ctrl.log)
+	WithMetrics(metrics.New())(ctrl)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "From outside", remoteAddr: "203.0.113.5:1234", expectedStatus: http.StatusForbidden},
+		{name: "From trusted subnet", remoteAddr: "192.168.1.10:1234", expectedStatus: http.StatusOK, expectedBody: `shortener_http_requests_total{method="GET",route="/metrics",status="403"} 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -35,8 +35,38 @@ func (m *MockUseCase) EXPECT() *MockUseCaseMockRecorder {
 	return m.recorder
 }
 
+// DoAddWebhook mocks base method.
+func (m *MockUseCase) DoAddWebhook(arg0 context.Context, arg1 string, arg2 *entity.Webhook) (*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoAddWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoAddWebhook indicates an expected call of DoAddWebhook.
+func (mr *MockUseCaseMockRecorder) DoAddWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoAddWebhook", reflect.TypeOf((*MockUseCase)(nil).DoAddWebhook), arg0, arg1, arg2)
+}
+
+// DoClaim mocks base method.
+func (m *MockUseCase) DoClaim(arg0 context.Context, arg1, arg2 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoClaim", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoClaim indicates an expected call of DoClaim.
+func (mr *MockUseCaseMockRecorder) DoClaim(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoClaim", reflect.TypeOf((*MockUseCase)(nil).DoClaim), arg0, arg1, arg2)
+}
+
 // DoDel mocks base method.
-func (m *MockUseCase) DoDel(arg0 context.Context, arg1 int, arg2 []string) {
+func (m *MockUseCase) DoDel(arg0 context.Context, arg1 string, arg2 []string) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "DoDel", arg0, arg1, arg2)
 }
@@ -47,6 +77,20 @@ func (mr *MockUseCaseMockRecorder) DoDel(arg0, arg1, arg2 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoDel", reflect.TypeOf((*MockUseCase)(nil).DoDel), arg0, arg1, arg2)
 }
 
+// DoDelWebhook mocks base method.
+func (m *MockUseCase) DoDelWebhook(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoDelWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoDelWebhook indicates an expected call of DoDelWebhook.
+func (mr *MockUseCaseMockRecorder) DoDelWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoDelWebhook", reflect.TypeOf((*MockUseCase)(nil).DoDelWebhook), arg0, arg1, arg2)
+}
+
 // DoGet mocks base method.
 func (m *MockUseCase) DoGet(arg0 context.Context, arg1 string) (*entity.URL, error) {
 	m.ctrl.T.Helper()
@@ -63,7 +107,7 @@ func (mr *MockUseCaseMockRecorder) DoGet(arg0, arg1 interface{}) *gomock.Call {
 }
 
 // DoGetAll mocks base method.
-func (m *MockUseCase) DoGetAll(arg0 context.Context, arg1 int, arg2 string) ([]*entity.URL, error) {
+func (m *MockUseCase) DoGetAll(arg0 context.Context, arg1, arg2 string) ([]*entity.URL, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DoGetAll", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*entity.URL)
@@ -77,6 +121,81 @@ func (mr *MockUseCaseMockRecorder) DoGetAll(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetAll", reflect.TypeOf((*MockUseCase)(nil).DoGetAll), arg0, arg1, arg2)
 }
 
+// DoGetAudit mocks base method.
+func (m *MockUseCase) DoGetAudit(arg0 context.Context, arg1 entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetAudit", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetAudit indicates an expected call of DoGetAudit.
+func (mr *MockUseCaseMockRecorder) DoGetAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetAudit", reflect.TypeOf((*MockUseCase)(nil).DoGetAudit), arg0, arg1)
+}
+
+// DoGetDeadLetters mocks base method.
+func (m *MockUseCase) DoGetDeadLetters(arg0 context.Context, arg1, arg2 string) ([]*entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetDeadLetters", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetDeadLetters indicates an expected call of DoGetDeadLetters.
+func (mr *MockUseCaseMockRecorder) DoGetDeadLetters(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetDeadLetters", reflect.TypeOf((*MockUseCase)(nil).DoGetDeadLetters), arg0, arg1, arg2)
+}
+
+// DoGetDeliveries mocks base method.
+func (m *MockUseCase) DoGetDeliveries(arg0 context.Context, arg1, arg2 string) ([]*entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetDeliveries indicates an expected call of DoGetDeliveries.
+func (mr *MockUseCaseMockRecorder) DoGetDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetDeliveries", reflect.TypeOf((*MockUseCase)(nil).DoGetDeliveries), arg0, arg1, arg2)
+}
+
+// DoGetQuota mocks base method.
+func (m *MockUseCase) DoGetQuota(arg0 context.Context, arg1 string) (*entity.QuotaUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetQuota", arg0, arg1)
+	ret0, _ := ret[0].(*entity.QuotaUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetQuota indicates an expected call of DoGetQuota.
+func (mr *MockUseCaseMockRecorder) DoGetQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetQuota", reflect.TypeOf((*MockUseCase)(nil).DoGetQuota), arg0, arg1)
+}
+
+// DoGetReports mocks base method.
+func (m *MockUseCase) DoGetReports(arg0 context.Context, arg1 string) ([]*entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetReports", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetReports indicates an expected call of DoGetReports.
+func (mr *MockUseCaseMockRecorder) DoGetReports(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetReports", reflect.TypeOf((*MockUseCase)(nil).DoGetReports), arg0, arg1)
+}
+
 // DoGetStats mocks base method.
 func (m *MockUseCase) DoGetStats(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -92,6 +211,36 @@ func (mr *MockUseCaseMockRecorder) DoGetStats(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetStats", reflect.TypeOf((*MockUseCase)(nil).DoGetStats), arg0)
 }
 
+// DoGetUserCounts mocks base method.
+func (m *MockUseCase) DoGetUserCounts(arg0 context.Context) ([]*entity.UserCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetUserCounts", arg0)
+	ret0, _ := ret[0].([]*entity.UserCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetUserCounts indicates an expected call of DoGetUserCounts.
+func (mr *MockUseCaseMockRecorder) DoGetUserCounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetUserCounts", reflect.TypeOf((*MockUseCase)(nil).DoGetUserCounts), arg0)
+}
+
+// DoGetWebhooks mocks base method.
+func (m *MockUseCase) DoGetWebhooks(arg0 context.Context, arg1 string) ([]*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoGetWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoGetWebhooks indicates an expected call of DoGetWebhooks.
+func (mr *MockUseCaseMockRecorder) DoGetWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoGetWebhooks", reflect.TypeOf((*MockUseCase)(nil).DoGetWebhooks), arg0, arg1)
+}
+
 // DoHealthcheck mocks base method.
 func (m *MockUseCase) DoHealthcheck() (bool, error) {
 	m.ctrl.T.Helper()
@@ -108,7 +257,7 @@ func (mr *MockUseCaseMockRecorder) DoHealthcheck() *gomock.Call {
 }
 
 // DoPut mocks base method.
-func (m *MockUseCase) DoPut(arg0 context.Context, arg1, arg2 string, arg3 int) (string, error) {
+func (m *MockUseCase) DoPut(arg0 context.Context, arg1, arg2, arg3 string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DoPut", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(string)
@@ -121,3 +270,91 @@ func (mr *MockUseCaseMockRecorder) DoPut(arg0, arg1, arg2, arg3 interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoPut", reflect.TypeOf((*MockUseCase)(nil).DoPut), arg0, arg1, arg2, arg3)
 }
+
+// DoPutBatch mocks base method.
+func (m *MockUseCase) DoPutBatch(arg0 context.Context, arg1 []string, arg2 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoPutBatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoPutBatch indicates an expected call of DoPutBatch.
+func (mr *MockUseCaseMockRecorder) DoPutBatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoPutBatch", reflect.TypeOf((*MockUseCase)(nil).DoPutBatch), arg0, arg1, arg2)
+}
+
+// DoReport mocks base method.
+func (m *MockUseCase) DoReport(arg0 context.Context, arg1, arg2 string) (*entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoReport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoReport indicates an expected call of DoReport.
+func (mr *MockUseCaseMockRecorder) DoReport(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoReport", reflect.TypeOf((*MockUseCase)(nil).DoReport), arg0, arg1, arg2)
+}
+
+// DoReviewReport mocks base method.
+func (m *MockUseCase) DoReviewReport(arg0 context.Context, arg1, arg2, arg3 string) (*entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoReviewReport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoReviewReport indicates an expected call of DoReviewReport.
+func (mr *MockUseCaseMockRecorder) DoReviewReport(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoReviewReport", reflect.TypeOf((*MockUseCase)(nil).DoReviewReport), arg0, arg1, arg2, arg3)
+}
+
+// DoSearchLinks mocks base method.
+func (m *MockUseCase) DoSearchLinks(arg0 context.Context, arg1 entity.LinkFilter) ([]*entity.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoSearchLinks", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoSearchLinks indicates an expected call of DoSearchLinks.
+func (mr *MockUseCaseMockRecorder) DoSearchLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoSearchLinks", reflect.TypeOf((*MockUseCase)(nil).DoSearchLinks), arg0, arg1)
+}
+
+// DoSetDisabled mocks base method.
+func (m *MockUseCase) DoSetDisabled(arg0 context.Context, arg1 string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoSetDisabled", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoSetDisabled indicates an expected call of DoSetDisabled.
+func (mr *MockUseCaseMockRecorder) DoSetDisabled(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoSetDisabled", reflect.TypeOf((*MockUseCase)(nil).DoSetDisabled), arg0, arg1, arg2)
+}
+
+// DoSetQuota mocks base method.
+func (m *MockUseCase) DoSetQuota(arg0 context.Context, arg1 string, arg2 *entity.Quota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoSetQuota", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoSetQuota indicates an expected call of DoSetQuota.
+func (mr *MockUseCaseMockRecorder) DoSetQuota(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoSetQuota", reflect.TypeOf((*MockUseCase)(nil).DoSetQuota), arg0, arg1, arg2)
+}
@@ -0,0 +1,120 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc"
+)
+
+// OIDCCallbackPath is the path the OpenID Connect provider redirects back to.
+const OIDCCallbackPath = oidcCookiePath + "/callback"
+
+const (
+	oidcStateCookie = "OIDCState"      // oidcStateCookie keeps the login attempt between the redirects
+	oidcCookiePath  = "/api/user/oidc" // oidcCookiePath limits the state cookie to the login routes
+	oidcStateMaxAge = 10 * 60          // oidcStateMaxAge is the time to log in at the provider, in seconds
+	oidcClaimParam  = "claim"          // oidcClaimParam requests claiming the anonymous links
+)
+
+// oidcState is a login attempt. The verifier and nonce never leave the browser
+// of the user except towards the provider.
+type oidcState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Claim    bool   `json:"claim,omitempty"`
+}
+
+// OIDCLogin handles the HTTP request for logging in through the OpenID Connect provider.
+// It remembers the login attempt in a cookie and redirects to the provider.
+// With ?claim=true the links of the anonymous user move into the logged in user.
+func (c *Controller) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	st := oidcState{Claim: r.URL.Query().Get(oidcClaimParam) == "true"}
+	var err error
+	for _, v := range []*string{&st.State, &st.Nonce, &st.Verifier} {
+		if *v, err = oidc.RandomString(); err != nil {
+			c.log.Error("failed to start OIDC login", zap.Error(err))
+			http.Error(w, "Failed to start login", http.StatusInternalServerError)
+			return
+		}
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		c.log.Error("failed to start OIDC login", zap.Error(err))
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    base64.RawURLEncoding.EncodeToString(data),
+		Path:     oidcCookiePath,
+		MaxAge:   oidcStateMaxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, c.oidc.AuthCodeURL(st.State, st.Nonce, st.Verifier), http.StatusFound)
+}
+
+// OIDCCallback handles the redirect back from the OpenID Connect provider. It redeems
+// the authorization code, maps the subject of the ID token to a user and logs it in.
+func (c *Controller) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	st, ok := c.oidcState(r)
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: oidcCookiePath, MaxAge: -1, HttpOnly: true})
+	q := r.URL.Query()
+	if !ok || subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(st.State)) != 1 {
+		http.Error(w, "Login attempt expired or unknown", http.StatusBadRequest)
+		return
+	}
+	if errCode := q.Get("error"); errCode != "" {
+		c.log.Info("OIDC login denied", zap.String("error", errCode), zap.String("description", q.Get("error_description")))
+		http.Error(w, "Login denied by provider: "+errCode, http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := c.oidc.Exchange(r.Context(), q.Get("code"), st.Verifier, st.Nonce)
+	if err != nil {
+		c.log.Error("OIDC code exchange failed", zap.Error(err))
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	identity, tokens, err := c.auth.LoginIdentity(r.Context(), c.oidc.Issuer(), claims.Subject)
+	if err != nil {
+		c.log.Error("failed to log in identity", zap.Error(err))
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	if st.Claim {
+		if identity.Claimed, err = c.claim(r, identity.UserID); err != nil {
+			c.log.Error("failed to claim links", zap.Error(err))
+			http.Error(w, "Failed to claim links", http.StatusInternalServerError)
+			return
+		}
+	}
+	c.auth.SetCookies(w, tokens)
+	writeJSON(w, http.StatusOK, identity, c.log)
+}
+
+// oidcState returns the login attempt of the state cookie.
+func (c *Controller) oidcState(r *http.Request) (*oidcState, bool) {
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return nil, false
+	}
+	var st oidcState
+	if err = json.Unmarshal(data, &st); err != nil || st.State == "" {
+		return nil, false
+	}
+	return &st, true
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc"
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc/oidctest"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	stand := oidctest.NewProvider("shortener", "alice")
+	defer stand.Close()
+
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	provider, err := oidc.Discover(context.Background(), stand.Config(srv.URL+OIDCCallbackPath), nil)
+	require.NoError(t, err)
+	WithOIDC(provider)(ctrl)
+	ctrl.Controller(r)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	browser := &http.Client{Jar: jar}
+
+	// The anonymous user shortens a link before logging in.
+	var anonymous string
+	db.EXPECT().DoPut(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, _, _, userID string) (string, error) {
+			anonymous = userID
+			return "alias", nil
+		}).Times(1)
+	resp, err := browser.Post(srv.URL+"/", "text/plain", strings.NewReader("http://example.com"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	db.EXPECT().DoClaim(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, from, _ string) (int, error) {
+			assert.Equal(t, anonymous, from)
+			return 1, nil
+		}).Times(1)
+	login := func(query string) *entity.Identity {
+		resp, err := browser.Get(srv.URL + "/api/user/oidc/login" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var identity entity.Identity
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&identity))
+		return &identity
+	}
+
+	first := login("?claim=true")
+	assert.Equal(t, "alice", first.Subject)
+	assert.Equal(t, 1, first.Claimed)
+	assert.NotEqual(t, anonymous, first.UserID)
+
+	// The subject maps to the same user on every login.
+	second := login("")
+	assert.Equal(t, first.UserID, second.UserID)
+
+	stand.SetSubject("bob")
+	assert.NotEqual(t, first.UserID, login("").UserID)
+
+	// A callback without a login attempt is rejected.
+	resp, err = http.Get(srv.URL + OIDCCallbackPath + "?code=code&state=state")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
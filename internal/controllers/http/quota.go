@@ -0,0 +1,81 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+)
+
+// GetQuota handles the HTTP request for the quota of a user and its current usage. Requests
+// made with an API key see the quota narrowed by the key.
+func (c *Controller) GetQuota(w http.ResponseWriter, r *http.Request) {
+	ctx, userID, ok := c.authorizeContext(w, r, entity.ScopeLinksRead)
+	if !ok {
+		return
+	}
+
+	usage, err := c.uc.DoGetQuota(ctx, userID)
+	if err != nil {
+		c.log.Error("Error getting quota", zap.Error(err))
+		http.Error(w, "Error retrieving quota", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage, c.log)
+}
+
+// SetUserQuota handles the HTTP request of an admin for overriding the quota of a user
+// with the quota in the JSON body.
+func (c *Controller) SetUserQuota(w http.ResponseWriter, r *http.Request) {
+	var q entity.Quota
+	if err := render.DecodeJSON(r.Body, &q); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := c.uc.DoSetQuota(r.Context(), chi.URLParam(r, "id"), &q)
+	if errors.Is(err, quota.ErrInvalidQuota) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error setting quota", zap.Error(err))
+		http.Error(w, "Error setting quota", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, q, c.log)
+}
+
+// DelUserQuota handles the HTTP request of an admin for removing the quota override of a
+// user, who falls back to the default quota.
+func (c *Controller) DelUserQuota(w http.ResponseWriter, r *http.Request) {
+	err := c.uc.DoSetQuota(r.Context(), chi.URLParam(r, "id"), nil)
+	if errors.Is(err, quota.ErrQuotaNotFound) {
+		http.Error(w, "User has no quota override", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error removing quota", zap.Error(err))
+		http.Error(w, "Error removing quota", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// quotaExceeded responds 403 Forbidden naming the exceeded limit if err is a quota.ExceededError,
+// and reports whether it did.
+func (c *Controller) quotaExceeded(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, quota.ErrExceeded) {
+		return false
+	}
+	c.log.Info("quota exceeded", zap.Error(err))
+	http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+	return true
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+)
+
+func TestQuota(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	_, cookies := registerAdmin(t, ctrl, r, "quota-root")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/keys", strings.NewReader(`{"name": "ci", "scopes": ["links:write"], "quota": {"max_daily_links": 1}}`))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var key entity.APIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&key))
+	require.NotNil(t, key.Quota)
+
+	db.EXPECT().DoPut(gomock.Any(), "http://example.com", "", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _, _, _ string) (string, error) {
+			q, ok := quota.FromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, 1, q.MaxDailyLinks)
+			return "", &quota.ExceededError{Limit: quota.LimitDailyLinks, Max: 1}
+		}).Times(1)
+	db.EXPECT().DoPutBatch(gomock.Any(), []string{"http://a.example", "http://b.example"}, gomock.Any()).
+		Return(nil, &quota.ExceededError{Limit: quota.LimitLinks, Max: 1}).Times(1)
+	db.EXPECT().DoGetQuota(gomock.Any(), gomock.Any()).
+		Return(&entity.QuotaUsage{Quota: entity.Quota{MaxLinks: 10}, Links: 3, LinksToday: 1}, nil).Times(1)
+	db.EXPECT().DoSetQuota(gomock.Any(), "someone", &entity.Quota{MaxLinks: 5}).Return(nil).Times(1)
+	db.EXPECT().DoSetQuota(gomock.Any(), "someone", &entity.Quota{MaxLinks: -1}).Return(quota.ErrInvalidQuota).Times(1)
+	db.EXPECT().DoSetQuota(gomock.Any(), "nobody", nil).Return(quota.ErrQuotaNotFound).Times(1)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Save over key quota", method: http.MethodPost, target: "/", body: "http://example.com", authorization: "Bearer " + key.Key, expectedStatus: http.StatusForbidden, expectedBody: "per day"},
+		{name: "Batch over quota", method: http.MethodPost, target: "/api/shorten/batch", body: `[{"correlation_id": "1", "original_url": "http://a.example"}, {"correlation_id": "2", "original_url": "http://b.example"}]`, expectedStatus: http.StatusForbidden, expectedBody: "can be kept"},
+		{name: "Get quota", method: http.MethodGet, target: "/api/user/quota", expectedStatus: http.StatusOK, expectedBody: `"links_today":1`},
+		{name: "Set user quota", method: http.MethodPut, target: "/api/admin/users/someone/quota", body: `{"max_links": 5}`, expectedStatus: http.StatusOK, expectedBody: `"max_links":5`},
+		{name: "Set invalid quota", method: http.MethodPut, target: "/api/admin/users/someone/quota", body: `{"max_links": -1}`, expectedStatus: http.StatusBadRequest},
+		{name: "Remove missing quota", method: http.MethodDelete, target: "/api/admin/users/nobody/quota", expectedStatus: http.StatusNotFound},
+		{name: "Invalid key quota", method: http.MethodPost, target: "/api/user/keys", body: `{"scopes": ["links:write"], "quota": {"max_links": -1}}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			} else {
+				for _, cookie := range cookies {
+					req.AddCookie(cookie)
+				}
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"net/http"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+)
+
+// WithRateLimitStore keeps the rate limit buckets in the store, which may be shared with
+// the gRPC server and other instances. By default every Controller has its own memory store.
+func WithRateLimitStore(store ratelimit.Store) Option {
+	return func(c *Controller) {
+		c.limits = store
+	}
+}
+
+// rateLimit returns a middleware limiting the requests of the group, or a middleware
+// passing them through if the rule has no requests. Requests are limited per client
+// address, and requests with an access token per user as well, so that the anonymous
+// users a client obtains by dropping its cookies share the bucket of its address.
+func (c *Controller) rateLimit(group string, rule configuration.RateRule) func(http.Handler) http.Handler {
+	l := ratelimit.New(group, ratelimit.LimitOf(rule), c.limits, c.log)
+	if l == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return l.Handler(c.addressKey, c.userKey)
+}
+
+// addressKey identifies the client by its address.
+func (c *Controller) addressKey(r *http.Request) string {
+	return "ip:" + c.clientIP(r)
+}
+
+// userKey identifies the client by the user of a valid access token, if any.
+func (c *Controller) userKey(r *http.Request) string {
+	if userID, ok := c.auth.RequestUser(r); ok {
+		return "user:" + userID
+	}
+	return ""
+}
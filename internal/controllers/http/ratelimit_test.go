@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	cfg := *ctrl.cfg
+	cfg.RateLimit.Write = configuration.RateRule{Requests: 1, Period: configuration.Duration(time.Minute)}
+	cfg.RateLimit.Auth = configuration.RateRule{Requests: 1, Period: configuration.Duration(time.Minute)}
+	ctrl.cfg = &cfg
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"username": "limited", "password": "correct horse"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	cookies := w.Result().Cookies()
+
+	db.EXPECT().DoPut(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("abc", nil).Times(2)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		cookies        []*http.Cookie
+		addr           string
+		expectedStatus int
+	}{
+		{name: "Login from same address", method: http.MethodPost, target: "/api/user/login", body: `{"username": "limited", "password": "correct horse"}`, expectedStatus: http.StatusTooManyRequests},
+		{name: "Login from other address", method: http.MethodPost, target: "/api/user/login", body: `{"username": "limited", "password": "correct horse"}`, addr: "192.0.2.2:1234", expectedStatus: http.StatusOK},
+		{name: "Shorten by user", method: http.MethodPost, target: "/", body: "http://example.com", cookies: cookies, expectedStatus: http.StatusCreated},
+		{name: "Shorten by user again", method: http.MethodPost, target: "/api/shorten", body: `{"url": "http://example.com"}`, cookies: cookies, expectedStatus: http.StatusTooManyRequests},
+		{name: "Shorten without cookies from same address", method: http.MethodPost, target: "/", body: "http://example.com", expectedStatus: http.StatusTooManyRequests},
+		{name: "Shorten from other address", method: http.MethodPost, target: "/", body: "http://example.com", addr: "192.0.2.3:1234", expectedStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.addr != "" {
+				req.RemoteAddr = tt.addr
+			}
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "1", w.Header().Get(ratelimit.HeaderLimit))
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				retryAfter, err := strconv.Atoi(w.Header().Get(ratelimit.HeaderRetryAfter))
+				require.NoError(t, err)
+				assert.InDelta(t, 60, retryAfter, 1, "the bucket refills within the period")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/shortenerURL/internal/health"
+)
+
+func TestReadiness(t *testing.T) {
+	const trustedAddr = "192.168.1.10:1234"
+	ctrl, _, _ := Ctrl(t)
+	cfg := *ctrl.cfg
+	cfg.TrustedSubnet = "192.168.0.0/16"
+	ctrl.cfg = &cfg
+	ctrl.trust, ctrl.guard = newTrust(&cfg, ctrl.log)
+	checker := health.New(time.Second)
+	var storageErr error
+	checker.Register("file_storage", func(context.Context) error { return storageErr })
+	WithHealth(checker)(ctrl)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	tests := []struct {
+		name           string
+		target         string
+		remoteAddr     string
+		prepare        func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Live", target: "/livez", expectedStatus: http.StatusOK, expectedBody: "ok"},
+		{name: "Ready", target: "/readyz", remoteAddr: trustedAddr, expectedStatus: http.StatusOK, expectedBody: `"file_storage":{"status":"ok"`},
+		{name: "Ready without details", target: "/readyz", expectedStatus: http.StatusOK, expectedBody: `{"status":"ok"}`},
+		{name: "Failing dependency", target: "/readyz", remoteAddr: trustedAddr, prepare: func() { storageErr = errors.New("read-only file system") },
+			expectedStatus: http.StatusServiceUnavailable, expectedBody: `"error":"read-only file system"`},
+		{name: "Failing dependency without details", target: "/readyz", expectedStatus: http.StatusServiceUnavailable, expectedBody: `{"status":"unavailable"}`},
+		{name: "Shutting down", target: "/readyz", prepare: func() { storageErr = nil; checker.Shutdown() },
+			expectedStatus: http.StatusServiceUnavailable, expectedBody: `{"status":"shutting_down"}`},
+		{name: "Live while shutting down", target: "/livez", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		})
+	}
+}
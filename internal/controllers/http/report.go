@@ -0,0 +1,101 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
+)
+
+// reportRequest is the body of an abuse report.
+type reportRequest struct {
+	Reason string `json:"reason"`
+}
+
+// reviewRequest is the body of the review of an abuse report by an admin.
+type reviewRequest struct {
+	Action string `json:"action"` // Action is report.ActionDisable or report.ActionDismiss
+}
+
+// Report handles the public HTTP request for reporting the abuse of a link. Repeat reports
+// against a link are merged until an admin reviews them, so every request gets 202 Accepted.
+func (c *Controller) Report(w http.ResponseWriter, r *http.Request) {
+	var req reportRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	alias := chi.URLParam(r, "id")
+	_, err := c.uc.DoReport(r.Context(), alias, req.Reason)
+	switch {
+	case errors.Is(err, report.ErrInvalidReason):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, admin.ErrLinkNotFound):
+		http.Error(w, "URL not found", http.StatusNotFound)
+	case err != nil:
+		c.log.Error("Error filing report", zap.Error(err))
+		http.Error(w, "Error filing report", http.StatusInternalServerError)
+	default:
+		c.log.Info("link reported", zap.String("alias", alias))
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// GetReports handles the HTTP request of an admin for the abuse reports. The query
+// parameter status selects open, actioned or dismissed reports.
+func (c *Controller) GetReports(w http.ResponseWriter, r *http.Request) {
+	reports, err := c.uc.DoGetReports(r.Context(), r.URL.Query().Get("status"))
+	if errors.Is(err, report.ErrUnknownStatus) {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error getting reports", zap.Error(err))
+		http.Error(w, "Error retrieving reports", http.StatusInternalServerError)
+		return
+	}
+	if len(reports) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, reports, c.log)
+}
+
+// ReviewReport handles the HTTP request of an admin for closing an open abuse report by
+// disabling the link or dismissing the report.
+func (c *Controller) ReviewReport(w http.ResponseWriter, r *http.Request) {
+	var req reviewRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	adminID, _ := auth.FromContext(r.Context())
+	rep, err := c.uc.DoReviewReport(r.Context(), chi.URLParam(r, "id"), adminID, req.Action)
+	switch {
+	case errors.Is(err, report.ErrUnknownAction):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, report.ErrReportNotFound):
+		http.Error(w, "Report not found", http.StatusNotFound)
+	case errors.Is(err, admin.ErrLinkNotFound):
+		http.Error(w, "Link not found", http.StatusNotFound)
+	case errors.Is(err, report.ErrReportClosed):
+		http.Error(w, "Report already reviewed", http.StatusConflict)
+	case err != nil:
+		c.log.Error("Error reviewing report", zap.Error(err))
+		http.Error(w, "Error reviewing report", http.StatusInternalServerError)
+	default:
+		c.log.Info("report reviewed", zap.String("report", rep.ID), zap.String("action", req.Action), zap.String("admin", adminID))
+		writeJSON(w, http.StatusOK, rep, c.log)
+	}
+}
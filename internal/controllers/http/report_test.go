@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
+)
+
+func TestReports(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	_, adminCookies := registerAdmin(t, ctrl, r, "moderator")
+
+	db.EXPECT().DoReport(gomock.Any(), "abc", "phishing").Return(&entity.Report{ID: "r1", Alias: "abc", Count: 1}, nil).Times(1)
+	db.EXPECT().DoReport(gomock.Any(), "abc", "").Return(nil, report.ErrInvalidReason).Times(1)
+	db.EXPECT().DoReport(gomock.Any(), "missing", "spam").Return(nil, admin.ErrLinkNotFound).Times(1)
+	db.EXPECT().DoGetReports(gomock.Any(), "open").Return([]*entity.Report{{ID: "r1", Alias: "abc", Count: 3, Status: entity.ReportOpen}}, nil).Times(1)
+	db.EXPECT().DoGetReports(gomock.Any(), "dismissed").Return(nil, nil).Times(1)
+	db.EXPECT().DoGetReports(gomock.Any(), "pending").Return(nil, report.ErrUnknownStatus).Times(1)
+	db.EXPECT().DoReviewReport(gomock.Any(), "r1", gomock.Any(), report.ActionDisable).
+		Return(&entity.Report{ID: "r1", Alias: "abc", Status: entity.ReportActioned}, nil).Times(1)
+	db.EXPECT().DoReviewReport(gomock.Any(), "r1", gomock.Any(), report.ActionDismiss).Return(nil, report.ErrReportClosed).Times(1)
+	db.EXPECT().DoReviewReport(gomock.Any(), "r1", gomock.Any(), "delete").Return(nil, report.ErrUnknownAction).Times(1)
+	db.EXPECT().DoGet(gomock.Any(), "abc").Return(&entity.URL{Alias: "abc", URL: "http://phishing.example", Disabled: true}, nil).Times(1)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		cookies        []*http.Cookie
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Report link", method: http.MethodPost, target: "/abc/report", body: `{"reason": "phishing"}`, expectedStatus: http.StatusAccepted},
+		{name: "Report without reason", method: http.MethodPost, target: "/abc/report", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "Report missing link", method: http.MethodPost, target: "/missing/report", body: `{"reason": "spam"}`, expectedStatus: http.StatusNotFound},
+		{name: "Invalid body", method: http.MethodPost, target: "/abc/report", body: `reason`, expectedStatus: http.StatusBadRequest},
+		{name: "Not an admin", method: http.MethodGet, target: "/api/admin/reports", expectedStatus: http.StatusForbidden},
+		{name: "Open reports", method: http.MethodGet, target: "/api/admin/reports?status=open", cookies: adminCookies, expectedStatus: http.StatusOK, expectedBody: `"count":3`},
+		{name: "No reports", method: http.MethodGet, target: "/api/admin/reports?status=dismissed", cookies: adminCookies, expectedStatus: http.StatusNoContent},
+		{name: "Unknown status", method: http.MethodGet, target: "/api/admin/reports?status=pending", cookies: adminCookies, expectedStatus: http.StatusBadRequest},
+		{name: "Disable link", method: http.MethodPost, target: "/api/admin/reports/r1/review", body: `{"action": "disable"}`, cookies: adminCookies, expectedStatus: http.StatusOK, expectedBody: `"status":"actioned"`},
+		{name: "Review again", method: http.MethodPost, target: "/api/admin/reports/r1/review", body: `{"action": "dismiss"}`, cookies: adminCookies, expectedStatus: http.StatusConflict},
+		{name: "Unknown action", method: http.MethodPost, target: "/api/admin/reports/r1/review", body: `{"action": "delete"}`, cookies: adminCookies, expectedStatus: http.StatusBadRequest},
+		{name: "Interstitial", method: http.MethodGet, target: "/abc", expectedStatus: http.StatusGone, expectedBody: "This link has been disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			if tt.name == "Interstitial" {
+				assert.Empty(t, w.Header().Get("Location"))
+				assert.NotContains(t, w.Body.String(), "phishing.example")
+			}
+		})
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 	"go.uber.org/zap"
 
-	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/entity"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
 )
 
@@ -23,16 +23,15 @@ func (c *Controller) Save(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad request 400", http.StatusBadRequest)
 		return
 	}
-	uuid, err := auth.CheckCookie(w, r, c.log)
-	if err != nil {
-		c.log.Error("Error getting cookie: ", zap.Error(err))
+	ctx, uuid, ok := c.authorizeContext(w, r, entity.ScopeLinksWrite)
+	if !ok {
 		return
 	}
 
-	alias, err := c.uc.DoPut(r.Context(), string(body), "", uuid)
+	alias, err := c.uc.DoPut(ctx, string(body), "", uuid)
 
 	if errors.Is(err, psql.ErrConflict) {
-		c.log.Error("duplicate url", zap.String("alias", alias), zap.String("url", string(body)))
+		c.log.Error("duplicate url", zap.String("alias", alias))
 		w.WriteHeader(http.StatusConflict)
 		_, err = fmt.Fprintf(w, "%s/%s", c.cfg.BaseURL, alias)
 		if err != nil {
@@ -42,6 +41,9 @@ func (c *Controller) Save(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if c.quotaExceeded(w, err) {
+		return
+	}
 	if err != nil {
 		c.log.Error("failed to add URL", zap.Error(err), zap.String("path to file storage", c.cfg.FileStorage))
 		http.Error(w, fmt.Sprintf("failed to add URL: %s", err), http.StatusInternalServerError)
@@ -0,0 +1,66 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+// GetSessions handles the HTTP request for listing the sessions of a user.
+// The session of the request is marked as current.
+func (c *Controller) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	current, _ := c.auth.SessionID(r)
+	sessions, err := c.auth.Sessions(r.Context(), userID, current)
+	if err != nil {
+		c.log.Error("Error getting sessions", zap.Error(err))
+		http.Error(w, "Error retrieving sessions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessions, c.log)
+}
+
+// DelSession handles the HTTP request for revoking a session of a user,
+// e.g. one that was stolen.
+func (c *Controller) DelSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = c.auth.Revoke(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error revoking session", zap.Error(err))
+		http.Error(w, "Error revoking session", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Logout handles the HTTP request for ending the session of the request.
+func (c *Controller) Logout(w http.ResponseWriter, r *http.Request) {
+	err := c.auth.Logout(w, r)
+	if err != nil && !errors.Is(err, auth.ErrSessionNotFound) {
+		c.log.Error("Error logging out", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
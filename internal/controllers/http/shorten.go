@@ -11,7 +11,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 
-	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/entity"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
 )
@@ -22,7 +22,6 @@ import (
 func (c *Controller) Shorten(w http.ResponseWriter, r *http.Request) {
 	var req inmemory.FileStorage
 	err := render.DecodeJSON(r.Body, &req)
-	c.log.Info("body", zap.String("URL", req.URL))
 
 	if errors.Is(err, io.EOF) {
 		c.log.Error("request body is empty", zap.Error(err))
@@ -44,19 +43,21 @@ func (c *Controller) Shorten(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uuid, err := auth.CheckCookie(w, r, c.log)
-	if err != nil {
-		c.log.Error("Error getting cookie: ", zap.Error(err))
+	ctx, uuid, ok := c.authorizeContext(w, r, entity.ScopeLinksWrite)
+	if !ok {
 		return
 	}
 
-	alias, err := c.uc.DoPut(r.Context(), req.URL, req.Alias, uuid)
+	alias, err := c.uc.DoPut(ctx, req.URL, req.Alias, uuid)
 	if errors.Is(err, psql.ErrConflict) {
 		c.log.Error("trying to add a duplicate URL", zap.Error(err))
 		responseConflict(w, alias, c.cfg)
 		return
 	}
 
+	if c.quotaExceeded(w, err) {
+		return
+	}
 	if err != nil {
 		er := fmt.Sprintf("failed to add URL: %s", err)
 		render.JSON(w, r, Error(er))
@@ -49,7 +49,7 @@ func TestShorten(t *testing.T) {
 				return
 			}
 
-			_, db, _ := Ctrl(t)
+			ctrl, db, _ := Ctrl(t)
 			if !strings.Contains(test.name, "ValidRequest") {
 				db.EXPECT().DoPut(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			} else {
@@ -61,7 +61,7 @@ func TestShorten(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 			wg := sync.WaitGroup{}
-			New(db, &wg, cfg, log).Shorten(w, req)
+			New(db, ctrl.auth, &wg, cfg, log).Shorten(w, req)
 			resp := w.Result()
 			defer resp.Body.Close()
 
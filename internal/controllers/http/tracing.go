@@ -0,0 +1,13 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"github.com/nextlag/shortenerURL/internal/tracing"
+)
+
+// WithTracer traces the requests with t, continuing the traces of their traceparent headers.
+func WithTracer(t *tracing.Tracer) Option {
+	return func(c *Controller) {
+		c.tracer = t
+	}
+}
@@ -0,0 +1,39 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+	"github.com/nextlag/shortenerURL/internal/middleware/trusted"
+)
+
+// newTrust creates the resolver of client IPs and the guard of the trusted subnets.
+// Invalid networks are logged and trust nothing, so that a typo does not open the
+// guarded routes or let clients choose their address.
+func newTrust(cfg *configuration.Config, log *zap.Logger) (*trusted.Resolver, *trusted.Guard) {
+	proxies, err := trusted.Parse(cfg.TrustedProxies...)
+	if err != nil {
+		log.Error("invalid trusted proxies configuration", zap.Error(err))
+		proxies = nil
+	}
+	subnets, err := trusted.Parse(cfg.TrustedSubnet)
+	if err != nil {
+		log.Error("invalid trusted subnet configuration", zap.Error(err))
+		subnets = nil
+	}
+	resolver := trusted.NewResolver(proxies)
+	return resolver, trusted.NewGuard(subnets, resolver, log)
+}
+
+// clientIP returns the address of the client of the request, resolved through the
+// trusted proxies.
+func (c *Controller) clientIP(r *http.Request) string {
+	if ip := c.trust.ClientIP(r); ip.IsValid() {
+		return ip.String()
+	}
+	return ratelimit.ClientIP(r)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedSubnet(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	cfg := *ctrl.cfg
+	cfg.TrustedSubnet = "192.168.0.0/16, 2001:db8::/32"
+	cfg.TrustedProxies = []string{"10.0.0.1"}
+	cfg.Admin.TrustedOnly = true
+	ctrl.cfg = &cfg
+	ctrl.trust, ctrl.guard = newTrust(&cfg, ctrl.log)
+	r := chi.NewRouter()
+	ctrl.Controller(r)
+
+	db.EXPECT().DoGetStats(gomock.Any()).Return([]byte(`{"urls":1,"users":1}`), nil).Times(2)
+
+	tests := []struct {
+		name           string
+		target         string
+		remoteAddr     string
+		forwardedFor   string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Stats from trusted subnet", target: "/api/internal/stats", remoteAddr: "192.168.1.10:1234", expectedStatus: http.StatusOK},
+		{name: "Stats from trusted IPv6 subnet", target: "/api/internal/stats", remoteAddr: "[2001:db8::10]:1234", expectedStatus: http.StatusOK},
+		{name: "Stats with spoofed header", target: "/api/internal/stats", remoteAddr: "203.0.113.5:1234", forwardedFor: "192.168.1.10", expectedStatus: http.StatusForbidden},
+		{name: "Stats through untrusted proxy hop", target: "/api/internal/stats", remoteAddr: "10.0.0.1:80", forwardedFor: "192.168.1.10, 203.0.113.5", expectedStatus: http.StatusForbidden},
+		{name: "Pprof from outside", target: "/debug/pprof/", remoteAddr: "203.0.113.5:1234", expectedStatus: http.StatusForbidden},
+		{name: "Pprof through trusted proxy", target: "/debug/pprof/", remoteAddr: "10.0.0.1:80", forwardedFor: "192.168.1.10", expectedStatus: http.StatusOK},
+		{name: "Admin from outside", target: "/api/admin/users", remoteAddr: "203.0.113.5:1234", expectedStatus: http.StatusForbidden, expectedBody: "not in trusted subnet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
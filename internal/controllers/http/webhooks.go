@@ -0,0 +1,154 @@
+// Package controllers provides the handlers for managing URL shortening operations.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
+)
+
+// eventTypes contains the event types a webhook can subscribe to.
+var eventTypes = map[string]bool{
+	entity.EventLinkCreated: true,
+	entity.EventLinkDeleted: true,
+	entity.EventLinkClicked: true,
+}
+
+// AddWebhook handles the HTTP request for registering a webhook.
+// It decodes the endpoint URL, optional secret and event types from the JSON body
+// and responds with the stored webhook including its signing secret.
+func (c *Controller) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	var hook entity.Webhook
+	if err := render.DecodeJSON(r.Body, &hook); err != nil {
+		c.log.Error("failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validator.New().Struct(hook); err != nil {
+		c.log.Error("invalid webhook", zap.Error(err))
+		http.Error(w, "Invalid webhook URL", http.StatusBadRequest)
+		return
+	}
+	for _, eventType := range hook.Events {
+		if !eventTypes[eventType] {
+			http.Error(w, fmt.Sprintf("Unknown event type %q", eventType), http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Error getting cookie: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	created, err := c.uc.DoAddWebhook(r.Context(), userID, &hook)
+	if errors.Is(err, webhook.ErrInvalidURL) {
+		c.log.Info("webhook URL rejected", zap.Error(err))
+		http.Error(w, "Invalid webhook URL", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		c.log.Error("failed to add webhook", zap.Error(err))
+		http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created, c.log)
+}
+
+// GetWebhooks handles the HTTP request for listing the webhooks of a user.
+func (c *Controller) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	hooks, err := c.uc.DoGetWebhooks(r.Context(), userID)
+	if err != nil {
+		c.log.Error("Error getting webhooks", zap.Error(err))
+		http.Error(w, "Error retrieving webhooks", http.StatusInternalServerError)
+		return
+	}
+	if len(hooks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, hooks, c.log)
+}
+
+// DelWebhook handles the HTTP request for removing a webhook of a user.
+func (c *Controller) DelWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = c.uc.DoDelWebhook(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, webhook.ErrNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error deleting webhook", zap.Error(err))
+		http.Error(w, "Error deleting webhook", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries handles the HTTP request for the delivery log of a webhook.
+// With the "dead" route the log is limited to dead letters.
+func (c *Controller) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, err := c.auth.CheckCookie(w, r)
+	if err != nil {
+		c.log.Error("Unauthorized access: ", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	get := c.uc.DoGetDeliveries
+	if chi.URLParam(r, "kind") == "dead-letters" {
+		get = c.uc.DoGetDeadLetters
+	}
+
+	deliveries, err := get(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, webhook.ErrNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.log.Error("Error getting webhook deliveries", zap.Error(err))
+		http.Error(w, "Error retrieving deliveries", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries, c.log)
+}
+
+// writeJSON serializes v and writes it with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}, log *zap.Logger) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		log.Error("Error serializing response to JSON", zap.Error(err))
+		http.Error(w, "Error serializing response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(jsonData); err != nil {
+		log.Error("Failed to write response", zap.Error(err))
+	}
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
+)
+
+func TestAddWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		rejected       bool
+	}{
+		{name: "Valid", body: `{"url": "https://hooks.example.com", "events": ["link.created"]}`, expectedStatus: http.StatusCreated},
+		{name: "Invalid URL", body: `{"url": "hooks"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Unknown event", body: `{"url": "https://hooks.example.com", "events": ["link.exploded"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Internal host", body: `{"url": "http://169.254.169.254/latest"}`, expectedStatus: http.StatusBadRequest, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, db, _ := Ctrl(t)
+			if tt.expectedStatus == http.StatusCreated {
+				db.EXPECT().DoAddWebhook(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ interface{}, _ string, hook *entity.Webhook) (*entity.Webhook, error) {
+						hook.ID = "id"
+						hook.Secret = "secret"
+						return hook, nil
+					}).Times(1)
+			}
+			if tt.rejected {
+				db.EXPECT().DoAddWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, webhook.ErrInvalidURL).Times(1)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctrl.AddWebhook(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestDelWebhookNotFound(t *testing.T) {
+	ctrl, db, _ := Ctrl(t)
+	db.EXPECT().DoDelWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Return(webhook.ErrNotFound).Times(1)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/user/webhooks/unknown", nil)
+	w := httptest.NewRecorder()
+	ctrl.DelWebhook(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Account is a registered user that logs in with a username and password
+// instead of being identified by a cookie alone.
+type Account struct {
+	UserID       string    `json:"user_id"`           // UserID is the user ID the account owns links as
+	Username     string    `json:"username"`          // Username is the unique, lowercase login name
+	PasswordHash string    `json:"-"`                 // PasswordHash is the bcrypt hash of the password
+	CreatedAt    time.Time `json:"created_at"`        // CreatedAt is the registration time
+	Claimed      int       `json:"claimed,omitempty"` // Claimed is the number of links claimed by the last login
+}
+
+// Identity links the subject of an external identity provider to a user ID.
+type Identity struct {
+	Issuer    string    `json:"issuer"`            // Issuer identifies the provider
+	Subject   string    `json:"subject"`           // Subject is the user's ID at the provider
+	UserID    string    `json:"user_id"`           // UserID is the shortener user of the subject
+	CreatedAt time.Time `json:"created_at"`        // CreatedAt is the time of the first login
+	Claimed   int       `json:"claimed,omitempty"` // Claimed is the number of links claimed by the last login
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Roles of users.
+const (
+	RoleUser  = "user"  // RoleUser manages its own links
+	RoleAdmin = "admin" // RoleAdmin moderates the links and users of the service
+)
+
+// LinkFilter selects the links of an admin search.
+type LinkFilter struct {
+	Query  string // Query matches a substring of the alias or the original URL
+	UserID string // UserID limits the search to the links of one user
+	Limit  int    // Limit is the maximum number of links returned
+	Offset int    // Offset is the number of matching links skipped
+}
+
+// UserCount is the number of links of a user.
+type UserCount struct {
+	UserID   string `json:"user_id"`          // UserID is the owner of the links
+	Links    int    `json:"links"`            // Links is the number of links that are not deleted
+	Deleted  int    `json:"deleted"`          // Deleted is the number of deleted links
+	Disabled int    `json:"disabled"`         // Disabled is the number of links disabled by an admin
+	Banned   bool   `json:"banned,omitempty"` // Banned is set when the user is banned
+}
+
+// Ban blocks a user from using the service.
+type Ban struct {
+	UserID    string    `json:"user_id"`          // UserID is the banned user
+	Reason    string    `json:"reason,omitempty"` // Reason is a note of the admin
+	BannedBy  string    `json:"banned_by"`        // BannedBy is the admin that banned the user
+	CreatedAt time.Time `json:"created_at"`       // CreatedAt is the time of the ban
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Scopes an API key can be granted.
+const (
+	ScopeLinksRead  = "links:read"  // ScopeLinksRead allows listing the links of the user
+	ScopeLinksWrite = "links:write" // ScopeLinksWrite allows creating and deleting links
+	ScopeStatsRead  = "stats:read"  // ScopeStatsRead allows reading the service statistics, for users with the admin role
+	ScopeAdmin      = "admin"       // ScopeAdmin allows moderating the service, for users with the admin role
+)
+
+// APIKey grants programmatic access to the API on behalf of a user, limited to its scopes.
+// Only the hash of the key is stored; the key itself is shown once when it is created.
+type APIKey struct {
+	ID         string     `json:"id"`                     // ID is the unique identifier of the key
+	UserID     string     `json:"-"`                      // UserID is the user the key acts for
+	Name       string     `json:"name,omitempty"`         // Name is a label chosen by the user
+	Prefix     string     `json:"prefix"`                 // Prefix is the beginning of the key, to recognize it
+	Hash       string     `json:"-"`                      // Hash is the SHA-256 of the key
+	Scopes     []string   `json:"scopes"`                 // Scopes lists what the key may be used for
+	CreatedAt  time.Time  `json:"created_at"`             // CreatedAt is the creation time of the key
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // LastUsedAt is the last time the key was accepted
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`   // RevokedAt is set once the key is revoked
+	Quota      *Quota     `json:"quota,omitempty"`        // Quota narrows the quota of the user for requests made with the key
+	Key        string     `json:"key,omitempty"`          // Key is the secret, only set in the response to its creation
+}
+
+// HasScope reports whether the key was granted the scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Transports of audited operations.
+const (
+	TransportHTTP     = "http"     // TransportHTTP is the HTTP API
+	TransportGRPC     = "grpc"     // TransportGRPC is the gRPC API
+	TransportInternal = "internal" // TransportInternal is the service itself, such as a background job
+)
+
+// AuditEntry records a mutating operation: who did what to which link or user, over which
+// transport and from where, with snapshots of the state before and after. Entries are
+// never changed once written.
+type AuditEntry struct {
+	ID        string          `json:"id"`                   // ID is the unique identifier of the entry
+	Action    string          `json:"action"`               // Action is the operation, such as "link.delete"
+	Alias     string          `json:"short_url,omitempty"`  // Alias is the link acted on, if any
+	Target    string          `json:"target,omitempty"`     // Target is the user or report acted on, if any
+	Actor     string          `json:"actor"`                // Actor is the user who performed the operation
+	Transport string          `json:"transport"`            // Transport is TransportHTTP, TransportGRPC or TransportInternal
+	RequestID string          `json:"request_id,omitempty"` // RequestID identifies the request in the logs
+	ClientIP  string          `json:"client_ip,omitempty"`  // ClientIP is the address of the client
+	Before    json.RawMessage `json:"before,omitempty"`     // Before is the state before the operation, absent for creations
+	After     json.RawMessage `json:"after,omitempty"`      // After is the state after the operation, absent for removals
+	At        time.Time       `json:"at"`                   // At is the time of the operation
+}
+
+// AuditFilter selects the entries of an audit log query. Empty fields match every entry.
+type AuditFilter struct {
+	Alias  string    // Alias limits the entries to one link
+	Actor  string    // Actor limits the entries to one user
+	From   time.Time // From is the earliest time of the entries
+	To     time.Time // To is the time the entries are before
+	Limit  int       // Limit is the maximum number of entries returned
+	Offset int       // Offset is the number of matching entries skipped
+}
@@ -10,31 +10,34 @@ import (
 
 // URL represents the storage structure for user data in the database.
 // It includes fields for the user's unique identifier (UUID), the original URL,
-// the shortened URL alias, a flag indicating if the record is deleted, and
-// the creation timestamp.
+// the shortened URL alias, flags indicating if the record is deleted or disabled by an admin,
+// the creation timestamp and the result of the last liveness check of the target.
 type URL struct {
-	UUID      int       `json:"user_id,omitempty"`      // UUID is the unique identifier for the user
-	URL       string    `json:"original_url,omitempty"` // URL is the original URL provided by the user
-	Alias     string    `json:"short_url,omitempty"`    // Alias is the shortened URL alias
-	IsDeleted bool      `json:"is_deleted,omitempty"`   // IsDeleted indicates if the record is marked as deleted
-	CreatedAt time.Time `json:"created_at,omitempty"`   // CreatedAt is the timestamp when the record was created
+	UUID          string    `json:"user_id,omitempty"`         // UUID is the unique identifier for the user
+	URL           string    `json:"original_url,omitempty"`    // URL is the original URL provided by the user
+	Alias         string    `json:"short_url,omitempty"`       // Alias is the shortened URL alias
+	IsDeleted     bool      `json:"is_deleted,omitempty"`      // IsDeleted indicates if the record is marked as deleted
+	Disabled      bool      `json:"disabled,omitempty"`        // Disabled indicates if an admin disabled the redirect
+	CreatedAt     time.Time `json:"created_at,omitempty"`      // CreatedAt is the timestamp when the record was created
+	LastStatus    int       `json:"last_status,omitempty"`     // LastStatus is the HTTP status of the last check, 0 if unreachable
+	LastCheckedAt time.Time `json:"last_checked_at,omitempty"` // LastCheckedAt is the time of the last liveness check
 }
 
-// MarshalJSON customizes the JSON output of URL to omit CreatedAt when zero.
+// MarshalJSON customizes the JSON output of URL to omit CreatedAt and LastCheckedAt when zero.
 func (u *URL) MarshalJSON() ([]byte, error) {
 	type Alias URL
-	if u.CreatedAt.IsZero() {
-		return json.Marshal(&struct {
-			CreatedAt interface{} `json:"created_at,omitempty"`
-			*Alias
-		}{
-			CreatedAt: nil,
-			Alias:     (*Alias)(u),
-		})
-	}
-	return json.Marshal(&struct {
+	aux := struct {
 		*Alias
+		CreatedAt     *time.Time `json:"created_at,omitempty"`
+		LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
 	}{
 		Alias: (*Alias)(u),
-	})
+	}
+	if !u.CreatedAt.IsZero() {
+		aux.CreatedAt = &u.CreatedAt
+	}
+	if !u.LastCheckedAt.IsZero() {
+		aux.LastCheckedAt = &u.LastCheckedAt
+	}
+	return json.Marshal(aux)
 }
@@ -8,7 +8,7 @@ import (
 
 func TestDBStorage(t *testing.T) {
 	original := URL{
-		UUID:      1,
+		UUID:      "0190d6c8-7a3b-7c4e-9d1f-2b3c4d5e6f70",
 		URL:       "https://example.com",
 		Alias:     "example",
 		IsDeleted: true,
@@ -26,3 +26,17 @@ func TestDBStorage(t *testing.T) {
 		t.Errorf("Error unmarshalling JSON: %v", err)
 	}
 }
+
+func TestURLMarshalOmitsZeroTimes(t *testing.T) {
+	u := &URL{URL: "https://example.com", Alias: "example", LastStatus: 404}
+
+	jsonData, err := json.Marshal(u)
+	if err != nil {
+		t.Fatalf("Error marshalling JSON: %v", err)
+	}
+
+	expected := `{"original_url":"https://example.com","short_url":"example","last_status":404}`
+	if string(jsonData) != expected {
+		t.Errorf("expected %s, got %s", expected, jsonData)
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// Link lifecycle event types.
+const (
+	EventLinkCreated = "link.created" // EventLinkCreated is emitted when a short link is stored
+	EventLinkDeleted = "link.deleted" // EventLinkDeleted is emitted when a short link is deleted
+	EventLinkClicked = "link.clicked" // EventLinkClicked is emitted when a short link is followed
+)
+
+// Event describes something that happened to a short link.
+type Event struct {
+	ID         string    `json:"id"`            // ID is the unique identifier of the event
+	Type       string    `json:"type"`          // Type is one of the Event* constants
+	UserID     string    `json:"user_id"`       // UserID is the owner of the link
+	Alias      string    `json:"alias"`         // Alias is the shortened URL alias
+	URL        string    `json:"url,omitempty"` // URL is the original URL, if known
+	OccurredAt time.Time `json:"occurred_at"`   // OccurredAt is the time the event happened
+}
+
+// Webhook is an endpoint registered by a user to receive link events.
+type Webhook struct {
+	ID        string    `json:"id"`                          // ID is the unique identifier of the webhook
+	UserID    string    `json:"-"`                           // UserID is the owner of the webhook
+	URL       string    `json:"url" validate:"required,url"` // URL is the endpoint that receives events
+	Secret    string    `json:"secret,omitempty"`            // Secret is the HMAC key, only returned on creation
+	Events    []string  `json:"events,omitempty"`            // Events limits the delivered event types, empty means all
+	CreatedAt time.Time `json:"created_at"`                  // CreatedAt is the registration time
+}
+
+// Accepts reports whether the webhook is subscribed to the event type.
+func (w *Webhook) Accepts(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is a single attempt to deliver an event to a webhook.
+type Delivery struct {
+	ID         string    `json:"id"`              // ID is the unique identifier of the attempt
+	WebhookID  string    `json:"webhook_id"`      // WebhookID is the target webhook
+	UserID     string    `json:"-"`               // UserID is the owner of the webhook
+	EventID    string    `json:"event_id"`        // EventID is the delivered event
+	EventType  string    `json:"event_type"`      // EventType is the type of the delivered event
+	Payload    []byte    `json:"-"`               // Payload is the signed request body
+	Attempt    int       `json:"attempt"`         // Attempt is the 1-based attempt number
+	Status     int       `json:"status"`          // Status is the HTTP status of the response, 0 on transport errors
+	Error      string    `json:"error,omitempty"` // Error describes a failed attempt
+	DeadLetter bool      `json:"dead_letter"`     // DeadLetter is set on the last failed attempt
+	CreatedAt  time.Time `json:"created_at"`      // CreatedAt is the time of the attempt
+}
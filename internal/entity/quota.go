@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Quota limits the links of a user. Zero limits are unlimited.
+type Quota struct {
+	MaxLinks      int `json:"max_links"`       // MaxLinks is the maximum number of links that are not deleted
+	MaxDailyLinks int `json:"max_daily_links"` // MaxDailyLinks is the maximum number of links created per UTC day
+}
+
+// Unlimited reports whether the quota has no limits.
+func (q Quota) Unlimited() bool {
+	return q.MaxLinks <= 0 && q.MaxDailyLinks <= 0
+}
+
+// QuotaUsage is the quota of a user and the links counted against it.
+type QuotaUsage struct {
+	Quota
+	Links      int       `json:"links"`       // Links is the number of links that are not deleted
+	LinksToday int       `json:"links_today"` // LinksToday is the number of links created since ResetsAt one day ago
+	ResetsAt   time.Time `json:"resets_at"`   // ResetsAt is the time the daily count starts over
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Statuses of abuse reports.
+const (
+	ReportOpen      = "open"      // ReportOpen awaits the review of an admin
+	ReportActioned  = "actioned"  // ReportActioned led to the link being disabled
+	ReportDismissed = "dismissed" // ReportDismissed was reviewed without action
+)
+
+// Report is an abuse report against a link. Reports filed against a link while it has an
+// open report are merged into that report, so that admins review every link once.
+type Report struct {
+	ID             string     `json:"id"`                    // ID is the unique identifier of the report
+	Alias          string     `json:"short_url"`             // Alias is the reported link
+	Reason         string     `json:"reason"`                // Reason is given by the first reporter
+	LastReason     string     `json:"last_reason"`           // LastReason is given by the latest reporter
+	Count          int        `json:"count"`                 // Count is the number of reports merged into this one
+	Status         string     `json:"status"`                // Status is ReportOpen, ReportActioned or ReportDismissed
+	CreatedAt      time.Time  `json:"created_at"`            // CreatedAt is the time of the first report
+	LastReportedAt time.Time  `json:"last_reported_at"`      // LastReportedAt is the time of the latest report
+	ReviewedBy     string     `json:"reviewed_by,omitempty"` // ReviewedBy is the admin who reviewed the report
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"` // ReviewedAt is set once the report is reviewed
+}
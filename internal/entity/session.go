@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Session is a login of a user on one client. Its access tokens are renewed with
+// refresh tokens until the session expires or is revoked.
+type Session struct {
+	ID        string     `json:"id"`                   // ID is the unique identifier of the session
+	UserID    string     `json:"-"`                    // UserID is the user the session belongs to
+	CreatedAt time.Time  `json:"created_at"`           // CreatedAt is the time of the login
+	ExpiresAt time.Time  `json:"expires_at"`           // ExpiresAt is the expiry of the latest refresh token
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // RevokedAt is set once the session is logged out
+	Current   bool       `json:"current,omitempty"`    // Current marks the session of the request
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,160 @@
+// Package health reports the readiness of the service from the checks of its dependencies,
+// such as the database, the file storage, the deletion queue and the gRPC server.
+//
+// Every check reports its status, its latency and the last error it had, even if it has
+// recovered since. Once the service starts shutting down it is no longer ready, so that
+// load balancers stop sending requests while the servers drain.
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of the service and of its checks.
+const (
+	StatusOK           = "ok"            // StatusOK is the status of passing checks and of a ready service
+	StatusFailing      = "failing"       // StatusFailing is the status of failing checks
+	StatusUnavailable  = "unavailable"   // StatusUnavailable is the status of a service with failing checks
+	StatusShuttingDown = "shutting_down" // StatusShuttingDown is the status of a service shutting down
+)
+
+// ErrTimeout is the error of checks that did not finish in time.
+var ErrTimeout = errors.New("check timed out")
+
+// Check checks a dependency, returning nil if it is available.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of a check.
+type CheckResult struct {
+	Status      string     `json:"status"`
+	Latency     string     `json:"latency"`
+	Error       string     `json:"error,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`    // LastError is the latest error, kept after recovery
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"` // LastErrorAt is the time of LastError
+}
+
+// Report is the readiness of the service with the results of its checks.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Ready reports whether the service is ready.
+func (r *Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// check is a registered check with its last error.
+type check struct {
+	name        string
+	fn          Check
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// Checker runs the checks of the dependencies of the service.
+type Checker struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checks       []*check
+	shutdownOnce sync.Once
+	shuttingDown chan struct{} // shuttingDown is closed by Shutdown
+}
+
+// New creates a Checker without checks, giving every check timeout to finish.
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, shuttingDown: make(chan struct{})}
+}
+
+// Register adds the check of a dependency, reported under name.
+func (c *Checker) Register(name string, fn Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, &check{name: name, fn: fn})
+	sort.Slice(c.checks, func(i, j int) bool { return c.checks[i].name < c.checks[j].name })
+}
+
+// Shutdown marks the service as shutting down, which is never ready again.
+func (c *Checker) Shutdown() {
+	c.shutdownOnce.Do(func() { close(c.shuttingDown) })
+}
+
+// ShuttingDown returns a channel closed when the service starts shutting down, for
+// reporters of the readiness that must not wait for their next check.
+func (c *Checker) ShuttingDown() <-chan struct{} {
+	return c.shuttingDown
+}
+
+// Check runs the checks concurrently and reports the readiness of the service. The
+// service is ready if every check passes and it is not shutting down, in which case
+// the checks are not run.
+func (c *Checker) Check(ctx context.Context) *Report {
+	select {
+	case <-c.shuttingDown:
+		return &Report{Status: StatusShuttingDown}
+	default:
+	}
+
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, ch)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, ch := range checks {
+		report.Checks[ch.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+// run runs a check with the timeout. A check ignoring its context is abandoned when
+// the timeout expires and reported as timed out.
+func (c *Checker) run(ctx context.Context, ch *check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- ch.fn(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrTimeout
+	}
+	latency := time.Since(start)
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	result := CheckResult{Status: StatusOK, Latency: latency.String()}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+		ch.lastError = err.Error()
+		ch.lastErrorAt = start
+	}
+	if ch.lastError != "" {
+		at := ch.lastErrorAt
+		result.LastError = ch.lastError
+		result.LastErrorAt = &at
+	}
+	return result
+}
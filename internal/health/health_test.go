@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	checker := New(50 * time.Millisecond)
+	var dbErr error
+	checker.Register("database", func(context.Context) error { return dbErr })
+	checker.Register("deletion_queue", func(context.Context) error { return nil })
+
+	report := checker.Check(context.Background())
+	assert.True(t, report.Ready())
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	assert.NotEmpty(t, report.Checks["database"].Latency)
+	assert.Nil(t, report.Checks["database"].LastErrorAt)
+
+	dbErr = errors.New("connection refused")
+	report = checker.Check(context.Background())
+	assert.False(t, report.Ready())
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, StatusFailing, report.Checks["database"].Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+	assert.Equal(t, StatusOK, report.Checks["deletion_queue"].Status)
+
+	dbErr = nil
+	report = checker.Check(context.Background())
+	assert.True(t, report.Ready())
+	assert.Empty(t, report.Checks["database"].Error)
+	assert.Equal(t, "connection refused", report.Checks["database"].LastError, "the last error is kept after recovery")
+	require.NotNil(t, report.Checks["database"].LastErrorAt)
+
+	checker.Shutdown()
+	report = checker.Check(context.Background())
+	assert.False(t, report.Ready())
+	assert.Equal(t, StatusShuttingDown, report.Status)
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	checker := New(10 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	checker.Register("grpc", func(context.Context) error {
+		<-release // ignores its context
+		return nil
+	})
+
+	report := checker.Check(context.Background())
+	assert.False(t, report.Ready())
+	assert.Equal(t, ErrTimeout.Error(), report.Checks["grpc"].Error)
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryInterceptor returns the unary server interceptor recording the count and duration
+// of calls by method and status code.
+func (m *Metrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observeGRPC(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor returns the stream server interceptor recording the count and
+// duration of streams by method and status code.
+func (m *Metrics) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observeGRPC(info.FullMethod, start, err)
+		return err
+	}
+}
+
+// observeGRPC records a call of the method that started at start and ended with err.
+func (m *Metrics) observeGRPC(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.grpcRequests.WithLabelValues(method, code).Inc()
+	m.grpcDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,133 @@
+// Package metrics collects the metrics of the service and serves them on /metrics in the
+// Prometheus text exposition format.
+//
+// The metric names and labels are part of the monitoring interface of the service and
+// do not change; new metrics may be added. Durations are in seconds.
+//
+//	shortener_http_requests_total{method,route,status}                 counter
+//	shortener_http_request_duration_seconds{method,route,status}       histogram
+//	shortener_grpc_requests_total{method,code}                         counter
+//	shortener_grpc_request_duration_seconds{method,code}               histogram
+//	shortener_repository_operation_duration_seconds{backend,operation} histogram
+//	shortener_repository_errors_total{backend,operation}               counter
+//	shortener_inmemory_links                                           gauge
+//	shortener_deletion_queue_depth                                     gauge
+//
+// The Go runtime metrics, such as go_goroutines and go_memstats_alloc_bytes, are those
+// of the Go collector of the Prometheus client.
+//
+// The route of an HTTP request is its route pattern, such as "/api/user/webhooks/{id}",
+// so that aliases and IDs do not multiply the series; requests matching no route have
+// the route "unmatched". The method of a gRPC call is its full method name and the code
+// its status code, such as "OK". The backend of the repository is "memory" or
+// "postgres", and its operations are the methods of the repository, such as "Get".
+// Expected outcomes, such as lookups of missing records and conflicts, are not errors.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Names of the metrics of the service.
+const (
+	HTTPRequestsTotal     = "shortener_http_requests_total"
+	HTTPRequestDuration   = "shortener_http_request_duration_seconds"
+	GRPCRequestsTotal     = "shortener_grpc_requests_total"
+	GRPCRequestDuration   = "shortener_grpc_request_duration_seconds"
+	RepositoryDuration    = "shortener_repository_operation_duration_seconds"
+	RepositoryErrorsTotal = "shortener_repository_errors_total"
+	InMemoryLinks         = "shortener_inmemory_links"
+	DeletionQueueDepth    = "shortener_deletion_queue_depth"
+)
+
+// UnmatchedRoute is the route of HTTP requests matching no route.
+const UnmatchedRoute = "unmatched"
+
+// Buckets of the duration histograms.
+var (
+	// RequestBuckets are the buckets of HTTP and gRPC request durations.
+	RequestBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// RepositoryBuckets are the buckets of repository operation durations.
+	RepositoryBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+)
+
+// Metrics are the metrics of the service.
+type Metrics struct {
+	registry     *prometheus.Registry
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	grpcRequests *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	repoDuration *prometheus.HistogramVec
+	repoErrors   *prometheus.CounterVec
+}
+
+// New creates the metrics of the service, including the Go runtime metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: HTTPRequestsTotal, Help: "Number of HTTP requests.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: HTTPRequestDuration, Help: "Duration of HTTP requests.", Buckets: RequestBuckets,
+		}, []string{"method", "route", "status"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: GRPCRequestsTotal, Help: "Number of gRPC calls.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: GRPCRequestDuration, Help: "Duration of gRPC calls.", Buckets: RequestBuckets,
+		}, []string{"method", "code"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: RepositoryDuration, Help: "Duration of repository operations.", Buckets: RepositoryBuckets,
+		}, []string{"backend", "operation"}),
+		repoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: RepositoryErrorsTotal, Help: "Number of failed repository operations.",
+		}, []string{"backend", "operation"}),
+	}
+	m.registry.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.grpcRequests, m.grpcDuration,
+		m.repoDuration, m.repoErrors,
+		collectors.NewGoCollector(),
+	)
+	return m
+}
+
+// Registry returns the registry of the metrics, to register further metrics.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Endpoint returns the handler serving the metrics in the Prometheus exposition format.
+func (m *Metrics) Endpoint() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRepository records a repository operation of the backend. err is nil for
+// operations that succeeded or had an expected outcome.
+func (m *Metrics) ObserveRepository(backend, operation string, d time.Duration, err error) {
+	m.repoDuration.WithLabelValues(backend, operation).Observe(d.Seconds())
+	if err != nil {
+		m.repoErrors.WithLabelValues(backend, operation).Inc()
+	}
+}
+
+// SetInMemoryLinks registers the gauge of the number of links in the in-memory store.
+func (m *Metrics) SetInMemoryLinks(fn func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: InMemoryLinks, Help: "Number of links in the in-memory store, deleted ones included.",
+	}, func() float64 { return float64(fn()) }))
+}
+
+// SetDeletionQueueDepth registers the gauge of the number of links waiting to be deleted.
+func (m *Metrics) SetDeletionQueueDepth(fn func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: DeletionQueueDepth, Help: "Number of links accepted for deletion and not deleted yet.",
+	}, func() float64 { return float64(fn()) }))
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.SetDeletionQueueDepth(func() int { return 7 })
+	r := chi.NewRouter()
+	r.Use(m.Handler)
+	r.Get("/{id}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTemporaryRedirect) })
+	r.Get("/api/user/urls", func(w http.ResponseWriter, _ *http.Request) {})
+
+	for _, target := range []string{"/abc", "/def", "/api/user/urls", "/api/missing/route"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/{id}", "307")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/api/user/urls", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, UnmatchedRoute, "404")))
+	assert.Equal(t, uint64(2), histogramCount(t, m.httpDuration.WithLabelValues(http.MethodGet, "/{id}", "307")))
+
+	w := httptest.NewRecorder()
+	m.Endpoint().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	body := w.Body.String()
+	assert.Contains(t, body, `shortener_http_requests_total{method="GET",route="/{id}",status="307"} 2`)
+	assert.Contains(t, body, "shortener_deletion_queue_depth 7\n")
+	assert.Contains(t, body, "\ngo_goroutines ")
+	assert.False(t, strings.Contains(body, "/abc"), "aliases must not be labels")
+}
+
+func TestObserveRepository(t *testing.T) {
+	m := New()
+	m.ObserveRepository("memory", "Get", time.Millisecond, nil)
+	m.ObserveRepository("memory", "Get", time.Millisecond, assert.AnError)
+
+	assert.Equal(t, uint64(2), histogramCount(t, m.repoDuration.WithLabelValues("memory", "Get")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.repoErrors.WithLabelValues("memory", "Get")))
+}
+
+// histogramCount returns the number of observations of the histogram o.
+func histogramCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
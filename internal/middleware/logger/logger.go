@@ -1,5 +1,5 @@
-// Package logger provides middleware for logging HTTP requests and responses.
-// It uses the zap logger for structured logging.
+// Package logger provides middleware for logging HTTP requests and responses, and the
+// configured zap logger of the application, which redacts secrets from its entries.
 package logger
 
 import (
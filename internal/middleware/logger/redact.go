@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the secrets in log entries.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of field names whose values are always redacted.
+var sensitiveKeys = []string{"token", "cookie", "authorization", "password", "secret", "apikey", "api_key", "credential"}
+
+// Patterns of the secrets in log messages and string values.
+var (
+	// jwtPattern matches JSON Web Tokens, whose header always starts with eyJ.
+	jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// bearerPattern matches the credentials of Authorization headers.
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+	// apiKeyPattern matches the API keys of the service.
+	apiKeyPattern = regexp.MustCompile(`\bsk_[0-9a-f]{8,}`)
+	// queryPattern matches the values of query parameters named like secrets.
+	queryPattern = regexp.MustCompile(`(?i)([?&][^=&#\s]*(?:token|secret|password|key|code|sig|auth)[^=&#\s]*=)[^&#\s]*`)
+)
+
+// RedactString replaces the secrets in s: JSON Web Tokens, bearer and basic credentials,
+// API keys and the values of query parameters named like secrets, such as access_token,
+// api_key or the code of an OAuth callback.
+func RedactString(s string) string {
+	if lower := strings.ToLower(s); !strings.ContainsAny(s, "?&") && !strings.Contains(s, "eyJ") &&
+		!strings.Contains(s, "sk_") && !strings.Contains(lower, "bearer") && !strings.Contains(lower, "basic") {
+		return s
+	}
+	s = jwtPattern.ReplaceAllString(s, Redacted)
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Redacted)
+	s = apiKeyPattern.ReplaceAllString(s, "sk_"+Redacted)
+	return queryPattern.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+// Redact wraps a core to redact secrets from the entries before they are written: the
+// values of fields named like secrets, such as "token" or "cookie", and the secrets found
+// by RedactString in messages, string fields and errors.
+func Redact(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+// redactCore redacts the entries of the wrapped core.
+type redactCore struct {
+	zapcore.Core
+}
+
+// With adds the redacted fields to the core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+// Check adds the core to the checked entry if the level is enabled, so that Write redacts it.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the redacted entry.
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = RedactString(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields returns the fields with their secrets redacted, copying them only if needed.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		redacted, ok := redactField(f)
+		if !ok {
+			if out != nil {
+				out[i] = f
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields[:i])
+		}
+		out[i] = redacted
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactField returns the redacted field and true if f holds a secret.
+func redactField(f zapcore.Field) (zapcore.Field, bool) {
+	if f.Type == zapcore.SkipType {
+		return f, false
+	}
+	key := strings.ToLower(f.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return zap.String(f.Key, Redacted), true
+		}
+	}
+
+	var value string
+	switch f.Type {
+	case zapcore.StringType:
+		value = f.String
+	case zapcore.ErrorType:
+		err, ok := f.Interface.(error)
+		if !ok || err == nil {
+			return f, false
+		}
+		value = err.Error()
+	default:
+		return f, false
+	}
+	if redacted := RedactString(value); redacted != value {
+		return zap.String(f.Key, redacted), true
+	}
+	return f, false
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+)
+
+// NewLogger creates the application logger of the configuration, with its level, which may
+// be changed at runtime. Secrets in the entries are redacted as described in Redact.
+func NewLogger(cfg configuration.Log) (*zap.Logger, zap.AtomicLevel, error) {
+	level, err := zap.ParseAtomicLevel(cfg.Level)
+	if err != nil {
+		return nil, level, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case "json":
+		encoderCfg := zap.NewProductionEncoderConfig()
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	case "console", "":
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	default:
+		return nil, level, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	output, err := openOutput(cfg.Output)
+	if err != nil {
+		return nil, level, err
+	}
+
+	core := Redact(zapcore.NewCore(encoder, output, level))
+	if cfg.Sampling > 0 {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, cfg.Sampling, cfg.Thereafter)
+	}
+	return zap.New(core, zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr))), level, nil
+}
+
+// openOutput opens the output of the log: stdout, stderr or a file appended to.
+func openOutput(name string) (zapcore.WriteSyncer, error) {
+	switch name {
+	case "stderr", "":
+		return zapcore.Lock(os.Stderr), nil
+	case "stdout":
+		return zapcore.Lock(os.Stdout), nil
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output: %w", err)
+	}
+	return zapcore.Lock(file), nil
+}
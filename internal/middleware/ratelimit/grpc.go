@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Metadata keys sent with the headers of limited calls.
+const (
+	MetadataLimit      = "ratelimit-limit"
+	MetadataRemaining  = "ratelimit-remaining"
+	MetadataReset      = "ratelimit-reset"
+	MetadataRetryAfter = "retry-after"
+)
+
+// ContextKeyFunc identifies the client of a gRPC call, as "user:<id>" or "ip:<address>".
+// An empty key takes no token.
+type ContextKeyFunc func(ctx context.Context) string
+
+// Interceptor limits gRPC calls with the limiter of their method.
+type Interceptor struct {
+	methods map[string]*Limiter
+	keys    []ContextKeyFunc
+}
+
+// NewInterceptor creates an Interceptor limiting the methods, given by their full names,
+// for the clients, which take a token from the bucket of every key. Methods without a
+// limiter are not limited.
+func NewInterceptor(methods map[string]*Limiter, keys ...ContextKeyFunc) *Interceptor {
+	return &Interceptor{methods: methods, keys: keys}
+}
+
+// Unary returns the unary server interceptor.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := i.allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor. A stream takes one token when it opens.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.allow(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allow takes a token for the call and sends the rate limit headers. Denied calls
+// get ResourceExhausted with RetryInfo details.
+func (i *Interceptor) allow(ctx context.Context, method string) error {
+	l := i.methods[method]
+	if l == nil {
+		return nil
+	}
+	values := make([]string, len(i.keys))
+	for j, key := range i.keys {
+		values[j] = key(ctx)
+	}
+	res := l.AllowKeys(ctx, values...)
+	md := metadata.Pairs(
+		MetadataLimit, strconv.Itoa(res.Limit),
+		MetadataRemaining, strconv.Itoa(res.Remaining),
+		MetadataReset, strconv.Itoa(ceilSeconds(res.Reset)),
+	)
+	if res.Allowed {
+		_ = grpc.SetHeader(ctx, md)
+		return nil
+	}
+
+	retryAfter := max(1, ceilSeconds(res.RetryAfter))
+	md.Set(MetadataRetryAfter, strconv.Itoa(retryAfter))
+	_ = grpc.SetHeader(ctx, md)
+	st, err := status.New(codes.ResourceExhausted, "Too many requests").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(seconds(float64(retryAfter))),
+	})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "Too many requests")
+	}
+	return st.Err()
+}
+
+// PeerIP returns the IP address of the peer of a gRPC call.
+func PeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
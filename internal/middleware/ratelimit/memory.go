@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of takes between removals of full buckets.
+const sweepInterval = 1024
+
+// memoryBucket is a bucket with the limit it was last taken with.
+type memoryBucket struct {
+	Bucket
+	limit Limit
+}
+
+// MemoryStore keeps the buckets in the memory of one instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	takes   int
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket), now: time.Now}
+}
+
+// TakeToken takes a token from the bucket of the key.
+func (s *MemoryStore) TakeToken(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.takes++
+	if s.takes%sweepInterval == 0 {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{Bucket: NewBucket(limit, now)}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	return b.Take(limit, now), nil
+}
+
+// sweep forgets the buckets that have refilled, since a new bucket would be full too.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.Full(b.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
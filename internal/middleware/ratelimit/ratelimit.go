@@ -0,0 +1,205 @@
+// Package ratelimit provides token-bucket rate limiting of HTTP requests and gRPC calls.
+// Every route group has its own limit, and every client its own buckets per group,
+// keyed by user ID and client IP. The buckets are kept in a Store, which may be shared
+// by several instances of the service.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+)
+
+// Response headers of limited requests.
+const (
+	HeaderLimit      = "RateLimit-Limit"     // HeaderLimit is the size of the bucket
+	HeaderRemaining  = "RateLimit-Remaining" // HeaderRemaining is the number of requests left now
+	HeaderReset      = "RateLimit-Reset"     // HeaderReset is the number of seconds until the bucket is full
+	HeaderRetryAfter = "Retry-After"         // HeaderRetryAfter is the number of seconds until the next allowed request
+)
+
+// Route groups, shared by HTTP and gRPC so that a client has one bucket per group.
+const (
+	GroupWrite  = "write"  // GroupWrite limits link creation
+	GroupAuth   = "auth"   // GroupAuth limits registration and logins
+	GroupReport = "report" // GroupReport limits abuse reports
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate requests per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// LimitOf converts a configured rule into a Limit. Rules without requests give a zero Limit.
+func LimitOf(rule configuration.RateRule) Limit {
+	if rule.Requests <= 0 || rule.Period <= 0 {
+		return Limit{}
+	}
+	burst := rule.Burst
+	if burst <= 0 {
+		burst = rule.Requests
+	}
+	return Limit{Rate: float64(rule.Requests) / time.Duration(rule.Period).Seconds(), Burst: burst}
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed    bool          // Allowed is set when a token was taken
+	Limit      int           // Limit is the size of the bucket
+	Remaining  int           // Remaining is the number of whole tokens left
+	RetryAfter time.Duration // RetryAfter is the time until the next token, for denied requests
+	Reset      time.Duration // Reset is the time until the bucket is full
+}
+
+// Bucket is the state of a token bucket.
+type Bucket struct {
+	Tokens    float64   // Tokens is the number of tokens at UpdatedAt
+	UpdatedAt time.Time // UpdatedAt is the time of the last take
+}
+
+// NewBucket returns a full bucket.
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+// Take refills the bucket up to now and takes a token if one is available.
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*limit.Rate)
+	}
+	b.UpdatedAt = now
+
+	res := Result{Limit: limit.Burst}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.Tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.Tokens)
+	res.Reset = seconds((float64(limit.Burst) - b.Tokens) / limit.Rate)
+	return res
+}
+
+// Full reports whether the bucket would be full at now, so that it can be forgotten.
+func (b *Bucket) Full(limit Limit, now time.Time) bool {
+	return b.Tokens+now.Sub(b.UpdatedAt).Seconds()*limit.Rate >= float64(limit.Burst)
+}
+
+// seconds converts seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Store keeps the buckets of the clients.
+type Store interface {
+	// TakeToken takes a token from the bucket of the key, creating a full bucket first
+	// if there is none.
+	TakeToken(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// KeyFunc identifies the client of a request, as "user:<id>" or "ip:<address>". An empty
+// key takes no token.
+type KeyFunc func(r *http.Request) string
+
+// Limiter limits the requests of one route group.
+type Limiter struct {
+	group string
+	limit Limit
+	store Store
+	log   *zap.Logger
+}
+
+// New creates a Limiter of the group. It returns nil if the limit is zero, which
+// disables limiting of the group.
+func New(group string, limit Limit, store Store, log *zap.Logger) *Limiter {
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return nil
+	}
+	return &Limiter{group: group, limit: limit, store: store, log: log}
+}
+
+// Allow takes a token of the client with the key. If the store fails, the request is allowed.
+func (l *Limiter) Allow(ctx context.Context, key string) Result {
+	res, err := l.store.TakeToken(ctx, l.group+":"+key, l.limit)
+	if err != nil {
+		l.log.Error("rate limit store failed", zap.String("group", l.group), zap.Error(err))
+		return Result{Allowed: true, Limit: l.limit.Burst, Remaining: l.limit.Burst}
+	}
+	return res
+}
+
+// AllowKeys takes a token from the bucket of every non-empty key in turn, so that a
+// client is limited by the emptiest of its buckets. It stops at the first denial, and
+// otherwise returns the result with the fewest remaining requests.
+func (l *Limiter) AllowKeys(ctx context.Context, keys ...string) Result {
+	res := Result{Allowed: true, Limit: l.limit.Burst, Remaining: l.limit.Burst}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		next := l.Allow(ctx, key)
+		if !next.Allowed {
+			return next
+		}
+		if next.Remaining <= res.Remaining {
+			res = next
+		}
+	}
+	return res
+}
+
+// Handler returns a middleware limiting the requests of the clients, which take a token
+// from the bucket of every key. Rejected requests get 429 Too Many Requests.
+func (l *Limiter) Handler(keys ...KeyFunc) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			values := make([]string, len(keys))
+			for i, key := range keys {
+				values[i] = key(r)
+			}
+			res := l.AllowKeys(r.Context(), values...)
+			WriteHeaders(w, res)
+			if !res.Allowed {
+				l.log.Info("rate limit exceeded", zap.String("group", l.group), zap.String("path", r.URL.Path))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// WriteHeaders sets the RateLimit headers of the result, and Retry-After if the request was denied.
+func WriteHeaders(w http.ResponseWriter, res Result) {
+	h := w.Header()
+	h.Set(HeaderLimit, strconv.Itoa(res.Limit))
+	h.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+	h.Set(HeaderReset, strconv.Itoa(ceilSeconds(res.Reset)))
+	if !res.Allowed {
+		h.Set(HeaderRetryAfter, strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ClientIP returns the IP address of the peer of the request.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+)
+
+func TestBucket(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBucket(limit, now)
+
+	res := b.Take(limit, now)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	assert.Equal(t, time.Second, res.Reset)
+
+	res = b.Take(limit, now)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res = b.Take(limit, now.Add(500*time.Millisecond))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, res.Reset)
+
+	res = b.Take(limit, now.Add(time.Second))
+	assert.True(t, res.Allowed)
+	assert.False(t, b.Full(limit, now.Add(2*time.Second)))
+	assert.True(t, b.Full(limit, now.Add(3*time.Second)))
+}
+
+func TestLimitOf(t *testing.T) {
+	minute := configuration.Duration(time.Minute)
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 30}, LimitOf(configuration.RateRule{Requests: 30, Period: minute}))
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 5}, LimitOf(configuration.RateRule{Requests: 30, Period: minute, Burst: 5}))
+	assert.Equal(t, Limit{}, LimitOf(configuration.RateRule{Period: minute}))
+	assert.Nil(t, New(GroupWrite, Limit{}, NewMemoryStore(), zap.NewNop()))
+}
+
+func TestHandler(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	l := New(GroupWrite, Limit{Rate: 0.1, Burst: 2}, store, zap.NewNop())
+	handler := l.Handler(ClientIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	request := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		addr           string
+		expectedStatus int
+		remaining      string
+		retryAfter     string
+	}{
+		{name: "First", addr: "192.0.2.1:1234", expectedStatus: http.StatusCreated, remaining: "1"},
+		{name: "Second", addr: "192.0.2.1:1235", expectedStatus: http.StatusCreated, remaining: "0"},
+		{name: "Limited", addr: "192.0.2.1:1236", expectedStatus: http.StatusTooManyRequests, remaining: "0", retryAfter: "10"},
+		{name: "Other client", addr: "192.0.2.2:1234", expectedStatus: http.StatusCreated, remaining: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.addr)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "2", w.Header().Get(HeaderLimit))
+			assert.Equal(t, tt.remaining, w.Header().Get(HeaderRemaining))
+			assert.Equal(t, tt.retryAfter, w.Header().Get(HeaderRetryAfter))
+		})
+	}
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, http.StatusCreated, request("192.0.2.1:1237").Code)
+}
+
+type failingStore struct{}
+
+func (failingStore) TakeToken(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("store is down")
+}
+
+func TestAllowFailsOpen(t *testing.T) {
+	l := New(GroupAuth, Limit{Rate: 1, Burst: 1}, failingStore{}, zap.NewNop())
+	res := l.Allow(context.Background(), "ip:192.0.2.1")
+	assert.True(t, res.Allowed)
+}
+
+func TestAllowKeys(t *testing.T) {
+	l := New(GroupWrite, Limit{Rate: 0.001, Burst: 2}, NewMemoryStore(), zap.NewNop())
+	ctx := context.Background()
+
+	res := l.AllowKeys(ctx, "ip:192.0.2.1", "user:a")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	// A new user from the same address shares the bucket of the address.
+	res = l.AllowKeys(ctx, "ip:192.0.2.1", "user:b")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining, "the emptiest bucket is reported")
+	assert.False(t, l.AllowKeys(ctx, "ip:192.0.2.1", "user:c").Allowed)
+
+	// Empty keys take no token.
+	assert.True(t, l.AllowKeys(ctx, "", "user:c").Allowed)
+}
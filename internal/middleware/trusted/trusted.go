@@ -0,0 +1,174 @@
+// Package trusted resolves the client IP of requests that pass through trusted proxies
+// and restricts routes to clients in trusted networks. Forwarding headers are believed
+// only when the peer is a trusted proxy, so clients cannot spoof their address.
+package trusted
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// HeaderForwardedFor is the header proxies append the address of their peer to.
+const HeaderForwardedFor = "X-Forwarded-For"
+
+// Networks is a set of IPv4 and IPv6 networks.
+type Networks []netip.Prefix
+
+// Parse parses a list of networks in CIDR notation. Single addresses are networks of
+// one address, and list items may themselves be comma-separated.
+func Parse(list ...string) (Networks, error) {
+	var networks Networks
+	for _, item := range list {
+		for _, s := range strings.Split(item, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			if !strings.Contains(s, "/") {
+				addr, err := netip.ParseAddr(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid trusted network %q: %w", s, err)
+				}
+				addr = addr.Unmap()
+				networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+				continue
+			}
+			prefix, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted network %q: %w", s, err)
+			}
+			if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+				prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+			}
+			networks = append(networks, prefix.Masked())
+		}
+	}
+	return networks, nil
+}
+
+// Contains reports whether the address is in one of the networks. IPv4-mapped IPv6
+// addresses match IPv4 networks.
+func (n Networks) Contains(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range n {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolver resolves the client IP of requests behind trusted proxies.
+type Resolver struct {
+	proxies Networks
+}
+
+// NewResolver creates a Resolver that believes the forwarding headers of the proxies.
+// Without proxies the client IP is the peer of the request.
+func NewResolver(proxies Networks) *Resolver {
+	return &Resolver{proxies: proxies}
+}
+
+// ClientIP returns the address of the client of the request. If the peer is a trusted
+// proxy, X-Forwarded-For is walked from right to left, skipping trusted proxies, and the
+// first other address is the client. An invalid address on the way gives the zero Addr,
+// which no network contains.
+func (r *Resolver) ClientIP(req *http.Request) netip.Addr {
+	addr := PeerAddr(req)
+	if !r.proxies.Contains(addr) {
+		return addr
+	}
+
+	hops := forwardedFor(req.Header.Values(HeaderForwardedFor))
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			return netip.Addr{}
+		}
+		addr = hop.Unmap()
+		if !r.proxies.Contains(addr) {
+			return addr
+		}
+	}
+	// Every hop is a trusted proxy, so the leftmost one is the client
+	return addr
+}
+
+// forwardedFor splits the values of X-Forwarded-For headers into their hops, in order.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// PeerAddr returns the address of the peer of the request, or the zero Addr if it
+// is not an IP address.
+func PeerAddr(req *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// Guard admits the requests of clients in the trusted networks.
+type Guard struct {
+	networks Networks
+	resolver *Resolver
+	log      *zap.Logger
+}
+
+// NewGuard creates a Guard of the networks. A Guard without networks rejects every request.
+func NewGuard(networks Networks, resolver *Resolver, log *zap.Logger) *Guard {
+	return &Guard{networks: networks, resolver: resolver, log: log}
+}
+
+// Check reports whether the client of the request is in the trusted networks.
+// Otherwise it writes the 403 Forbidden response.
+func (g *Guard) Check(w http.ResponseWriter, r *http.Request) bool {
+	if len(g.networks) == 0 {
+		g.log.Error("trusted subnet is not configured", zap.String("path", r.URL.Path))
+		http.Error(w, "Access forbidden: trusted subnet is not configured", http.StatusForbidden)
+		return false
+	}
+	if ip := g.resolver.ClientIP(r); !g.networks.Contains(ip) {
+		g.log.Info("client IP is not in trusted subnet", zap.String("client_ip", ip.String()), zap.String("path", r.URL.Path))
+		http.Error(w, "Access forbidden: client IP is not in trusted subnet", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// Trusted reports whether the client of the request is in the trusted networks, without
+// responding to it.
+func (g *Guard) Trusted(r *http.Request) bool {
+	return len(g.networks) > 0 && g.networks.Contains(g.resolver.ClientIP(r))
+}
+
+// Handler returns a middleware admitting only the requests of clients in the trusted
+// networks, rejecting others with 403 Forbidden.
+func (g *Guard) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.Check(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package trusted
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParse(t *testing.T) {
+	networks, err := Parse("10.0.0.0/8, 2001:db8::/32", "192.0.2.7,::ffff:198.51.100.0/120")
+	require.NoError(t, err)
+	require.Len(t, networks, 4)
+	assert.Equal(t, "192.0.2.7/32", networks[2].String())
+	assert.Equal(t, "198.51.100.0/24", networks[3].String())
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "10.1.2.3", want: true},
+		{addr: "::ffff:10.1.2.3", want: true},
+		{addr: "2001:db8::1", want: true},
+		{addr: "192.0.2.7", want: true},
+		{addr: "192.0.2.8"},
+		{addr: "198.51.100.200", want: true},
+		{addr: "2001:db9::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, networks.Contains(netip.MustParseAddr(tt.addr)))
+		})
+	}
+	assert.False(t, networks.Contains(netip.Addr{}))
+
+	empty, err := Parse("", " , ")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+	_, err = Parse("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = Parse("localhost")
+	assert.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := Parse("10.0.0.0/8", "fd00::/8")
+	require.NoError(t, err)
+	r := NewResolver(proxies)
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  []string
+		want          string
+		wantInvalidIP bool
+	}{
+		{name: "Direct client", remoteAddr: "203.0.113.5:1234", want: "203.0.113.5"},
+		{name: "Spoofed header from a client", remoteAddr: "203.0.113.5:1234", forwardedFor: []string{"10.1.1.1"}, want: "203.0.113.5"},
+		{name: "One proxy", remoteAddr: "10.0.0.2:80", forwardedFor: []string{"203.0.113.5"}, want: "203.0.113.5"},
+		{name: "Spoofed hop before the client", remoteAddr: "10.0.0.2:80", forwardedFor: []string{"10.9.9.9, 203.0.113.5"}, want: "203.0.113.5"},
+		{name: "Proxy chain", remoteAddr: "[fd00::1]:80", forwardedFor: []string{"198.51.100.1, 203.0.113.5", "10.0.0.3"}, want: "203.0.113.5"},
+		{name: "IPv6 client", remoteAddr: "10.0.0.2:80", forwardedFor: []string{"2001:db8::5"}, want: "2001:db8::5"},
+		{name: "Only proxies", remoteAddr: "10.0.0.2:80", forwardedFor: []string{"10.0.0.4, 10.0.0.3"}, want: "10.0.0.4"},
+		{name: "Proxy without header", remoteAddr: "10.0.0.2:80", want: "10.0.0.2"},
+		{name: "Invalid hop", remoteAddr: "10.0.0.2:80", forwardedFor: []string{"unknown, 10.0.0.3"}, wantInvalidIP: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add(HeaderForwardedFor, value)
+			}
+			ip := r.ClientIP(req)
+			if tt.wantInvalidIP {
+				assert.False(t, ip.IsValid())
+				return
+			}
+			assert.Equal(t, tt.want, ip.String())
+		})
+	}
+}
+
+func TestGuard(t *testing.T) {
+	proxies, err := Parse("10.0.0.0/8")
+	require.NoError(t, err)
+	subnets, err := Parse("192.168.0.0/16,2001:db8::/32")
+	require.NoError(t, err)
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	tests := []struct {
+		name         string
+		subnets      Networks
+		remoteAddr   string
+		forwardedFor string
+		want         int
+	}{
+		{name: "Trusted client", subnets: subnets, remoteAddr: "192.168.1.1:1234", want: http.StatusOK},
+		{name: "Trusted IPv6 client", subnets: subnets, remoteAddr: "[2001:db8::1]:1234", want: http.StatusOK},
+		{name: "Trusted client behind proxy", subnets: subnets, remoteAddr: "10.0.0.2:80", forwardedFor: "192.168.1.1", want: http.StatusOK},
+		{name: "Spoofed header", subnets: subnets, remoteAddr: "203.0.113.5:1234", forwardedFor: "192.168.1.1", want: http.StatusForbidden},
+		{name: "Untrusted client behind proxy", subnets: subnets, remoteAddr: "10.0.0.2:80", forwardedFor: "192.168.1.1, 203.0.113.5", want: http.StatusForbidden},
+		{name: "No subnets", remoteAddr: "192.168.1.1:1234", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGuard(tt.subnets, NewResolver(proxies), zap.NewNop())
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set(HeaderForwardedFor, tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			g.Handler(next).ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.want == http.StatusOK, g.Trusted(req))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// NewWriterExporter creates an exporter writing spans to w as JSON lines.
+func NewWriterExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w))
+}
+
+// NewFileExporter creates an exporter that appends to the named file,
+// or writes to stdout if name is empty or "-".
+func NewFileExporter(name string) (sdktrace.SpanExporter, error) {
+	if name == "" || name == "-" {
+		return NewWriterExporter(os.Stdout)
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := NewWriterExporter(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &fileExporter{SpanExporter: exporter, file: file}, nil
+}
+
+// fileExporter is an exporter writing to a file, which it closes on Shutdown.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+// Shutdown shuts the exporter down and closes the file.
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	if err := e.SpanExporter.Shutdown(ctx); err != nil {
+		_ = e.file.Close()
+		return err
+	}
+	return e.file.Close()
+}
+
+// NewOTLPExporter creates an exporter sending spans with OTLP over HTTP to the traces
+// endpoint of a collector, such as "http://localhost:4318/v1/traces", with the headers,
+// for example for authentication.
+func NewOTLPExporter(ctx context.Context, endpoint string, headers map[string]string) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint), otlptracehttp.WithHeaders(headers))
+}
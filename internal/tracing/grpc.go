@@ -0,0 +1,20 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// ServerOption returns the option of a gRPC server tracing its calls and streams with a
+// server span named by the full method, continuing the trace of the traceparent metadata.
+// Codes reporting a failure of the server, rather than of the request, mark the span as
+// failed.
+func (t *Tracer) ServerOption() grpc.ServerOption {
+	if t == nil {
+		return grpc.EmptyServerOption{}
+	}
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(
+		otelgrpc.WithTracerProvider(t.provider),
+		otelgrpc.WithPropagators(propagator),
+	))
+}
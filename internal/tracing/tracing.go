@@ -0,0 +1,72 @@
+// Package tracing records spans of HTTP requests, gRPC calls and repository operations
+// with OpenTelemetry and exports them in batches to a file or an OpenTelemetry collector.
+//
+// The trace context is propagated with the W3C Trace Context headers "traceparent" and
+// "tracestate": spans of requests carrying a valid traceparent continue the trace of the
+// caller and follow its sampling decision. New traces are sampled with the configured
+// ratio, decided by the trace ID so that every service samples a trace alike.
+//
+// A nil *Tracer is valid and records nothing; the spans it starts do nothing.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// scopeName is the instrumentation scope of the spans of the service.
+const scopeName = "github.com/nextlag/shortenerURL/internal/tracing"
+
+// propagator reads and writes the W3C Trace Context headers.
+var propagator = propagation.TraceContext{}
+
+// Tracer starts spans and exports the sampled ones in the background.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// New creates a Tracer sampling ratio of the new traces, between 0 and 1, and exporting
+// them with the exporter. The spans are attributed to the service. Shutdown must be
+// called to export the last spans.
+func New(exporter sdktrace.SpanExporter, ratio float64, service string) *Tracer {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+	)
+	return &Tracer{provider: provider, tracer: provider.Tracer(scopeName)}
+}
+
+// Start starts a span named name as a child of the span or remote span context of ctx,
+// and returns a copy of ctx carrying it. The span must be ended with End.
+func (t *Tracer) Start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, noop.Span{}
+	}
+	return t.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// Shutdown exports the queued spans and closes the exporter. Spans ending later are
+// dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// RecordError marks the span as failed with err, if err is not nil.
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,194 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// recorder keeps the exported spans past Shutdown, which resets an in-memory exporter.
+type recorder struct {
+	*tracetest.InMemoryExporter
+}
+
+func newRecorder() recorder {
+	return recorder{InMemoryExporter: tracetest.NewInMemoryExporter()}
+}
+
+func (recorder) Shutdown(context.Context) error {
+	return nil
+}
+
+// withParent returns a copy of ctx carrying the remote span context of parent and the
+// tracestate.
+func withParent(ctx context.Context, tracestate string) context.Context {
+	carrier := propagation.MapCarrier{"traceparent": parent, "tracestate": tracestate}
+	return propagator.Extract(ctx, carrier)
+}
+
+// attributeValue returns the value of the attribute key of the span.
+func attributeValue(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestSampling(t *testing.T) {
+	exporter := newRecorder()
+	tracer := New(exporter, 0, "test")
+
+	_, root := tracer.Start(context.Background(), "root", trace.SpanKindInternal)
+	root.End()
+	assert.False(t, root.SpanContext().IsSampled())
+	assert.True(t, root.SpanContext().IsValid(), "unsampled spans still propagate")
+
+	ctx, span := tracer.Start(withParent(context.Background(), "vendor=value"), "continued", trace.SpanKindServer)
+	_, child := tracer.Start(ctx, "child", trace.SpanKindInternal)
+	RecordError(child, errors.New("failed"))
+	child.End()
+	span.End()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].Parent.SpanID().String())
+	assert.Equal(t, "vendor=value", spans[1].SpanContext.TraceState().String())
+
+	half := New(newRecorder(), 0.5, "test")
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		_, s := half.Start(context.Background(), "root", trace.SpanKindInternal)
+		if s.SpanContext().IsSampled() {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.Background(), "span", trace.SpanKindInternal)
+	span.SetAttributes(attribute.String("key", "value"))
+	RecordError(span, errors.New("failed"))
+	span.End()
+	assert.False(t, span.SpanContext().IsValid())
+	assert.Equal(t, context.Background(), ctx)
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+}
+
+func TestHandler(t *testing.T) {
+	exporter := newRecorder()
+	tracer := New(exporter, 1, "test")
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(tracer.Handler)
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracer.Start(r.Context(), "lookup", trace.SpanKindInternal)
+		span.End()
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+	req.Header.Set("traceparent", parent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	server := spans[1]
+	assert.Equal(t, "GET /{id}", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, "/{id}", attributeValue(server, "http.route").AsString())
+	assert.NotEmpty(t, attributeValue(server, "http.request_id").AsString())
+	assert.Equal(t, server.SpanContext.SpanID(), spans[0].Parent.SpanID())
+}
+
+func TestServerOption(t *testing.T) {
+	exporter := newRecorder()
+	tracer := New(exporter, 1, "test")
+	listener := bufconn.Listen(1 << 16)
+	server := grpc.NewServer(tracer.ServerOption())
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", parent)
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	require.Error(t, err, "unknown services are not found")
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "grpc.health.v1.Health/Check", spans[0].Name)
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, codes.Unset, spans[1].Status.Code, "client errors do not fail the span")
+	assert.False(t, spans[1].Parent.IsValid())
+}
+
+func TestExporters(t *testing.T) {
+	var path, authorization string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, authorization = r.URL.Path, r.Header.Get("Authorization")
+	}))
+	defer collector.Close()
+
+	var buf bytes.Buffer
+	writer, err := NewWriterExporter(&buf)
+	require.NoError(t, err)
+	tracer := New(writer, 1, "test")
+	_, span := tracer.Start(withParent(context.Background(), ""), "span", trace.SpanKindClient)
+	span.SetAttributes(attribute.Int("count", 3))
+	span.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	assert.Contains(t, buf.String(), `"TraceID":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, buf.String(), `"Key":"count"`)
+
+	otlp, err := NewOTLPExporter(context.Background(), collector.URL+"/v1/traces", map[string]string{"Authorization": "secret"})
+	require.NoError(t, err)
+	tracer = New(otlp, 1, "test")
+	_, span = tracer.Start(context.Background(), "span", trace.SpanKindClient)
+	span.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "secret", authorization)
+}
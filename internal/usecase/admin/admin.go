@@ -0,0 +1,45 @@
+// Package admin defines the storage of moderation: searching the links of all users,
+// disabling links and counting the links of every user.
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// Limits of a link search.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// ErrLinkNotFound is returned when a link to moderate does not exist.
+var ErrLinkNotFound = errors.New("link not found")
+
+// Store keeps the links as seen by admins.
+type Store interface {
+	// SearchLinks retrieves the links of all users matching the filter, deleted ones
+	// included, ordered by alias.
+	SearchLinks(ctx context.Context, filter entity.LinkFilter) ([]*entity.URL, error)
+	// SetDisabled disables or enables the redirect of a link.
+	SetDisabled(ctx context.Context, alias string, disabled bool) error
+	// GetUserCounts counts the links of every user and marks the banned ones, ordered
+	// by user ID.
+	GetUserCounts(ctx context.Context) ([]*entity.UserCount, error)
+}
+
+// NormalizeFilter limits the page size of a filter to DefaultLimit when unset and to MaxLimit.
+func NormalizeFilter(filter entity.LinkFilter) entity.LinkFilter {
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = DefaultLimit
+	case filter.Limit > MaxLimit:
+		filter.Limit = MaxLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return filter
+}
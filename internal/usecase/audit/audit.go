@@ -0,0 +1,152 @@
+// Package audit defines the append-only log of mutating operations. The use cases and
+// auth record the operations through a Recorder, which takes the transport, request ID
+// and client IP from the context the controllers set up.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+)
+
+// Audited actions.
+const (
+	ActionLinkCreate   = "link.create"   // ActionLinkCreate creates a link
+	ActionLinkDelete   = "link.delete"   // ActionLinkDelete deletes a link of its owner
+	ActionLinkClaim    = "link.claim"    // ActionLinkClaim moves the links of an anonymous user into an account
+	ActionLinkDisable  = "link.disable"  // ActionLinkDisable disables the redirect of a link
+	ActionLinkEnable   = "link.enable"   // ActionLinkEnable enables the redirect of a link again
+	ActionUserBan      = "user.ban"      // ActionUserBan bans a user
+	ActionUserUnban    = "user.unban"    // ActionUserUnban lifts the ban of a user
+	ActionQuotaSet     = "quota.set"     // ActionQuotaSet overrides the quota of a user
+	ActionQuotaDelete  = "quota.delete"  // ActionQuotaDelete removes the quota override of a user
+	ActionReportReview = "report.review" // ActionReportReview closes an abuse report
+)
+
+// Limits of an audit log query.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// ErrInvalidRange is returned for queries whose time range ends before it starts.
+var ErrInvalidRange = errors.New("audit range ends before it starts")
+
+// Store keeps the audit log.
+type Store interface {
+	// PutAudit appends an entry to the audit log.
+	PutAudit(ctx context.Context, entry *entity.AuditEntry) error
+	// GetAudit retrieves the entries matching the filter, the latest first.
+	GetAudit(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error)
+}
+
+// Source describes where a request came from.
+type Source struct {
+	Transport string // Transport is entity.TransportHTTP or entity.TransportGRPC
+	RequestID string // RequestID identifies the request in the logs
+	ClientIP  string // ClientIP is the address of the client
+}
+
+// keySource is the context key of the Source of a request.
+type keySource struct{}
+
+// NewContext returns a copy of ctx carrying the source of the request.
+func NewContext(ctx context.Context, src Source) context.Context {
+	return context.WithValue(ctx, keySource{}, src)
+}
+
+// FromContext returns the source of the request carried by ctx.
+func FromContext(ctx context.Context) (Source, bool) {
+	src, ok := ctx.Value(keySource{}).(Source)
+	return src, ok
+}
+
+// Recorder appends the entries of the audited operations to a Store. A nil Recorder
+// records nothing.
+type Recorder struct {
+	store Store
+	log   *zap.Logger
+	now   func() time.Time
+}
+
+// NewRecorder creates a Recorder writing to the store.
+func NewRecorder(store Store, log *zap.Logger) *Recorder {
+	return &Recorder{store: store, log: log, now: time.Now}
+}
+
+// Record appends an entry of the action to the audit log. The operation has already
+// happened, so a failing store is logged rather than reported to the caller.
+func (r *Recorder) Record(ctx context.Context, action, actor, alias, target string, before, after any) {
+	if r == nil {
+		return
+	}
+	entry := &entity.AuditEntry{
+		ID:        generatestring.GenerateUUID(),
+		Action:    action,
+		Alias:     alias,
+		Target:    target,
+		Actor:     actor,
+		Transport: entity.TransportInternal,
+		Before:    Snapshot(before),
+		After:     Snapshot(after),
+		At:        r.now(),
+	}
+	if src, ok := FromContext(ctx); ok {
+		entry.Transport = src.Transport
+		entry.RequestID = src.RequestID
+		entry.ClientIP = src.ClientIP
+	}
+	if err := r.store.PutAudit(context.WithoutCancel(ctx), entry); err != nil {
+		r.log.Error("failed to record audit entry", zap.String("action", action), zap.String("actor", actor), zap.Error(err))
+	}
+}
+
+// Enabled reports whether the recorder records, so that callers can skip reading the
+// state before an operation.
+func (r *Recorder) Enabled() bool {
+	return r != nil
+}
+
+// Snapshot encodes the state of an object as JSON. Nil values give no snapshot.
+func Snapshot(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// NormalizeFilter limits the page size of a filter to DefaultLimit when unset and to
+// MaxLimit, and checks its time range.
+func NormalizeFilter(filter entity.AuditFilter) (entity.AuditFilter, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return filter, ErrInvalidRange
+	}
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = DefaultLimit
+	case filter.Limit > MaxLimit:
+		filter.Limit = MaxLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return filter, nil
+}
+
+// Match reports whether the entry matches the filter, for stores that filter in memory.
+func Match(entry *entity.AuditEntry, filter entity.AuditFilter) bool {
+	return (filter.Alias == "" || entry.Alias == filter.Alias) &&
+		(filter.Actor == "" || entry.Actor == filter.Actor) &&
+		(filter.From.IsZero() || !entry.At.Before(filter.From)) &&
+		(filter.To.IsZero() || entry.At.Before(filter.To))
+}
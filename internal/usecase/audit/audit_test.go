@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// memoryStore keeps the entries of a test in a slice.
+type memoryStore struct {
+	entries []*entity.AuditEntry
+	err     error
+}
+
+func (s *memoryStore) PutAudit(_ context.Context, entry *entity.AuditEntry) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryStore) GetAudit(context.Context, entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	return s.entries, nil
+}
+
+func TestRecorder(t *testing.T) {
+	store := &memoryStore{}
+	r := NewRecorder(store, zap.NewNop())
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return at }
+
+	ctx := NewContext(context.Background(), Source{Transport: entity.TransportHTTP, RequestID: "req-1", ClientIP: "192.0.2.1"})
+	before := &entity.URL{Alias: "abc", URL: "http://example.com"}
+	r.Record(ctx, ActionLinkDisable, "admin", "abc", "", before, &entity.URL{Alias: "abc", URL: "http://example.com", Disabled: true})
+	r.Record(context.Background(), ActionQuotaDelete, "", "", "user", &entity.Quota{MaxLinks: 5}, (*entity.Quota)(nil))
+
+	require.Len(t, store.entries, 2)
+	entry := store.entries[0]
+	assert.NotEmpty(t, entry.ID)
+	assert.Equal(t, ActionLinkDisable, entry.Action)
+	assert.Equal(t, "admin", entry.Actor)
+	assert.Equal(t, entity.TransportHTTP, entry.Transport)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Equal(t, "192.0.2.1", entry.ClientIP)
+	assert.JSONEq(t, `{"short_url":"abc","original_url":"http://example.com"}`, string(entry.Before))
+	assert.JSONEq(t, `{"short_url":"abc","original_url":"http://example.com","disabled":true}`, string(entry.After))
+	assert.Equal(t, at, entry.At)
+
+	removal := store.entries[1]
+	assert.Equal(t, entity.TransportInternal, removal.Transport)
+	assert.Nil(t, removal.After, "a nil pointer gives no snapshot")
+
+	store.err = errors.New("disk full")
+	r.Record(ctx, ActionLinkCreate, "user", "xyz", "", nil, nil)
+	assert.Len(t, store.entries, 2, "a failing store is logged")
+
+	var disabled *Recorder
+	assert.False(t, disabled.Enabled())
+	disabled.Record(ctx, ActionLinkCreate, "user", "xyz", "", nil, nil)
+}
+
+func TestNormalizeFilter(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	filter, err := NormalizeFilter(entity.AuditFilter{Limit: 5000, Offset: -1})
+	require.NoError(t, err)
+	assert.Equal(t, MaxLimit, filter.Limit)
+	assert.Zero(t, filter.Offset)
+
+	filter, err = NormalizeFilter(entity.AuditFilter{From: from})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLimit, filter.Limit)
+
+	_, err = NormalizeFilter(entity.AuditFilter{From: from, To: from.Add(-time.Hour)})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestMatch(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := &entity.AuditEntry{Alias: "abc", Actor: "admin", At: at}
+
+	tests := []struct {
+		name   string
+		filter entity.AuditFilter
+		want   bool
+	}{
+		{name: "Empty filter", want: true},
+		{name: "Alias", filter: entity.AuditFilter{Alias: "abc"}, want: true},
+		{name: "Other alias", filter: entity.AuditFilter{Alias: "xyz"}},
+		{name: "Other actor", filter: entity.AuditFilter{Actor: "user"}},
+		{name: "In range", filter: entity.AuditFilter{From: at, To: at.Add(time.Second)}, want: true},
+		{name: "Range ends at entry", filter: entity.AuditFilter{To: at}},
+		{name: "Range starts after entry", filter: entity.AuditFilter{From: at.Add(time.Second)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(entry, tt.filter))
+		})
+	}
+}
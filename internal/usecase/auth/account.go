@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
+)
+
+// Errors returned for accounts.
+var (
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrUsernameTaken       = errors.New("username is already taken")
+	ErrInvalidUsername     = errors.New("username must be 3 to 32 characters of a-z, 0-9, '.', '_' or '-'")
+	ErrInvalidPassword     = errors.New("password must be 8 to 72 bytes long")
+	ErrInvalidCredentials  = errors.New("invalid username or password")
+	errNotAnonymousSession = errors.New("session belongs to an account")
+)
+
+// Password length limits; bcrypt ignores everything after 72 bytes.
+const (
+	minPasswordLen = 8
+	maxPasswordLen = 72
+)
+
+// usernamePattern matches valid, already lowercased usernames.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,32}$`)
+
+// AccountStore keeps registered accounts.
+type AccountStore interface {
+	// PutAccount stores a new account. It returns ErrUsernameTaken if the username exists.
+	PutAccount(ctx context.Context, account *entity.Account) error
+	// GetAccount retrieves an account by its username.
+	GetAccount(ctx context.Context, username string) (*entity.Account, error)
+	// GetAccountByUserID retrieves the account owning a user ID.
+	GetAccountByUserID(ctx context.Context, userID string) (*entity.Account, error)
+}
+
+// Register creates an account with a new user ID and logs it in.
+func (a *Auth) Register(ctx context.Context, username, password string) (*entity.Account, *Tokens, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !usernamePattern.MatchString(username) {
+		return nil, nil, ErrInvalidUsername
+	}
+	if len(password) < minPasswordLen || len(password) > maxPasswordLen {
+		return nil, nil, ErrInvalidPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), a.passwordCost)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	account := &entity.Account{
+		UserID:       userid.New(),
+		Username:     username,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}
+	if err = a.store.PutAccount(ctx, account); err != nil {
+		return nil, nil, err
+	}
+	tokens, err := a.openSession(ctx, account.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, tokens, nil
+}
+
+// Login verifies the password of an account and starts a session for it.
+// Unknown usernames take as long to reject as wrong passwords.
+func (a *Auth) Login(ctx context.Context, username, password string) (*entity.Account, *Tokens, error) {
+	account, err := a.store.GetAccount(ctx, strings.ToLower(strings.TrimSpace(username)))
+	if errors.Is(err, ErrAccountNotFound) {
+		_ = bcrypt.CompareHashAndPassword(a.dummyHash(), []byte(password))
+		return nil, nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	tokens, err := a.openSession(ctx, account.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, tokens, nil
+}
+
+// AnonymousUser returns the user and session of the request's cookie if the user has
+// no account, so that its links can be claimed by one. An expired access token is
+// accepted as long as its session is active.
+func (a *Auth) AnonymousUser(r *http.Request) (string, string, error) {
+	cookie, err := r.Cookie(AccessCookie)
+	if err != nil {
+		return "", "", errMissingSessionCookie
+	}
+	claims, err := a.parse(cookie.Value, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return "", "", err
+	}
+	if err = a.checkSession(r.Context(), claims.SessionID); err != nil {
+		return "", "", err
+	}
+	_, err = a.store.GetAccountByUserID(r.Context(), string(claims.UserID))
+	switch {
+	case err == nil:
+		return "", "", errNotAnonymousSession
+	case !errors.Is(err, ErrAccountNotFound):
+		return "", "", err
+	}
+	return string(claims.UserID), claims.SessionID, nil
+}
+
+// dummyHash returns a hash to compare passwords of unknown usernames against.
+func (a *Auth) dummyHash() []byte {
+	a.dummyOnce.Do(func() {
+		a.dummy, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), a.passwordCost)
+	})
+	return a.dummy
+}
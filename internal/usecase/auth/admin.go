@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+)
+
+// Errors returned for roles and bans.
+var (
+	ErrRoleDenied  = errors.New("user lacks the required role")
+	ErrUserBanned  = errors.New("user is banned")
+	ErrBanNotFound = errors.New("ban not found")
+)
+
+// BanStore keeps the bans of users.
+type BanStore interface {
+	// PutBan stores the ban of a user, replacing an earlier one.
+	PutBan(ctx context.Context, ban *entity.Ban) error
+	// DelBan lifts the ban of a user. It returns ErrBanNotFound if the user is not banned.
+	DelBan(ctx context.Context, userID string) error
+	// GetBan retrieves the ban of a user. It returns ErrBanNotFound if the user is not banned.
+	GetBan(ctx context.Context, userID string) (*entity.Ban, error)
+}
+
+// Role returns the role of the user. Admins are configured by user ID only, because
+// anyone may register an account with a configured username.
+func (a *Auth) Role(_ context.Context, userID string) (string, error) {
+	if a.admins[userID] {
+		return entity.RoleAdmin, nil
+	}
+	return entity.RoleUser, nil
+}
+
+// Ban bans the user on behalf of the admin. Banned users keep their links but can
+// neither log in nor use their sessions and API keys.
+func (a *Auth) Ban(ctx context.Context, userID, admin, reason string) (*entity.Ban, error) {
+	var before *entity.Ban
+	if a.audit.Enabled() {
+		before, _ = a.store.GetBan(ctx, userID)
+	}
+	ban := &entity.Ban{UserID: userID, Reason: reason, BannedBy: admin, CreatedAt: time.Now()}
+	if err := a.store.PutBan(ctx, ban); err != nil {
+		return nil, err
+	}
+	a.audit.Record(ctx, audit.ActionUserBan, admin, "", userID, before, ban)
+	return ban, nil
+}
+
+// Unban lifts the ban of the user on behalf of the admin in the context.
+func (a *Auth) Unban(ctx context.Context, userID string) error {
+	var before *entity.Ban
+	if a.audit.Enabled() {
+		before, _ = a.store.GetBan(ctx, userID)
+	}
+	if err := a.store.DelBan(ctx, userID); err != nil {
+		return err
+	}
+	admin, _ := FromContext(ctx)
+	a.audit.Record(ctx, audit.ActionUserUnban, admin, "", userID, before, nil)
+	return nil
+}
+
+// Banned reports whether the user is banned.
+func (a *Auth) Banned(ctx context.Context, userID string) (bool, error) {
+	_, err := a.store.GetBan(ctx, userID)
+	if errors.Is(err, ErrBanNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkUser returns ErrUserBanned for banned users and ErrRoleDenied for the admin
+// scopes of users without the admin role.
+func (a *Auth) checkUser(ctx context.Context, userID, scope string) error {
+	if err := a.checkBan(ctx, userID); err != nil {
+		return err
+	}
+	return a.checkRole(ctx, userID, scope)
+}
+
+// checkBan returns ErrUserBanned if the user is banned.
+func (a *Auth) checkBan(ctx context.Context, userID string) error {
+	banned, err := a.Banned(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrUserBanned
+	}
+	return nil
+}
+
+// checkRole returns ErrRoleDenied if the scope is one of the admin scopes and the user
+// is no admin.
+func (a *Auth) checkRole(ctx context.Context, userID, scope string) error {
+	if !adminScopes[scope] {
+		return nil
+	}
+	role, err := a.Role(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if role != entity.RoleAdmin {
+		return ErrRoleDenied
+	}
+	return nil
+}
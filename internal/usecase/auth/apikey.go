@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+)
+
+// Errors returned for API keys and Bearer tokens.
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidAPIKey  = errors.New("API key is unknown or revoked")
+	ErrScopeDenied    = errors.New("token lacks the required scope")
+	ErrUnknownScope   = errors.New("unknown scope")
+	ErrNoScopes       = errors.New("API key needs at least one scope")
+	ErrInvalidToken   = errors.New("access token is not valid")
+)
+
+const (
+	apiKeyPrefix = "sk_" // apiKeyPrefix starts every API key, so leaked keys are easy to spot
+	prefixLen    = 11    // prefixLen is the length of the stored, displayable key prefix
+
+	// lastUsedResolution limits how often the last-used time of a key is written.
+	lastUsedResolution = time.Minute
+)
+
+// scopes contains the known scopes.
+var scopes = map[string]bool{
+	entity.ScopeLinksRead:  true,
+	entity.ScopeLinksWrite: true,
+	entity.ScopeStatsRead:  true,
+	entity.ScopeAdmin:      true,
+}
+
+// adminScopes contains the scopes limited to users with the admin role. The service
+// statistics are one of them, as keys with the stats:read scope are not bound to the
+// trusted subnets.
+var adminScopes = map[string]bool{
+	entity.ScopeStatsRead: true,
+	entity.ScopeAdmin:     true,
+}
+
+// sessionScopes contains the scopes of access tokens: users manage their own links,
+// but the service statistics need an API key. The admin scope is further limited to
+// users with the admin role.
+var sessionScopes = map[string]bool{
+	entity.ScopeLinksRead:  true,
+	entity.ScopeLinksWrite: true,
+	entity.ScopeAdmin:      true,
+}
+
+// APIKeyStore keeps the API keys of users by the hashes of their secrets.
+type APIKeyStore interface {
+	// PutAPIKey stores a new API key.
+	PutAPIKey(ctx context.Context, key *entity.APIKey) error
+	// GetAPIKeys retrieves the API keys of a user, including revoked ones.
+	GetAPIKeys(ctx context.Context, userID string) ([]*entity.APIKey, error)
+	// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+	GetAPIKeyByHash(ctx context.Context, hash string) (*entity.APIKey, error)
+	// RevokeAPIKey marks an API key of the user as revoked.
+	RevokeAPIKey(ctx context.Context, userID, id string, at time.Time) error
+	// TouchAPIKey sets the last-used time of an API key.
+	TouchAPIKey(ctx context.Context, id string, at time.Time) error
+}
+
+// Store keeps everything Auth needs to persist.
+type Store interface {
+	SessionStore
+	APIKeyStore
+	AccountStore
+	IdentityStore
+	BanStore
+}
+
+// CreateAPIKey creates an API key of the user with the scopes and an optional quota,
+// which narrows the quota of the user for requests made with the key. The returned
+// key carries its secret, which cannot be retrieved later. Only admins get keys with
+// the admin or stats:read scope.
+func (a *Auth) CreateAPIKey(ctx context.Context, userID, name string, keyScopes []string, keyQuota *entity.Quota) (*entity.APIKey, error) {
+	if len(keyScopes) == 0 {
+		return nil, ErrNoScopes
+	}
+	if keyQuota != nil {
+		if err := quota.Validate(*keyQuota); err != nil {
+			return nil, err
+		}
+	}
+	for _, scope := range keyScopes {
+		if !scopes[scope] {
+			return nil, fmt.Errorf("%w %q", ErrUnknownScope, scope)
+		}
+		if err := a.checkRole(ctx, userID, scope); err != nil {
+			return nil, err
+		}
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(b)
+	key := &entity.APIKey{
+		ID:        generatestring.GenerateUUID(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:prefixLen],
+		Hash:      hashToken(secret),
+		Scopes:    keyScopes,
+		CreatedAt: time.Now(),
+		Quota:     keyQuota,
+	}
+	if err := a.store.PutAPIKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+	key.Key = secret
+	return key, nil
+}
+
+// APIKeys returns the API keys of the user.
+func (a *Auth) APIKeys(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	return a.store.GetAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey revokes an API key of the user.
+func (a *Auth) RevokeAPIKey(ctx context.Context, userID, id string) error {
+	return a.store.RevokeAPIKey(ctx, userID, id, time.Now())
+}
+
+// CheckAPIKey verifies an API key and its scope and returns the user the key acts for.
+func (a *Auth) CheckAPIKey(ctx context.Context, secret, scope string) (string, error) {
+	key, err := a.checkAPIKey(ctx, secret, scope)
+	if err != nil {
+		return "", err
+	}
+	return key.UserID, nil
+}
+
+// checkAPIKey verifies an API key and its scope and returns the key.
+func (a *Auth) checkAPIKey(ctx context.Context, secret, scope string) (*entity.APIKey, error) {
+	key, err := a.store.GetAPIKeyByHash(ctx, hashToken(secret))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if !key.HasScope(scope) {
+		return nil, ErrScopeDenied
+	}
+	if err = a.checkUser(ctx, key.UserID, scope); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err = a.store.TouchAPIKey(ctx, key.ID, now); err != nil {
+			a.log.Error("failed to update last use of API key", zap.String("key", key.ID), zap.Error(err))
+		}
+	}
+	return key, nil
+}
+
+// CheckToken verifies a Bearer token, which is either an API key or the access token
+// of a session, and returns the user it acts for. Tokens of banned users are rejected
+// with ErrUserBanned, and the admin scopes need the admin role.
+func (a *Auth) CheckToken(ctx context.Context, token, scope string) (string, error) {
+	userID, _, err := a.CheckTokenKey(ctx, token, scope)
+	return userID, err
+}
+
+// CheckTokenKey verifies a Bearer token as CheckToken does, and also returns the API key
+// if the token is one.
+func (a *Auth) CheckTokenKey(ctx context.Context, token, scope string) (string, *entity.APIKey, error) {
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		key, err := a.checkAPIKey(ctx, token, scope)
+		if err != nil {
+			return "", nil, err
+		}
+		return key.UserID, key, nil
+	}
+	claims, err := a.parse(token)
+	if err != nil || claims.SessionID == "" {
+		a.log.Debug("bearer access token is not valid", zap.Error(err))
+		return "", nil, ErrInvalidToken
+	}
+	if err = a.checkSession(ctx, claims.SessionID); err != nil {
+		return "", nil, err
+	}
+	if !sessionScopes[scope] {
+		return "", nil, ErrScopeDenied
+	}
+	if err = a.checkUser(ctx, string(claims.UserID), scope); err != nil {
+		return "", nil, err
+	}
+	return string(claims.UserID), nil, nil
+}
+
+// Authenticate identifies the user of the request. A request with an Authorization
+// header must carry a Bearer token valid for the scope as in CheckToken; other
+// requests are identified by their cookies as in CheckCookie.
+func (a *Auth) Authenticate(w http.ResponseWriter, r *http.Request, scope string) (string, error) {
+	userID, _, err := a.AuthenticateKey(w, r, scope)
+	return userID, err
+}
+
+// AuthenticateKey identifies the user of the request as Authenticate does, and also
+// returns the API key the request was made with, if any.
+func (a *Auth) AuthenticateKey(w http.ResponseWriter, r *http.Request, scope string) (string, *entity.APIKey, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		id, err := a.CheckCookie(w, r)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = a.checkRole(r.Context(), id, scope); err != nil {
+			return "", nil, err
+		}
+		return id, nil, nil
+	}
+	secret, ok := ParseBearer(header)
+	if !ok {
+		return "", nil, ErrInvalidAPIKey
+	}
+	return a.CheckTokenKey(r.Context(), secret, scope)
+}
+
+// ParseBearer extracts the token of an Authorization header value of the Bearer scheme.
+func ParseBearer(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
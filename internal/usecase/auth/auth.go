@@ -1,111 +1,422 @@
 // Package auth provides authentication utilities, including JWT generation and validation,
 // and functions to manage user ID cookies in HTTP requests.
+//
+// The UserID cookie holds a short-lived access token of a session. When it expires,
+// the refresh token in the RefreshToken cookie is exchanged for a new pair of tokens.
+// Every refresh token can be used once; using it again revokes the whole session.
+//
+// Registered accounts log in with a username and password and may claim the links
+// of the anonymous user of the browser. Clients without cookies send the access token
+// or an API key limited to scopes as a Bearer token.
+//
+// Configured users have the admin role, which the admin scope requires. Banned users
+// are rejected whichever way they authenticate.
 package auth
 
 import (
-	"crypto/rand"
-	"encoding/binary"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
+)
+
+// Cookie names.
+const (
+	AccessCookie  = "UserID"       // AccessCookie holds the access token
+	RefreshCookie = "RefreshToken" // RefreshCookie holds the refresh token
 )
 
-// tokenKey is the key used for signing JWT tokens.
-const tokenKey = "nextbug"
+// Default token lifetimes.
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// refreshReuseGrace is how long a used refresh token still renews its session, so that
+// parallel requests carrying the same cookie do not count as reuse.
+const refreshReuseGrace = 10 * time.Second
 
 // Claims represents the structure of JWT claims.
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID int `json:"user_id"`
+	UserID    userid.Compat `json:"user_id"`
+	SessionID string        `json:"sid,omitempty"`
+}
+
+// Auth issues and verifies the user tokens.
+type Auth struct {
+	keys       *KeySet
+	store      Store
+	log        *zap.Logger
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+
+	passwordCost int
+	dummy        []byte
+	dummyOnce    sync.Once
+
+	admins map[string]bool // user IDs with the admin role
+	audit  *audit.Recorder // recorder of the audit log of bans, may be nil
 }
 
-// cryptoRandomID generates a cryptographically secure random integer ID up to the specified limit.
-func cryptoRandomID(limit int) int {
-	b := make([]byte, 8)
-	_, err := rand.Read(b)
+// Option configures an Auth.
+type Option func(*Auth)
+
+// WithAudit sets the recorder of the audit log of bans.
+func WithAudit(r *audit.Recorder) Option {
+	return func(a *Auth) {
+		a.audit = r
+	}
+}
+
+// WithAdmins grants the admin role to the user IDs.
+func WithAdmins(users ...string) Option {
+	return func(a *Auth) {
+		for _, user := range users {
+			a.admins[user] = true
+		}
+	}
+}
+
+// New creates a new Auth with the keys from the configuration. Without configured keys
+// a random key is used, so the issued tokens only live as long as the process.
+func New(cfg configuration.JWT, store Store, log *zap.Logger, opts ...Option) (*Auth, error) {
+	var keys *KeySet
+	var err error
+	if len(cfg.Keys) == 0 && cfg.KeyFile == "" {
+		log.Warn("no JWT keys configured, using an ephemeral key")
+		keys, err = EphemeralKeySet()
+	} else {
+		keys, err = LoadKeySet(cfg)
+	}
 	if err != nil {
-		return -1
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	a := NewWithKeys(keys, store, log)
+	if cfg.AccessTTL > 0 {
+		a.accessTTL = time.Duration(cfg.AccessTTL)
+	}
+	if cfg.RefreshTTL > 0 {
+		a.refreshTTL = time.Duration(cfg.RefreshTTL)
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
-	return int(binary.BigEndian.Uint64(b) % uint64(limit))
+	return a, nil
 }
 
-// buildJWTString creates a new JWT string with a random user ID.
-func buildJWTString() (string, error) {
-	id := cryptoRandomID(1000)
+// NewWithKeys creates a new Auth with the given key set and the default token lifetimes.
+func NewWithKeys(keys *KeySet, store Store, log *zap.Logger) *Auth {
+	return &Auth{
+		keys:       keys,
+		store:      store,
+		log:        log,
+		accessTTL:  defaultAccessTTL,
+		refreshTTL: defaultRefreshTTL,
+
+		passwordCost: bcrypt.DefaultCost,
+		admins:       make(map[string]bool),
+	}
+}
+
+// buildJWTString creates a new access token of the session, signed with the
+// current signing key and naming it in the kid header.
+func (a *Auth) buildJWTString(session *entity.Session) (string, error) {
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			// Token storage duration
-			// ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenExp)),
-
-			// Token stored indefinitely
-			ExpiresAt: nil,
+			ID:        generatestring.GenerateUUID(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.accessTTL)),
 		},
-		UserID: id,
+		UserID:    userid.Compat(session.UserID),
+		SessionID: session.ID,
 	})
-	tokenString, err := token.SignedString([]byte(tokenKey))
+	key := a.keys.SigningKey()
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString([]byte(key.Secret))
 	if err != nil {
 		return "", err
 	}
 	return tokenString, nil
 }
 
-// getUserID parses the JWT token string and retrieves the user ID from its claims.
-func getUserID(tokenString string, log *zap.Logger) (int, error) {
+// parse verifies the token string and returns its claims.
+func (a *Auth) parse(tokenString string, opts ...jwt.ParserOption) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+	token, err := jwt.NewParser(opts...).ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			log.Error("unexpected signing method")
-			return nil, nil
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
 		}
-		return []byte(tokenKey), nil
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.VerificationKey(kid)
 	})
-	if err != nil || !token.Valid {
-		log.Error("Token is not valid", zap.Error(err))
-		return 0, err
+	if err != nil {
+		return nil, err
 	}
-	return claims.UserID, nil
+	if !token.Valid {
+		return nil, fmt.Errorf("token is not valid")
+	}
+	if claims.UserID == "" {
+		return nil, fmt.Errorf("token has no user ID")
+	}
+	return claims, nil
 }
 
-// CheckCookie checks for a user ID cookie in the request. If not found, it generates a new one.
-// It returns the user ID and any error encountered.
-func CheckCookie(w http.ResponseWriter, r *http.Request, log *zap.Logger) (int, error) {
-	var id int
-	uuid, err := r.Cookie("UserID")
+// CheckCookie checks for a user ID cookie in the request. An expired access token is
+// renewed with the refresh token, and a new user is created if the request has neither.
+// A rejected refresh token clears the cookies and fails the request.
+// It returns the user ID and any error encountered; banned users get ErrUserBanned.
+func (a *Auth) CheckCookie(w http.ResponseWriter, r *http.Request) (string, error) {
+	id, err := a.identify(w, r)
 	if err != nil {
-		if r.URL.Path == "/api/user/urls" {
-			// Return a user-friendly error if the cookie is missing.
-			return 0, fmt.Errorf("UserID cookie is missing")
+		return "", err
+	}
+	if err = a.checkBan(r.Context(), id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// identify returns the user of the cookies of the request as described in CheckCookie.
+func (a *Auth) identify(w http.ResponseWriter, r *http.Request) (string, error) {
+	ctx := r.Context()
+	if cookie, err := r.Cookie(AccessCookie); err == nil {
+		claims, err := a.parse(cookie.Value)
+		switch {
+		case err != nil:
+			a.log.Debug("access token is not valid", zap.Error(err))
+		case claims.SessionID == "":
+			// Tokens issued before sessions existed never expire; move their user into a
+			// session. Retiring the signing key of these tokens ends the upgrade path.
+			a.log.Info("upgrading token without session", zap.String("UserID", string(claims.UserID)))
+			return a.startSession(ctx, w, string(claims.UserID))
+		default:
+			if err = a.checkSession(ctx, claims.SessionID); err == nil {
+				a.log.Info("UserID retrieved from cookie", zap.String("UserID", string(claims.UserID)))
+				return string(claims.UserID), nil
+			}
+			a.log.Info("session of access token rejected", zap.String("session", claims.SessionID), zap.Error(err))
 		}
+	}
 
-		var jwt string
-		jwt, err = buildJWTString()
+	if cookie, err := r.Cookie(RefreshCookie); err == nil {
+		id, err := a.refresh(ctx, w, cookie.Value)
 		if err != nil {
-			log.Error("error making cookie", zap.Error(err))
-			return 0, err
+			// The cookies belong to a user; never replace them with a new identity.
+			a.log.Info("refresh token rejected", zap.Error(err))
+			a.clearCookies(w)
+			return "", err
 		}
+		return id, nil
+	}
+
+	if r.URL.Path == "/api/user/urls" {
+		// Return a user-friendly error if the cookie is missing.
+		return "", fmt.Errorf("UserID cookie is missing")
+	}
+
+	id, err := a.startSession(ctx, w, userid.New())
+	if err != nil {
+		a.log.Error("error making cookie", zap.Error(err))
+		return "", err
+	}
+	a.log.Info("Generated UserID", zap.String("UserID", id))
+	return id, nil
+}
+
+// RequestUser returns the user of a valid access token in the Authorization header or the
+// cookie of the request. It does not look up the session, so it is cheap enough to key
+// rate limits, but must not be used to authorize requests.
+func (a *Auth) RequestUser(r *http.Request) (string, bool) {
+	var token string
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, _ = ParseBearer(header)
+	} else if cookie, err := r.Cookie(AccessCookie); err == nil {
+		token = cookie.Value
+	}
+	if token == "" || strings.HasPrefix(token, apiKeyPrefix) {
+		return "", false
+	}
+	claims, err := a.parse(token)
+	if err != nil || claims.UserID == "" {
+		return "", false
+	}
+	return string(claims.UserID), true
+}
+
+// SessionID returns the session of the access token in the request, even if the token expired.
+func (a *Auth) SessionID(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(AccessCookie)
+	if err != nil {
+		return "", errMissingSessionCookie
+	}
+	claims, err := a.parse(cookie.Value, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return "", err
+	}
+	return claims.SessionID, nil
+}
 
-		cookie := http.Cookie{
-			Name:  "UserID",
-			Value: jwt,
-			Path:  "/",
+// Logout revokes the session of the request and clears the cookies.
+func (a *Auth) Logout(w http.ResponseWriter, r *http.Request) error {
+	sessionID, err := a.SessionID(r)
+	if err != nil {
+		return err
+	}
+	if sessionID != "" {
+		if err = a.store.RevokeSession(r.Context(), sessionID, time.Now()); err != nil {
+			return err
 		}
+	}
+	a.clearCookies(w)
+	return nil
+}
 
-		// Set the cookie, returning an error if unsuccessful.
-		http.SetCookie(w, &cookie)
-		id, err = getUserID(jwt, log)
-		if err != nil {
-			log.Error("error creating UserID cookie", zap.Error(err))
+// clearCookies removes the token cookies from the client.
+func (a *Auth) clearCookies(w http.ResponseWriter) {
+	for _, name := range []string{AccessCookie, RefreshCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Path: "/", MaxAge: -1, HttpOnly: true})
+	}
+}
+
+// checkSession returns an error unless the session exists and is active.
+func (a *Auth) checkSession(ctx context.Context, sessionID string) error {
+	session, err := a.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if !session.Active(time.Now()) {
+		return ErrSessionNotActive
+	}
+	return nil
+}
+
+// Tokens are the tokens of a session, for clients that do not keep cookies.
+type Tokens struct {
+	AccessToken      string    // AccessToken is the short-lived access token
+	RefreshToken     string    // RefreshToken renews the access token once
+	RefreshExpiresAt time.Time // RefreshExpiresAt is the expiry of the refresh token
+}
+
+// startSession creates a session for the user and sets its cookies.
+func (a *Auth) startSession(ctx context.Context, w http.ResponseWriter, userID string) (string, error) {
+	tokens, err := a.openSession(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	a.SetCookies(w, tokens)
+	return userID, nil
+}
+
+// openSession creates a session for the user and returns its tokens. Banned users
+// get no session.
+func (a *Auth) openSession(ctx context.Context, userID string) (*Tokens, error) {
+	if err := a.checkBan(ctx, userID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &entity.Session{
+		ID:        generatestring.GenerateUUID(),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.refreshTTL),
+	}
+	if err := a.store.PutSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+	return a.issue(ctx, session)
+}
+
+// refresh exchanges a refresh token for new tokens of its session and sets their cookies.
+func (a *Auth) refresh(ctx context.Context, w http.ResponseWriter, token string) (string, error) {
+	userID, tokens, err := a.Refresh(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	a.SetCookies(w, tokens)
+	return userID, nil
+}
+
+// Refresh exchanges a refresh token for new tokens of its session and returns them
+// with the user of the session. Reuse of a refresh token after refreshReuseGrace revokes
+// the session, because either the legitimate client or an attacker holds a copy of it.
+func (a *Auth) Refresh(ctx context.Context, token string) (string, *Tokens, error) {
+	now := time.Now()
+	sessionID, err := a.store.UseRefreshToken(ctx, hashToken(token), now, refreshReuseGrace)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		a.log.Warn("refresh token reuse detected, revoking session", zap.String("session", sessionID))
+		if revokeErr := a.store.RevokeSession(ctx, sessionID, now); revokeErr != nil {
+			a.log.Error("failed to revoke session", zap.String("session", sessionID), zap.Error(revokeErr))
 		}
-		log.Info("Generated UserID and token", zap.Int("UserID", id), zap.String("token key", jwt))
-		return id, nil
+		return "", nil, err
+	}
+	if err != nil {
+		return "", nil, err
 	}
 
-	id, err = getUserID(uuid.Value, log)
+	session, err := a.store.GetSession(ctx, sessionID)
 	if err != nil {
-		log.Error("error retrieving UserID from cookie", zap.Error(err))
+		return "", nil, err
 	}
-	log.Info("UserID retrieved from cookie", zap.Int("UserID", id))
-	return id, nil
+	if !session.Active(now) {
+		return "", nil, ErrSessionNotActive
+	}
+	if err = a.checkBan(ctx, session.UserID); err != nil {
+		return "", nil, err
+	}
+	tokens, err := a.issue(ctx, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return session.UserID, tokens, nil
+}
+
+// issue creates a new access token and a new refresh token of the session.
+func (a *Auth) issue(ctx context.Context, session *entity.Session) (*Tokens, error) {
+	refresh, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(a.refreshTTL)
+	if err = a.store.PutRefreshToken(ctx, session.ID, hash, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	access, err := a.buildJWTString(session)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{AccessToken: access, RefreshToken: refresh, RefreshExpiresAt: expiresAt}, nil
+}
+
+// SetCookies sets the cookies of the tokens.
+func (a *Auth) SetCookies(w http.ResponseWriter, tokens *Tokens) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     AccessCookie,
+		Value:    tokens.AccessToken,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     RefreshCookie,
+		Value:    tokens.RefreshToken,
+		Path:     "/",
+		Expires:  tokens.RefreshExpiresAt,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
 }
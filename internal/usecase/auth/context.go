@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+// userKey is the context key of the verified user ID.
+type userKey struct{}
+
+// NewContext returns a copy of ctx carrying the verified user ID.
+func NewContext(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// FromContext returns the verified user ID stored in ctx by NewContext.
+func FromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userKey{}).(string)
+	return userID, ok && userID != ""
+}
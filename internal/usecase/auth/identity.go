@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
+)
+
+// Errors returned for identities.
+var (
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrIdentityExists   = errors.New("identity already exists")
+)
+
+// IdentityStore keeps the mapping of external identities to user IDs.
+type IdentityStore interface {
+	// PutIdentity stores a new identity. It returns ErrIdentityExists if the subject
+	// of the issuer is already mapped.
+	PutIdentity(ctx context.Context, identity *entity.Identity) error
+	// GetIdentity retrieves the identity of a subject of an issuer.
+	GetIdentity(ctx context.Context, issuer, subject string) (*entity.Identity, error)
+}
+
+// LoginIdentity starts a session for the user of an external identity. The first
+// login of a subject maps it to a new user ID.
+func (a *Auth) LoginIdentity(ctx context.Context, issuer, subject string) (*entity.Identity, *Tokens, error) {
+	identity, err := a.store.GetIdentity(ctx, issuer, subject)
+	if errors.Is(err, ErrIdentityNotFound) {
+		identity = &entity.Identity{Issuer: issuer, Subject: subject, UserID: userid.New(), CreatedAt: time.Now()}
+		err = a.store.PutIdentity(ctx, identity)
+		if errors.Is(err, ErrIdentityExists) {
+			// A concurrent first login of the subject won.
+			identity, err = a.store.GetIdentity(ctx, issuer, subject)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := a.openSession(ctx, identity.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity, tokens, nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+)
+
+// Errors returned when a token is verified.
+var (
+	ErrNoKeyID      = errors.New("token has no key id")
+	ErrUnknownKey   = errors.New("token is signed with an unknown key")
+	ErrRetiredKey   = errors.New("token is signed with a retired key")
+	ErrNoSigningKey = errors.New("no active signing key")
+)
+
+// minSecretLen is the minimum length of a signing secret in bytes.
+const minSecretLen = 16
+
+// Key is an HMAC key identified by the kid header of the tokens it signs.
+type Key struct {
+	ID      string `json:"kid"`               // ID is the key id written to the kid header
+	Secret  string `json:"secret,omitempty"`  // Secret is the HMAC secret
+	Retired bool   `json:"retired,omitempty"` // Retired keys no longer verify tokens
+}
+
+// keyFile is the format of the key file.
+type keyFile struct {
+	SigningKey string `json:"signing_key"`
+	Keys       []Key  `json:"keys"`
+}
+
+// KeySet holds the key that signs new tokens and all keys that verify them.
+type KeySet struct {
+	signing string
+	keys    map[string]Key
+}
+
+// NewKeySet creates a key set signing with the key signingID.
+// An empty signingID selects the first active key.
+func NewKeySet(signingID string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]Key, len(keys))}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("key without kid")
+		}
+		if !key.Retired && len(key.Secret) < minSecretLen {
+			return nil, fmt.Errorf("secret of key %q is shorter than %d bytes", key.ID, minSecretLen)
+		}
+		ks.keys[key.ID] = key
+		if signingID == "" && !key.Retired {
+			signingID = key.ID
+		}
+	}
+
+	if key, ok := ks.keys[signingID]; !ok || key.Retired {
+		return nil, ErrNoSigningKey
+	}
+	ks.signing = signingID
+	return ks, nil
+}
+
+// LoadKeySet builds the key set from the configuration and the key file it names.
+// Keys listed as retired in either place are rejected even if their secret is known.
+func LoadKeySet(cfg configuration.JWT) (*KeySet, error) {
+	var keys []Key
+	for i, pair := range cfg.Keys {
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("key #%d is not a kid:secret pair", i+1)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+
+	signing := cfg.SigningKey
+	if cfg.KeyFile != "" {
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		var file keyFile
+		if err = json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse key file: %w", err)
+		}
+		keys = append(keys, file.Keys...)
+		if signing == "" {
+			signing = file.SigningKey
+		}
+	}
+
+	for _, id := range cfg.RetiredKeys {
+		keys = append(keys, Key{ID: id, Retired: true})
+	}
+	return NewKeySet(signing, retire(keys)...)
+}
+
+// EphemeralKeySet creates a key set with a random key. Tokens signed with it
+// become invalid when the process exits.
+func EphemeralKeySet() (*KeySet, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return NewKeySet("", Key{ID: "ephemeral-" + hex.EncodeToString(b[:4]), Secret: hex.EncodeToString(b)})
+}
+
+// retire marks every key whose kid is retired anywhere in keys as retired.
+func retire(keys []Key) []Key {
+	retired := make(map[string]bool)
+	for _, key := range keys {
+		if key.Retired {
+			retired[key.ID] = true
+		}
+	}
+	for i := range keys {
+		if retired[keys[i].ID] {
+			keys[i].Retired = true
+		}
+	}
+	return keys
+}
+
+// SigningKey returns the key that signs new tokens.
+func (ks *KeySet) SigningKey() Key {
+	return ks.keys[ks.signing]
+}
+
+// VerificationKey returns the secret of the key kid if it may verify tokens.
+func (ks *KeySet) VerificationKey(kid string) ([]byte, error) {
+	if kid == "" {
+		return nil, ErrNoKeyID
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if key.Retired {
+		return nil, ErrRetiredKey
+	}
+	return []byte(key.Secret), nil
+}
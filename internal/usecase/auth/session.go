@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// Errors returned by session stores.
+var (
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrInvalidRefreshToken  = errors.New("refresh token is unknown or expired")
+	ErrRefreshTokenReused   = errors.New("refresh token was already used")
+	ErrSessionNotActive     = errors.New("session is revoked or expired")
+	errMissingSessionCookie = errors.New("no session cookie")
+)
+
+// SessionStore keeps sessions and the hashes of their refresh tokens.
+type SessionStore interface {
+	// PutSession stores a new session.
+	PutSession(ctx context.Context, session *entity.Session) error
+	// GetSession retrieves a session by its ID.
+	GetSession(ctx context.Context, id string) (*entity.Session, error)
+	// GetSessions retrieves the sessions of a user.
+	GetSessions(ctx context.Context, userID string) ([]*entity.Session, error)
+	// RevokeSession marks a session as revoked.
+	RevokeSession(ctx context.Context, id string, at time.Time) error
+	// PutRefreshToken adds a refresh token to a session and extends the session until expiresAt.
+	PutRefreshToken(ctx context.Context, sessionID, hash string, expiresAt time.Time) error
+	// UseRefreshToken atomically marks an unused, unexpired refresh token as used and returns
+	// its session. A token that was used less than grace before now returns its session as
+	// well, so that parallel requests racing on the rotation keep it. For a token that was
+	// used earlier it returns the session together with ErrRefreshTokenReused.
+	UseRefreshToken(ctx context.Context, hash string, now time.Time, grace time.Duration) (string, error)
+}
+
+// newRefreshToken returns a random refresh token and the hash under which it is stored.
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the hex encoded SHA-256 of a token. Refresh tokens and API keys
+// are random, so a fast hash is sufficient.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sessions returns the sessions of the user, marking the one with the ID current.
+func (a *Auth) Sessions(ctx context.Context, userID, current string) ([]*entity.Session, error) {
+	sessions, err := a.store.GetSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == current
+	}
+	return sessions, nil
+}
+
+// Revoke logs out a session of the user. Access tokens of the session are rejected
+// from now on and its refresh tokens can no longer be used.
+func (a *Auth) Revoke(ctx context.Context, userID, sessionID string) error {
+	session, err := a.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return a.store.RevokeSession(ctx, sessionID, time.Now())
+}
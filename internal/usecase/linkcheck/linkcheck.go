@@ -0,0 +1,233 @@
+// Package linkcheck provides a background scheduler that periodically checks
+// whether the targets of short links are still reachable.
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/pkg/tools/safehttp"
+)
+
+// userAgent identifies the checker to the target servers.
+const userAgent = "shortenerURL-linkcheck/1.0"
+
+// Store is the part of the repository used by the checker.
+type Store interface {
+	GetActive(ctx context.Context) ([]*entity.URL, error)
+	SetStatus(ctx context.Context, alias string, status int, checkedAt time.Time) error
+}
+
+// Checker sends HEAD (falling back to GET) requests to link targets and records
+// the resulting status code and check time in the store. Targets that are not
+// publicly routable are never contacted and recorded as unreachable.
+type Checker struct {
+	store        Store
+	client       *http.Client
+	log          *zap.Logger
+	interval     time.Duration
+	concurrency  int
+	hosts        int
+	hostInterval time.Duration
+
+	mu       sync.Mutex
+	nextSlot map[string]time.Time // nextSlot holds the earliest time of the next request per host
+}
+
+// New creates a new Checker from the link check configuration.
+func New(store Store, cfg configuration.LinkCheck, log *zap.Logger) *Checker {
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	hosts := cfg.Hosts
+	if hosts < 1 {
+		hosts = 1
+	}
+	return &Checker{
+		store:        store,
+		client:       safehttp.NewClient(time.Duration(cfg.Timeout)),
+		log:          log,
+		interval:     time.Duration(cfg.Interval),
+		concurrency:  concurrency,
+		hosts:        hosts,
+		hostInterval: time.Duration(cfg.HostInterval),
+		nextSlot:     make(map[string]time.Time),
+	}
+}
+
+// Run checks all links every interval until the context is canceled.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.CheckAll(ctx); err != nil {
+			c.log.Error("link check failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll performs a single pass over all active links. The links of each host are
+// checked in turn by one of a bounded number of host workers, so that a host waiting for
+// its rate limit does not take one of the concurrency slots, which are only held while
+// a request is in flight.
+func (c *Checker) CheckAll(ctx context.Context) error {
+	links, err := c.store.GetActive(ctx)
+	if err != nil {
+		return err
+	}
+	c.forgetIdleHosts()
+
+	byHost := make(map[string][]*entity.URL)
+	for _, link := range links {
+		u, err := url.Parse(link.URL)
+		if err != nil || u.Host == "" {
+			c.record(ctx, link, 0)
+			continue
+		}
+		byHost[u.Host] = append(byHost[u.Host], link)
+	}
+
+	hosts := make(chan string, len(byHost))
+	for host := range byHost {
+		hosts <- host
+	}
+	close(hosts)
+
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for range min(c.hosts, len(byHost)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				for _, link := range byHost[host] {
+					if ctx.Err() != nil {
+						return
+					}
+					c.record(ctx, link, c.check(ctx, sem, host, link.URL))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// record stores the status of a link, unless the pass was canceled.
+func (c *Checker) record(ctx context.Context, link *entity.URL, status int) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := c.store.SetStatus(ctx, link.Alias, status, time.Now()); err != nil {
+		c.log.Error("failed to record link status", zap.String("alias", link.Alias), zap.Error(err))
+	}
+}
+
+// Check returns the HTTP status code of target, or 0 if it could not be reached.
+func (c *Checker) Check(ctx context.Context, target string) int {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return 0
+	}
+	return c.check(ctx, nil, u.Host, target)
+}
+
+// check returns the HTTP status code of target on host, or 0 if it could not be reached.
+// Requests take a slot of sem, if given, while they are in flight.
+func (c *Checker) check(ctx context.Context, sem chan struct{}, host, target string) int {
+	status, err := c.request(ctx, sem, host, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		// Some servers do not support HEAD, ask for the body instead.
+		status, err = c.request(ctx, sem, host, http.MethodGet, target)
+	}
+	if err != nil {
+		c.log.Debug("link target is unreachable", zap.String("url", target), zap.Error(err))
+		return 0
+	}
+	return status
+}
+
+// request waits for the rate limit of the host, then for a slot of sem, if given, and
+// sends the request.
+func (c *Checker) request(ctx context.Context, sem chan struct{}, host, method, target string) (int, error) {
+	if err := c.wait(ctx, host); err != nil {
+		return 0, err
+	}
+	if sem != nil {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case sem <- struct{}{}:
+		}
+		defer func() { <-sem }()
+	}
+	return c.do(ctx, method, target)
+}
+
+// do sends a single request and returns the response status code.
+func (c *Checker) do(ctx context.Context, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// forgetIdleHosts drops the rate limit state of hosts that have no pending slot.
+func (c *Checker) forgetIdleHosts() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for host, slot := range c.nextSlot {
+		if slot.Before(now) {
+			delete(c.nextSlot, host)
+		}
+	}
+}
+
+// wait blocks until a request to host is allowed by the per-host rate limit.
+func (c *Checker) wait(ctx context.Context, host string) error {
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.nextSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.nextSlot[host] = slot.Add(c.hostInterval)
+	c.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
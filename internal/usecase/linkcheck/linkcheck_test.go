@@ -0,0 +1,203 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+type fakeStore struct {
+	mu     sync.Mutex
+	links  []*entity.URL
+	status map[string]int
+}
+
+func (s *fakeStore) GetActive(_ context.Context) ([]*entity.URL, error) {
+	return s.links, nil
+}
+
+func (s *fakeStore) SetStatus(_ context.Context, alias string, status int, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[alias] = status
+	return nil
+}
+
+func TestCheckAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	store := &fakeStore{
+		links: []*entity.URL{
+			{Alias: "ok", URL: srv.URL + "/ok"},
+			{Alias: "gone", URL: srv.URL + "/gone"},
+			{Alias: "no-head", URL: srv.URL + "/no-head"},
+			{Alias: "down", URL: unreachable.URL},
+		},
+		status: make(map[string]int),
+	}
+
+	checker := New(store, configuration.LinkCheck{
+		Timeout:     configuration.Duration(time.Second),
+		Concurrency: 2,
+	}, zap.NewNop())
+	// The test servers listen on loopback addresses, which the default client refuses.
+	checker.client = &http.Client{Timeout: time.Second}
+
+	require.NoError(t, checker.CheckAll(context.Background()))
+
+	assert.Equal(t, map[string]int{
+		"ok":      http.StatusOK,
+		"gone":    http.StatusNotFound,
+		"no-head": http.StatusOK,
+		"down":    0,
+	}, store.status)
+}
+
+func TestHostRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var hits []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		hits = append(hits, time.Now())
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{
+		links: []*entity.URL{
+			{Alias: "a", URL: srv.URL + "/a"},
+			{Alias: "b", URL: srv.URL + "/b"},
+			{Alias: "c", URL: srv.URL + "/c"},
+		},
+		status: make(map[string]int),
+	}
+
+	interval := 50 * time.Millisecond
+	checker := New(store, configuration.LinkCheck{
+		Timeout:      configuration.Duration(time.Second),
+		Concurrency:  3,
+		HostInterval: configuration.Duration(interval),
+	}, zap.NewNop())
+	checker.client = &http.Client{Timeout: time.Second}
+
+	require.NoError(t, checker.CheckAll(context.Background()))
+	require.Len(t, hits, 3)
+
+	first, last := hits[0], hits[0]
+	for _, hit := range hits {
+		if hit.Before(first) {
+			first = hit
+		}
+		if hit.After(last) {
+			last = hit
+		}
+	}
+	assert.GreaterOrEqual(t, last.Sub(first), 2*interval-5*time.Millisecond)
+}
+
+func TestHostWaitDoesNotTakeSlot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer other.Close()
+
+	store := &fakeStore{
+		links: []*entity.URL{
+			{Alias: "a", URL: srv.URL + "/a"},
+			{Alias: "b", URL: srv.URL + "/b"},
+			{Alias: "other", URL: other.URL},
+		},
+		status: make(map[string]int),
+	}
+	checker := New(store, configuration.LinkCheck{
+		Timeout:      configuration.Duration(time.Second),
+		Concurrency:  1,
+		Hosts:        2,
+		HostInterval: configuration.Duration(time.Hour),
+	}, zap.NewNop())
+	checker.client = &http.Client{Timeout: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, checker.CheckAll(ctx), context.DeadlineExceeded)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Equal(t, http.StatusOK, store.status["other"], "the other host is checked while the first one waits")
+	assert.Len(t, store.status, 2)
+}
+
+func TestHostWorkers(t *testing.T) {
+	store := &fakeStore{status: make(map[string]int)}
+	for _, name := range []string{"a", "b", "c"} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+		store.links = append(store.links,
+			&entity.URL{Alias: name + "1", URL: srv.URL + "/1"},
+			&entity.URL{Alias: name + "2", URL: srv.URL + "/2"})
+	}
+	checker := New(store, configuration.LinkCheck{
+		Timeout:      configuration.Duration(time.Second),
+		Concurrency:  3,
+		Hosts:        2,
+		HostInterval: configuration.Duration(time.Hour),
+	}, zap.NewNop())
+	checker.client = &http.Client{Timeout: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, checker.CheckAll(ctx), context.DeadlineExceeded)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Len(t, store.status, 2, "the third host waits for one of the two workers")
+}
+
+func TestInternalTargetsAreNotContacted(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	checker := New(&fakeStore{}, configuration.LinkCheck{Timeout: configuration.Duration(time.Second)}, zap.NewNop())
+	assert.Equal(t, 0, checker.Check(context.Background(), srv.URL))
+	assert.Equal(t, 0, checker.Check(context.Background(), "http://169.254.169.254/latest/meta-data/"))
+	assert.Equal(t, 0, hits)
+}
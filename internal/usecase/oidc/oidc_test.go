@@ -0,0 +1,71 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc"
+	"github.com/nextlag/shortenerURL/internal/usecase/oidc/oidctest"
+)
+
+// noRedirect is a client that returns redirects instead of following them.
+var noRedirect = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}}
+
+// authorize sends the user to the provider and returns the parameters it redirects back with.
+func authorize(t *testing.T, p *oidc.Provider, state, nonce, verifier string) url.Values {
+	t.Helper()
+	resp, err := noRedirect.Get(p.AuthCodeURL(state, nonce, verifier))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query()
+}
+
+func TestCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	stand := oidctest.NewProvider("shortener", "alice")
+	defer stand.Close()
+
+	p, err := oidc.Discover(ctx, stand.Config("http://localhost:8080/api/user/oidc/callback"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, stand.URL, p.Issuer())
+
+	verifier, err := oidc.RandomString()
+	require.NoError(t, err)
+	back := authorize(t, p, "state", "nonce", verifier)
+	assert.Equal(t, "state", back.Get("state"))
+
+	claims, err := p.Exchange(ctx, back.Get("code"), verifier, "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+
+	_, err = p.Exchange(ctx, back.Get("code"), verifier, "nonce")
+	assert.Error(t, err, "codes are redeemed once")
+
+	back = authorize(t, p, "state", "nonce", verifier)
+	_, err = p.Exchange(ctx, back.Get("code"), "wrong verifier", "nonce")
+	assert.Error(t, err, "the PKCE verifier must match")
+
+	back = authorize(t, p, "state", "nonce", verifier)
+	_, err = p.Exchange(ctx, back.Get("code"), verifier, "other nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	stand := oidctest.NewProvider("shortener", "alice")
+	defer stand.Close()
+
+	cfg := stand.Config("http://localhost:8080/api/user/oidc/callback")
+	cfg.Issuer = stand.URL + "/other"
+	_, err := oidc.Discover(context.Background(), cfg, nil)
+	assert.Error(t, err)
+}
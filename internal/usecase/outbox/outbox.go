@@ -0,0 +1,191 @@
+// Package outbox relays the link lifecycle events that a repository records in its
+// outbox table, in the same transaction as the link change, to a pluggable publisher.
+// An event is removed from the outbox only after it was published, so events survive
+// a crash between the database write and the publish.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// defaultInterval is used when no poll interval is configured.
+const defaultInterval = time.Second
+
+// ErrChannelFull is returned by a ChanPublisher whose consumers fall behind. The event
+// stays in the outbox and is published by a later batch.
+var ErrChannelFull = errors.New("outbox channel is full")
+
+// Publisher delivers events outside the process.
+type Publisher interface {
+	Publish(ctx context.Context, event entity.Event) error
+}
+
+// Store is implemented by repositories with a transactional outbox.
+type Store interface {
+	// ProcessOutbox passes up to limit unpublished events, oldest first, to handle and
+	// removes the handled ones. It stops at the first error so that order is preserved,
+	// and returns the number of handled events. handle may run while the outbox is
+	// locked, so publishers must not wait for the network.
+	ProcessOutbox(ctx context.Context, limit int, handle func(entity.Event) error) (int, error)
+}
+
+// Relay periodically moves events from the outbox to the publisher.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	log       *zap.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+// NewRelay creates a new Relay.
+func NewRelay(store Store, publisher Publisher, cfg configuration.Outbox, log *zap.Logger) *Relay {
+	batchSize := cfg.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	interval := time.Duration(cfg.Interval)
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		log:       log,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run relays events until ctx is canceled. Full batches are followed by the next
+// batch immediately, otherwise the relay waits for the poll interval.
+func (r *Relay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := r.RelayBatch(ctx)
+		switch {
+		case errors.Is(err, ErrChannelFull):
+			r.log.Warn("outbox consumers fall behind, retrying later", zap.Int("relayed", n))
+		case err != nil && ctx.Err() == nil:
+			r.log.Error("failed to relay outbox events", zap.Error(err))
+		}
+		if n == r.batchSize && err == nil {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.interval)
+		}
+	}
+}
+
+// RelayBatch publishes a single batch of events and returns the number of published events.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	return r.store.ProcessOutbox(ctx, r.batchSize, func(event entity.Event) error {
+		return r.publisher.Publish(ctx, event)
+	})
+}
+
+// WriterPublisher writes every event as a JSON line to a writer, e.g. a file or stdout.
+type WriterPublisher struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewWriterPublisher creates a publisher that writes to w.
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w, enc: json.NewEncoder(w)}
+}
+
+// NewFilePublisher creates a publisher that appends to the named file,
+// or writes to stdout if name is empty or "-".
+func NewFilePublisher(name string) (*WriterPublisher, error) {
+	if name == "" || name == "-" {
+		return NewWriterPublisher(os.Stdout), nil
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterPublisher(file), nil
+}
+
+// Publish writes the event.
+func (p *WriterPublisher) Publish(_ context.Context, event entity.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enc.Encode(event)
+}
+
+// Close closes the underlying writer if it is a file other than stdout.
+func (p *WriterPublisher) Close() error {
+	if c, ok := p.w.(io.Closer); ok && p.w != os.Stdout {
+		return c.Close()
+	}
+	return nil
+}
+
+// ChanPublisher hands events to in-process consumers, such as the webhook dispatcher,
+// through a channel. It never waits for the consumers: an event the channel has no room
+// for fails with ErrChannelFull and stays in the outbox. Events accepted by the channel
+// are removed from the outbox before the consumers handle them, so the consumers' network
+// calls happen outside the outbox transaction, and the events still queued when the
+// process stops are lost.
+type ChanPublisher struct {
+	c chan<- entity.Event
+}
+
+// NewChanPublisher creates a publisher sending to c.
+func NewChanPublisher(c chan<- entity.Event) *ChanPublisher {
+	return &ChanPublisher{c: c}
+}
+
+// Publish sends the event to the channel if it has room.
+func (p *ChanPublisher) Publish(_ context.Context, event entity.Event) error {
+	select {
+	case p.c <- event:
+		return nil
+	default:
+		return ErrChannelFull
+	}
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, event entity.Event) error
+
+// Publish calls f.
+func (f PublisherFunc) Publish(ctx context.Context, event entity.Event) error {
+	return f(ctx, event)
+}
+
+// MultiPublisher publishes every event to each of the publishers in turn. It stops at the
+// first error, so that the event stays in the outbox and is published again, to the
+// earlier publishers as well: consumers must tolerate duplicates.
+func MultiPublisher(publishers ...Publisher) Publisher {
+	return PublisherFunc(func(ctx context.Context, event entity.Event) error {
+		for _, p := range publishers {
+			if err := p.Publish(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
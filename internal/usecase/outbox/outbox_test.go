@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// memStore is an in-memory outbox.
+type memStore struct {
+	events []entity.Event
+}
+
+func (s *memStore) ProcessOutbox(_ context.Context, limit int, handle func(entity.Event) error) (int, error) {
+	n := 0
+	for n < limit && n < len(s.events) {
+		if err := handle(s.events[n]); err != nil {
+			s.events = s.events[n:]
+			return n, err
+		}
+		n++
+	}
+	s.events = s.events[n:]
+	return n, nil
+}
+
+// failingPublisher accepts events until it reaches the limit.
+type failingPublisher struct {
+	limit     int
+	published []string
+}
+
+func (p *failingPublisher) Publish(_ context.Context, event entity.Event) error {
+	if len(p.published) == p.limit {
+		return errors.New("publisher is down")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func TestRelayBatchKeepsOrder(t *testing.T) {
+	store := &memStore{events: []entity.Event{{ID: "1"}, {ID: "2"}, {ID: "3"}}}
+	publisher := &failingPublisher{limit: 1}
+	relay := NewRelay(store, publisher, configuration.Outbox{BatchSize: 10}, zap.NewNop())
+
+	n, err := relay.RelayBatch(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, store.events, 2, "unpublished events stay in the outbox")
+
+	publisher.limit = 10
+	n, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"1", "2", "3"}, publisher.published)
+	assert.Empty(t, store.events)
+}
+
+func TestRelayRun(t *testing.T) {
+	store := &memStore{events: []entity.Event{{ID: "1"}, {ID: "2"}, {ID: "3"}}}
+	published := make(chan entity.Event)
+	publisher := PublisherFunc(func(ctx context.Context, event entity.Event) error {
+		select {
+		case published <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	relay := NewRelay(store, publisher, configuration.Outbox{BatchSize: 2}, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	for _, id := range []string{"1", "2", "3"} {
+		assert.Equal(t, id, (<-published).ID)
+	}
+	cancel()
+	<-done
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	require.NoError(t, publisher.Publish(context.Background(), entity.Event{ID: "1", Type: entity.EventLinkCreated}))
+	require.NoError(t, publisher.Publish(context.Background(), entity.Event{ID: "2", Type: entity.EventLinkDeleted}))
+
+	dec := json.NewDecoder(&buf)
+	for _, want := range []string{entity.EventLinkCreated, entity.EventLinkDeleted} {
+		var event entity.Event
+		require.NoError(t, dec.Decode(&event))
+		assert.Equal(t, want, event.Type)
+	}
+}
+
+func TestMultiPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	failing := PublisherFunc(func(context.Context, entity.Event) error { return errors.New("unavailable") })
+
+	require.NoError(t, MultiPublisher(NewWriterPublisher(&buf), NewWriterPublisher(&buf)).Publish(context.Background(), entity.Event{ID: "1"}))
+	assert.Equal(t, 2, strings.Count(buf.String(), `"id":"1"`))
+
+	buf.Reset()
+	assert.Error(t, MultiPublisher(failing, NewWriterPublisher(&buf)).Publish(context.Background(), entity.Event{ID: "2"}))
+	assert.Empty(t, buf.String(), "publishing stops at the first error")
+}
+
+func TestChanPublisher(t *testing.T) {
+	events := make(chan entity.Event, 1)
+	store := &memStore{events: []entity.Event{{ID: "1"}, {ID: "2"}}}
+	relay := NewRelay(store, NewChanPublisher(events), configuration.Outbox{BatchSize: 10}, zap.NewNop())
+
+	n, err := relay.RelayBatch(context.Background())
+	assert.ErrorIs(t, err, ErrChannelFull, "the publisher does not wait for the consumers")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "1", (<-events).ID)
+	require.Len(t, store.events, 1, "events the channel has no room for stay in the outbox")
+
+	n, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "2", (<-events).ID)
+}
@@ -0,0 +1,125 @@
+// Package quota defines the long-term quotas of users: the maximum number of links
+// they keep and the maximum number of links they create per day. The quota of a
+// user is the configured default, replaced by an override set by admins, and
+// narrowed by the quota of the API key a request was made with.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// Limits a link may exceed.
+const (
+	LimitLinks      = "max_links"       // LimitLinks is exceeded by a new link over Quota.MaxLinks
+	LimitDailyLinks = "max_daily_links" // LimitDailyLinks is exceeded by a new link over Quota.MaxDailyLinks
+)
+
+var (
+	// ErrExceeded matches the ExceededError of links over the quota.
+	ErrExceeded = errors.New("quota exceeded")
+	// ErrQuotaNotFound is returned when a user has no quota override.
+	ErrQuotaNotFound = errors.New("quota not found")
+	// ErrInvalidQuota is returned for quotas with negative limits.
+	ErrInvalidQuota = errors.New("quota limits must not be negative")
+)
+
+// ExceededError is returned when new links would exceed a limit of the quota.
+type ExceededError struct {
+	Limit string // Limit is LimitLinks or LimitDailyLinks
+	Max   int    // Max is the value of the limit
+}
+
+// Error describes the exceeded limit.
+func (e *ExceededError) Error() string {
+	if e.Limit == LimitDailyLinks {
+		return fmt.Sprintf("quota exceeded: at most %d links can be created per day", e.Max)
+	}
+	return fmt.Sprintf("quota exceeded: at most %d links can be kept", e.Max)
+}
+
+// Is makes errors.Is(err, ErrExceeded) match any ExceededError.
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrExceeded
+}
+
+// Store keeps the quota overrides of users and enforces the quotas on new links.
+type Store interface {
+	// PutQuota stores the quota override of a user.
+	PutQuota(ctx context.Context, userID string, q entity.Quota) error
+	// GetQuota retrieves the quota override of a user, or ErrQuotaNotFound.
+	GetQuota(ctx context.Context, userID string) (*entity.Quota, error)
+	// DelQuota removes the quota override of a user, or returns ErrQuotaNotFound.
+	DelQuota(ctx context.Context, userID string) error
+	// CountLinks counts the links of the user that are not deleted, and the links
+	// the user created since the time given.
+	CountLinks(ctx context.Context, userID string, since time.Time) (links, created int, err error)
+	// PutWithinQuota saves a link as Put does, unless the link would exceed the quota
+	// of the user, counting the links created since the time given against the daily
+	// limit. The check and the insert are atomic.
+	PutWithinQuota(ctx context.Context, url, alias, userID string, q entity.Quota, since time.Time) (string, error)
+	// PutBatchWithinQuota saves the URLs of the user with the aliases at the same index
+	// as PutWithinQuota does, all or none: the new links must fit into the quota together.
+	// URLs the user already shortened cost nothing and keep their alias. It returns the
+	// stored alias of every URL.
+	PutBatchWithinQuota(ctx context.Context, urls, aliases []string, userID string, q entity.Quota, since time.Time) ([]string, error)
+}
+
+// Validate returns ErrInvalidQuota if a limit of the quota is negative.
+func Validate(q entity.Quota) error {
+	if q.MaxLinks < 0 || q.MaxDailyLinks < 0 {
+		return ErrInvalidQuota
+	}
+	return nil
+}
+
+// Check returns an ExceededError if n new links would exceed the quota, given the
+// links of the user and the links it created today.
+func Check(q entity.Quota, links, created, n int) error {
+	if q.MaxLinks > 0 && links+n > q.MaxLinks {
+		return &ExceededError{Limit: LimitLinks, Max: q.MaxLinks}
+	}
+	if q.MaxDailyLinks > 0 && created+n > q.MaxDailyLinks {
+		return &ExceededError{Limit: LimitDailyLinks, Max: q.MaxDailyLinks}
+	}
+	return nil
+}
+
+// Narrow returns the quota with the stricter of each limit of q and other.
+func Narrow(q, other entity.Quota) entity.Quota {
+	return entity.Quota{
+		MaxLinks:      stricter(q.MaxLinks, other.MaxLinks),
+		MaxDailyLinks: stricter(q.MaxDailyLinks, other.MaxDailyLinks),
+	}
+}
+
+// stricter returns the smaller of two limits, where zero is unlimited.
+func stricter(a, b int) int {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// Day returns the start of the UTC day of t, since which links count against the daily limit.
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// keyQuotaKey is the context key of the quota of an API key.
+type keyQuotaKey struct{}
+
+// NewContext returns a copy of ctx carrying the quota of the API key a request was made with.
+func NewContext(ctx context.Context, q entity.Quota) context.Context {
+	return context.WithValue(ctx, keyQuotaKey{}, q)
+}
+
+// FromContext returns the quota of the API key stored in ctx by NewContext.
+func FromContext(ctx context.Context) (entity.Quota, bool) {
+	q, ok := ctx.Value(keyQuotaKey{}).(entity.Quota)
+	return q, ok
+}
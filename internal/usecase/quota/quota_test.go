@@ -0,0 +1,64 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+func TestCheck(t *testing.T) {
+	q := entity.Quota{MaxLinks: 10, MaxDailyLinks: 3}
+
+	tests := []struct {
+		name      string
+		links     int
+		created   int
+		n         int
+		wantLimit string
+	}{
+		{name: "Within quota", links: 5, created: 1, n: 2},
+		{name: "Too many links", links: 9, created: 0, n: 2, wantLimit: LimitLinks},
+		{name: "Too many today", links: 5, created: 3, n: 1, wantLimit: LimitDailyLinks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(q, tt.links, tt.created, tt.n)
+			if tt.wantLimit == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrExceeded)
+			var exceeded *ExceededError
+			if assert.True(t, errors.As(err, &exceeded)) {
+				assert.Equal(t, tt.wantLimit, exceeded.Limit)
+			}
+		})
+	}
+
+	assert.NoError(t, Check(entity.Quota{}, 1000, 1000, 1))
+}
+
+func TestNarrow(t *testing.T) {
+	assert.Equal(t, entity.Quota{MaxLinks: 5, MaxDailyLinks: 3},
+		Narrow(entity.Quota{MaxLinks: 10, MaxDailyLinks: 3}, entity.Quota{MaxLinks: 5, MaxDailyLinks: 20}))
+	assert.Equal(t, entity.Quota{MaxLinks: 5, MaxDailyLinks: 3},
+		Narrow(entity.Quota{MaxDailyLinks: 3}, entity.Quota{MaxLinks: 5}))
+	assert.ErrorIs(t, Validate(entity.Quota{MaxLinks: -1}), ErrInvalidQuota)
+}
+
+func TestDayAndContext(t *testing.T) {
+	zone := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2024, 3, 2, 1, 30, 0, 0, zone)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Day(now))
+
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+	q, ok := FromContext(NewContext(context.Background(), entity.Quota{MaxLinks: 1}))
+	assert.True(t, ok)
+	assert.Equal(t, 1, q.MaxLinks)
+}
@@ -0,0 +1,81 @@
+// Package report defines the storage of abuse reports against links and the rules
+// for filing and reviewing them.
+package report
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+// MaxReasonLength is the maximum length of the reason of a report, in characters.
+const MaxReasonLength = 500
+
+// Actions of admins reviewing a report.
+const (
+	ActionDisable = "disable" // ActionDisable disables the link and closes the report as actioned
+	ActionDismiss = "dismiss" // ActionDismiss closes the report without action
+)
+
+var (
+	// ErrReportNotFound is returned when a report does not exist.
+	ErrReportNotFound = errors.New("report not found")
+	// ErrReportClosed is returned when a report was already reviewed.
+	ErrReportClosed = errors.New("report already reviewed")
+	// ErrInvalidReason is returned for empty or too long reasons.
+	ErrInvalidReason = errors.New("reason must be between 1 and 500 characters")
+	// ErrUnknownAction is returned for review actions other than ActionDisable and ActionDismiss.
+	ErrUnknownAction = errors.New("unknown review action")
+	// ErrUnknownStatus is returned when listing reports of an unknown status.
+	ErrUnknownStatus = errors.New("unknown report status")
+)
+
+// Store keeps the abuse reports.
+type Store interface {
+	// PutReport files a report against the link. If the link has an open report, the
+	// report is merged into it: its count grows and its last report time moves to at.
+	// It returns the open report of the link.
+	PutReport(ctx context.Context, alias, reason string, at time.Time) (*entity.Report, error)
+	// GetReports retrieves the reports with the status, or all reports if status is
+	// empty, the latest reported first.
+	GetReports(ctx context.Context, status string) ([]*entity.Report, error)
+	// GetReport retrieves a report by its ID, or returns ErrReportNotFound.
+	GetReport(ctx context.Context, id string) (*entity.Report, error)
+	// CloseReport sets the status of an open report, or returns ErrReportNotFound or
+	// ErrReportClosed.
+	CloseReport(ctx context.Context, id, status, reviewedBy string, at time.Time) error
+}
+
+// NormalizeReason trims the reason and checks its length.
+func NormalizeReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > MaxReasonLength {
+		return "", ErrInvalidReason
+	}
+	return reason, nil
+}
+
+// StatusOf returns the status a report is closed with by the action.
+func StatusOf(action string) (string, error) {
+	switch action {
+	case ActionDisable:
+		return entity.ReportActioned, nil
+	case ActionDismiss:
+		return entity.ReportDismissed, nil
+	default:
+		return "", ErrUnknownAction
+	}
+}
+
+// ValidStatus reports whether reports can be listed by the status. The empty status lists all reports.
+func ValidStatus(status string) bool {
+	switch status {
+	case "", entity.ReportOpen, entity.ReportActioned, entity.ReportDismissed:
+		return true
+	}
+	return false
+}
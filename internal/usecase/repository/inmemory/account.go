@@ -0,0 +1,198 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const (
+	fileAccounts   = "accounts.json"
+	fileIdentities = "identities.json"
+)
+
+// AccountRecord represents a registered account in file storage.
+type AccountRecord struct {
+	UserID       string    `json:"uuid"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PutAccount stores a new account.
+func (s *Data) PutAccount(_ context.Context, account *entity.Account) error {
+	s.accountMutex.Lock()
+	defer s.accountMutex.Unlock()
+
+	if _, ok := s.accounts[account.Username]; ok {
+		return auth.ErrUsernameTaken
+	}
+	stored := *account
+	s.accounts[account.Username] = &stored
+	s.accountUsers[account.UserID] = account.Username
+
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileAccounts)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, &AccountRecord{
+		UserID:       account.UserID,
+		Username:     account.Username,
+		PasswordHash: account.PasswordHash,
+		CreatedAt:    account.CreatedAt,
+	})
+}
+
+// GetAccount retrieves a copy of an account by its username.
+func (s *Data) GetAccount(_ context.Context, username string) (*entity.Account, error) {
+	s.accountMutex.RLock()
+	defer s.accountMutex.RUnlock()
+
+	account, ok := s.accounts[username]
+	if !ok {
+		return nil, auth.ErrAccountNotFound
+	}
+	stored := *account
+	return &stored, nil
+}
+
+// GetAccountByUserID retrieves a copy of the account owning a user ID.
+func (s *Data) GetAccountByUserID(ctx context.Context, userID string) (*entity.Account, error) {
+	s.accountMutex.RLock()
+	username, ok := s.accountUsers[userID]
+	s.accountMutex.RUnlock()
+	if !ok {
+		return nil, auth.ErrAccountNotFound
+	}
+	return s.GetAccount(ctx, username)
+}
+
+// ClaimLinks moves the links of one user to another, except those whose URL the
+// other user already shortened.
+func (s *Data) ClaimLinks(_ context.Context, fromUserID, toUserID string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	owned := make(map[string]bool)
+	for _, v := range s.data {
+		if v.UserID == toUserID && !v.IsDeleted {
+			owned[v.Canonical] = true
+		}
+	}
+
+	claimed := 0
+	for alias, v := range s.data {
+		if v.UserID != fromUserID || v.IsDeleted || owned[v.Canonical] {
+			continue
+		}
+		v.UserID = toUserID
+		owned[v.Canonical] = true
+		claimed++
+		if s.cfg.FileStorage != "" {
+			if err := save(s.cfg.FileStorage, alias, v.URL, toUserID, v.CreatedAt, false); err != nil {
+				return claimed, err
+			}
+		}
+	}
+	return claimed, nil
+}
+
+// LoadAccounts reads the accounts file into memory.
+func LoadAccounts(db *Data) error {
+	consumer, err := NewConsumer(fileAccounts)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.accountMutex.Lock()
+	defer db.accountMutex.Unlock()
+
+	for {
+		record, err := ReadEvent[AccountRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		db.accounts[record.Username] = &entity.Account{
+			UserID:       record.UserID,
+			Username:     record.Username,
+			PasswordHash: record.PasswordHash,
+			CreatedAt:    record.CreatedAt,
+		}
+		db.accountUsers[record.UserID] = record.Username
+	}
+}
+
+// identityKey returns the key of an identity in memory.
+func identityKey(issuer, subject string) string {
+	return issuer + " " + subject
+}
+
+// PutIdentity stores a new identity.
+func (s *Data) PutIdentity(_ context.Context, identity *entity.Identity) error {
+	s.accountMutex.Lock()
+	defer s.accountMutex.Unlock()
+
+	key := identityKey(identity.Issuer, identity.Subject)
+	if _, ok := s.identities[key]; ok {
+		return auth.ErrIdentityExists
+	}
+	stored := *identity
+	s.identities[key] = &stored
+
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileIdentities)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, &stored)
+}
+
+// GetIdentity retrieves a copy of the identity of a subject of an issuer.
+func (s *Data) GetIdentity(_ context.Context, issuer, subject string) (*entity.Identity, error) {
+	s.accountMutex.RLock()
+	defer s.accountMutex.RUnlock()
+
+	identity, ok := s.identities[identityKey(issuer, subject)]
+	if !ok {
+		return nil, auth.ErrIdentityNotFound
+	}
+	stored := *identity
+	return &stored, nil
+}
+
+// LoadIdentities reads the identities file into memory.
+func LoadIdentities(db *Data) error {
+	consumer, err := NewConsumer(fileIdentities)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.accountMutex.Lock()
+	defer db.accountMutex.Unlock()
+
+	for {
+		identity, err := ReadEvent[entity.Identity](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		db.identities[identityKey(identity.Issuer, identity.Subject)] = &identity
+	}
+}
@@ -0,0 +1,238 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const (
+	fileDisabled = "disabled.json"
+	fileBans     = "bans.json"
+)
+
+// DisabledRecord represents the disabling or enabling of a link in file storage.
+type DisabledRecord struct {
+	Alias    string `json:"alias"`
+	Disabled bool   `json:"disabled"`
+}
+
+// BanRecord represents a ban or its lifting in file storage.
+type BanRecord struct {
+	UserID    string    `json:"uuid"`
+	Reason    string    `json:"reason,omitempty"`
+	BannedBy  string    `json:"banned_by,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Lifted    bool      `json:"lifted,omitempty"`
+}
+
+// SearchLinks retrieves the links of all users matching the filter, deleted ones included.
+func (s *Data) SearchLinks(_ context.Context, filter entity.LinkFilter) ([]*entity.URL, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	aliases := make([]string, 0, len(s.data))
+	for alias, v := range s.data {
+		if v.URL == "" || (filter.UserID != "" && v.UserID != filter.UserID) {
+			continue
+		}
+		if filter.Query != "" && !strings.Contains(alias, filter.Query) && !strings.Contains(v.URL, filter.Query) {
+			continue
+		}
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	if filter.Offset >= len(aliases) {
+		aliases = nil
+	} else {
+		aliases = aliases[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(aliases) > filter.Limit {
+		aliases = aliases[:filter.Limit]
+	}
+
+	urls := make([]*entity.URL, 0, len(aliases))
+	for _, alias := range aliases {
+		v := s.data[alias]
+		urls = append(urls, &entity.URL{
+			UUID:          v.UserID,
+			URL:           v.URL,
+			Alias:         alias,
+			IsDeleted:     v.IsDeleted,
+			Disabled:      v.Disabled,
+			LastStatus:    v.LastStatus,
+			LastCheckedAt: v.LastCheckedAt,
+		})
+	}
+	return urls, nil
+}
+
+// SetDisabled disables or enables the redirect of a link.
+func (s *Data) SetDisabled(_ context.Context, alias string, disabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	v, ok := s.data[alias]
+	if !ok || v.URL == "" {
+		return admin.ErrLinkNotFound
+	}
+	v.Disabled = disabled
+
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileDisabled)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, &DisabledRecord{Alias: alias, Disabled: disabled})
+}
+
+// GetUserCounts counts the links of every user.
+func (s *Data) GetUserCounts(_ context.Context) ([]*entity.UserCount, error) {
+	s.mutex.RLock()
+	counts := make(map[string]*entity.UserCount)
+	for _, v := range s.data {
+		if v.URL == "" {
+			continue
+		}
+		count, ok := counts[v.UserID]
+		if !ok {
+			count = &entity.UserCount{UserID: v.UserID}
+			counts[v.UserID] = count
+		}
+		switch {
+		case v.IsDeleted:
+			count.Deleted++
+		case v.Disabled:
+			count.Links++
+			count.Disabled++
+		default:
+			count.Links++
+		}
+	}
+	s.mutex.RUnlock()
+
+	s.banMutex.RLock()
+	result := make([]*entity.UserCount, 0, len(counts))
+	for _, count := range counts {
+		_, count.Banned = s.bans[count.UserID]
+		result = append(result, count)
+	}
+	s.banMutex.RUnlock()
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result, nil
+}
+
+// LoadDisabled reads the disabled links file into memory. It must run after Load.
+func LoadDisabled(db *Data) error {
+	consumer, err := NewConsumer(fileDisabled)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	for {
+		record, err := ReadEvent[DisabledRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if v, ok := db.data[record.Alias]; ok {
+			v.Disabled = record.Disabled
+		}
+	}
+}
+
+// PutBan stores the ban of a user, replacing an earlier one.
+func (s *Data) PutBan(_ context.Context, ban *entity.Ban) error {
+	s.banMutex.Lock()
+	defer s.banMutex.Unlock()
+
+	stored := *ban
+	s.bans[ban.UserID] = &stored
+	return s.saveBan(&BanRecord{UserID: ban.UserID, Reason: ban.Reason, BannedBy: ban.BannedBy, CreatedAt: ban.CreatedAt})
+}
+
+// DelBan lifts the ban of a user.
+func (s *Data) DelBan(_ context.Context, userID string) error {
+	s.banMutex.Lock()
+	defer s.banMutex.Unlock()
+
+	if _, ok := s.bans[userID]; !ok {
+		return auth.ErrBanNotFound
+	}
+	delete(s.bans, userID)
+	return s.saveBan(&BanRecord{UserID: userID, Lifted: true})
+}
+
+// GetBan retrieves a copy of the ban of a user.
+func (s *Data) GetBan(_ context.Context, userID string) (*entity.Ban, error) {
+	s.banMutex.RLock()
+	defer s.banMutex.RUnlock()
+
+	ban, ok := s.bans[userID]
+	if !ok {
+		return nil, auth.ErrBanNotFound
+	}
+	stored := *ban
+	return &stored, nil
+}
+
+// saveBan appends a ban record to the bans file.
+func (s *Data) saveBan(record *BanRecord) error {
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileBans)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, record)
+}
+
+// LoadBans reads the bans file into memory.
+func LoadBans(db *Data) error {
+	consumer, err := NewConsumer(fileBans)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.banMutex.Lock()
+	defer db.banMutex.Unlock()
+
+	for {
+		record, err := ReadEvent[BanRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if record.Lifted {
+			delete(db.bans, record.UserID)
+			continue
+		}
+		db.bans[record.UserID] = &entity.Ban{
+			UserID:    record.UserID,
+			Reason:    record.Reason,
+			BannedBy:  record.BannedBy,
+			CreatedAt: record.CreatedAt,
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const fileAPIKeys = "apikeys.json"
+
+// Operations recorded in the API key file.
+const (
+	opKey   = "key"   // an API key was created
+	opTouch = "touch" // an API key was used
+)
+
+// APIKeyRecord represents a change of an API key in file storage. Revocations
+// are recorded with opRevoke.
+type APIKeyRecord struct {
+	Op        string        `json:"op"`
+	ID        string        `json:"id"`
+	UserID    string        `json:"uuid,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Prefix    string        `json:"prefix,omitempty"`
+	Hash      string        `json:"hash,omitempty"`
+	Scopes    []string      `json:"scopes,omitempty"`
+	CreatedAt time.Time     `json:"created_at,omitempty"`
+	Quota     *entity.Quota `json:"quota,omitempty"`
+	At        *time.Time    `json:"at,omitempty"`
+}
+
+// PutAPIKey stores a new API key.
+func (s *Data) PutAPIKey(_ context.Context, key *entity.APIKey) error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	stored := *key
+	stored.Key = ""
+	s.apiKeys[key.ID] = &stored
+	s.apiKeyHashes[key.Hash] = key.ID
+	return s.saveAPIKey(&APIKeyRecord{
+		Op:        opKey,
+		ID:        key.ID,
+		UserID:    key.UserID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+		Quota:     key.Quota,
+	})
+}
+
+// GetAPIKeys retrieves copies of the API keys of a user.
+func (s *Data) GetAPIKeys(_ context.Context, userID string) ([]*entity.APIKey, error) {
+	s.keyMutex.RLock()
+	defer s.keyMutex.RUnlock()
+
+	var keys []*entity.APIKey
+	for _, key := range s.apiKeys {
+		if key.UserID == userID {
+			stored := *key
+			keys = append(keys, &stored)
+		}
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash retrieves a copy of an API key by the hash of its secret.
+func (s *Data) GetAPIKeyByHash(_ context.Context, hash string) (*entity.APIKey, error) {
+	s.keyMutex.RLock()
+	defer s.keyMutex.RUnlock()
+
+	key, ok := s.apiKeys[s.apiKeyHashes[hash]]
+	if !ok {
+		return nil, auth.ErrAPIKeyNotFound
+	}
+	stored := *key
+	return &stored, nil
+}
+
+// RevokeAPIKey marks an API key of the user as revoked.
+func (s *Data) RevokeAPIKey(_ context.Context, userID, id string, at time.Time) error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok || key.UserID != userID {
+		return auth.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return s.saveAPIKey(&APIKeyRecord{Op: opRevoke, ID: id, At: &at})
+}
+
+// TouchAPIKey sets the last-used time of an API key.
+func (s *Data) TouchAPIKey(_ context.Context, id string, at time.Time) error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return auth.ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &at
+	return s.saveAPIKey(&APIKeyRecord{Op: opTouch, ID: id, At: &at})
+}
+
+// saveAPIKey appends an API key record to the API key file if the storage is file backed.
+func (s *Data) saveAPIKey(record *APIKeyRecord) error {
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileAPIKeys)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, record)
+}
+
+// LoadAPIKeys replays the API key file into memory.
+func LoadAPIKeys(db *Data) error {
+	consumer, err := NewConsumer(fileAPIKeys)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.keyMutex.Lock()
+	defer db.keyMutex.Unlock()
+
+	for {
+		record, err := ReadEvent[APIKeyRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch record.Op {
+		case opKey:
+			db.apiKeys[record.ID] = &entity.APIKey{
+				ID:        record.ID,
+				UserID:    record.UserID,
+				Name:      record.Name,
+				Prefix:    record.Prefix,
+				Hash:      record.Hash,
+				Scopes:    record.Scopes,
+				CreatedAt: record.CreatedAt,
+				Quota:     record.Quota,
+			}
+			db.apiKeyHashes[record.Hash] = record.ID
+		case opTouch:
+			if key, ok := db.apiKeys[record.ID]; ok {
+				key.LastUsedAt = record.At
+			}
+		case opRevoke:
+			if key, ok := db.apiKeys[record.ID]; ok {
+				key.RevokedAt = record.At
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+)
+
+// fileAudit is the append-only file of the audit log.
+const fileAudit = "audit.json"
+
+// PutAudit appends an entry to the audit log.
+func (s *Data) PutAudit(_ context.Context, entry *entity.AuditEntry) error {
+	s.auditMutex.Lock()
+	defer s.auditMutex.Unlock()
+
+	if s.cfg.FileStorage != "" {
+		producer, err := NewProducer(fileAudit)
+		if err != nil {
+			return err
+		}
+		defer producer.Close()
+		if err = WriteEvent(producer, entry); err != nil {
+			return err
+		}
+	}
+	stored := *entry
+	s.audit = append(s.audit, &stored)
+	return nil
+}
+
+// GetAudit retrieves the entries matching the filter, the latest first.
+func (s *Data) GetAudit(_ context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	s.auditMutex.RLock()
+	defer s.auditMutex.RUnlock()
+
+	var entries []*entity.AuditEntry
+	for _, entry := range s.audit {
+		if audit.Match(entry, filter) {
+			stored := *entry
+			entries = append(entries, &stored)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.After(entries[j].At) })
+	if filter.Offset >= len(entries) {
+		return nil, nil
+	}
+	entries = entries[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(entries) {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// LoadAudit reads the audit log file into memory.
+func LoadAudit(db *Data) error {
+	consumer, err := NewConsumer(fileAudit)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.auditMutex.Lock()
+	defer db.auditMutex.Unlock()
+
+	for {
+		entry, err := ReadEvent[entity.AuditEntry](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		db.audit = append(db.audit, &entry)
+	}
+}
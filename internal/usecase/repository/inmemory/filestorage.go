@@ -3,13 +3,15 @@ package inmemory
 import (
 	"encoding/json"
 	"os"
+	"time"
 )
 
 // FileStorage represents the structure of a URL record for file storage.
 type FileStorage struct {
-	UUID  string `json:"uuid"`                        // UUID, a unique identifier
-	Alias string `json:"alias,omitempty"`             // Alias, a custom alias for the shortened URL (optional)
-	URL   string `json:"url" validate:"required,url"` // URL, the URL to be shortened, must be a valid URL
+	UUID      string    `json:"uuid"`                        // UUID, a unique identifier
+	Alias     string    `json:"alias,omitempty"`             // Alias, a custom alias for the shortened URL (optional)
+	URL       string    `json:"url" validate:"required,url"` // URL, the URL to be shortened, must be a valid URL
+	CreatedAt time.Time `json:"created_at,omitempty"`        // CreatedAt, the creation time of the record, zero in older files
 }
 
 // NewFileStorage creates a new instance of FileStorage.
@@ -45,7 +47,7 @@ type Producer struct {
 
 // NewProducer creates a new Producer for the given file name.
 func NewProducer(fileName string) (*Producer, error) {
-	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +76,7 @@ type Consumer struct {
 
 // NewConsumer creates a new Consumer for the given file name.
 func NewConsumer(fileName string) (*Consumer, error) {
-	file, err := os.OpenFile(fileName, os.O_RDONLY|os.O_CREATE, 0666)
+	file, err := os.OpenFile(fileName, os.O_RDONLY|os.O_CREATE, 0600)
 	if err != nil {
 		return nil, err
 	}
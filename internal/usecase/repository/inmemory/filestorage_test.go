@@ -31,6 +31,11 @@ func TestProducer_WriteEvent(t *testing.T) {
 	err = inmemory.WriteEvent(producer, event)
 	assert.NoError(t, err)
 
+	// The storage files hold credentials, so only the owner reads them
+	info, err := os.Stat(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
 	// Verify the file content
 	file, err := os.Open(fileName)
 	assert.NoError(t, err)
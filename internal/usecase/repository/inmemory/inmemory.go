@@ -7,23 +7,33 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strconv"
+	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/nextlag/shortenerURL/internal/configuration"
 	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/models"
+	"github.com/nextlag/shortenerURL/pkg/tools/canonicalurl"
 	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
 )
 
 const fileDel = "del.json"
 
 type dataDel struct {
-	UserID    string
-	URL       string
-	IsDeleted bool
+	UserID        string
+	URL           string
+	Canonical     string // Canonical is the de-duplication key of URL
+	CreatedAt     time.Time
+	IsDeleted     bool
+	Disabled      bool
+	LastStatus    int
+	LastCheckedAt time.Time
 }
 
 // Data represents the in-memory data storage structure.
@@ -32,17 +42,73 @@ type Data struct {
 	log   *zap.Logger
 	cfg   *configuration.Config
 	mutex sync.RWMutex
+
+	webhooks   map[string]*entity.Webhook
+	deliveries map[string][]*entity.Delivery
+	hookMutex  sync.RWMutex
+
+	sessions      map[string]*entity.Session
+	refreshTokens map[string]*refreshToken
+	sessionMutex  sync.RWMutex
+
+	apiKeys      map[string]*entity.APIKey
+	apiKeyHashes map[string]string
+	keyMutex     sync.RWMutex
+
+	accounts     map[string]*entity.Account
+	accountUsers map[string]string
+	identities   map[string]*entity.Identity
+	accountMutex sync.RWMutex
+
+	bans     map[string]*entity.Ban
+	banMutex sync.RWMutex
+
+	quotas     map[string]*entity.Quota
+	quotaMutex sync.RWMutex
+
+	reports     map[string]*entity.Report
+	openReports map[string]string
+	reportMutex sync.RWMutex
+
+	audit      []*entity.AuditEntry
+	auditMutex sync.RWMutex
 }
 
 // New creates a new instance of Data.
 func New(cfg *configuration.Config, log *zap.Logger) (*Data, error) {
 	return &Data{
-		data: make(map[string]*dataDel),
-		log:  log,
-		cfg:  cfg,
+		data:       make(map[string]*dataDel),
+		log:        log,
+		cfg:        cfg,
+		webhooks:   make(map[string]*entity.Webhook),
+		deliveries: make(map[string][]*entity.Delivery),
+
+		sessions:      make(map[string]*entity.Session),
+		refreshTokens: make(map[string]*refreshToken),
+
+		apiKeys:      make(map[string]*entity.APIKey),
+		apiKeyHashes: make(map[string]string),
+
+		accounts:     make(map[string]*entity.Account),
+		accountUsers: make(map[string]string),
+		identities:   make(map[string]*entity.Identity),
+
+		bans: make(map[string]*entity.Ban),
+
+		quotas: make(map[string]*entity.Quota),
+
+		reports:     make(map[string]*entity.Report),
+		openReports: make(map[string]string),
 	}, nil
 }
 
+// Len returns the number of links in the in-memory storage, deleted ones included.
+func (s *Data) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.data)
+}
+
 // Get retrieves a URL by its alias from the in-memory storage.
 func (s *Data) Get(_ context.Context, alias string) (*entity.URL, error) {
 	s.mutex.RLock()
@@ -54,68 +120,143 @@ func (s *Data) Get(_ context.Context, alias string) (*entity.URL, error) {
 	}
 
 	return &entity.URL{
-		Alias: alias,
-		URL:   delInfo.URL,
+		UUID:     delInfo.UserID,
+		Alias:    alias,
+		URL:      delInfo.URL,
+		Disabled: delInfo.Disabled,
 	}, nil
 }
 
 // GetAll retrieves all URLs for a given user.
-func (s *Data) GetAll(_ context.Context, _ int, host string) ([]*entity.URL, error) {
+func (s *Data) GetAll(_ context.Context, userID string, host string) ([]*entity.URL, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	var userUrls []*entity.URL
 	for alias, delInfo := range s.data {
-		if !delInfo.IsDeleted {
+		if !delInfo.IsDeleted && delInfo.UserID == userID {
 			userUrls = append(userUrls, &entity.URL{
-				Alias: fmt.Sprintf("%s/%s", host, alias),
-				URL:   delInfo.URL,
+				Alias:         fmt.Sprintf("%s/%s", host, alias),
+				URL:           delInfo.URL,
+				Disabled:      delInfo.Disabled,
+				LastStatus:    delInfo.LastStatus,
+				LastCheckedAt: delInfo.LastCheckedAt,
 			})
 		}
 	}
 	return userUrls, nil
 }
 
-// Healthcheck checks if a file exists at the specified path and is accessible for reading and writing.
+// ListLinks retrieves a page of the links of a user that are not deleted, ordered by
+// alias: at most limit links with an alias after the given one.
+func (s *Data) ListLinks(_ context.Context, userID string, after string, limit int) ([]*entity.URL, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var aliases []string
+	for alias, delInfo := range s.data {
+		if !delInfo.IsDeleted && delInfo.UserID == userID && delInfo.URL != "" && alias > after {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	if limit > 0 && len(aliases) > limit {
+		aliases = aliases[:limit]
+	}
+
+	urls := make([]*entity.URL, 0, len(aliases))
+	for _, alias := range aliases {
+		delInfo := s.data[alias]
+		urls = append(urls, &entity.URL{
+			UUID:          userID,
+			Alias:         alias,
+			URL:           delInfo.URL,
+			CreatedAt:     delInfo.CreatedAt,
+			Disabled:      delInfo.Disabled,
+			LastStatus:    delInfo.LastStatus,
+			LastCheckedAt: delInfo.LastCheckedAt,
+		})
+	}
+	return urls, nil
+}
+
+// GetActive retrieves all non-deleted URLs of all users.
+func (s *Data) GetActive(_ context.Context) ([]*entity.URL, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	urls := make([]*entity.URL, 0, len(s.data))
+	for alias, delInfo := range s.data {
+		if !delInfo.IsDeleted && delInfo.URL != "" {
+			urls = append(urls, &entity.URL{
+				Alias:         alias,
+				URL:           delInfo.URL,
+				LastStatus:    delInfo.LastStatus,
+				LastCheckedAt: delInfo.LastCheckedAt,
+			})
+		}
+	}
+	return urls, nil
+}
+
+// SetStatus records the result of a liveness check of the URL behind alias.
+func (s *Data) SetStatus(_ context.Context, alias string, status int, checkedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delInfo, ok := s.data[alias]
+	if !ok {
+		return fmt.Errorf("key '%s' not found", alias)
+	}
+	delInfo.LastStatus = status
+	delInfo.LastCheckedAt = checkedAt
+	return nil
+}
+
+// Healthcheck checks that the storage file, if any, can be written: either the file is a
+// regular file open for writing, or it does not exist yet and its directory does. The
+// check has no side effects, the file is only created by the first write.
 func (s *Data) Healthcheck() (bool, error) {
 	filePath := s.cfg.FileStorage
-	_, err := os.Stat(filePath)
+	if filePath == "" {
+		return true, nil
+	}
 
-	if err != nil {
-		if os.IsNotExist(err) {
-			file, createErr := os.Create(filePath)
-			if createErr != nil {
-				s.log.Error("Healthcheck: unable to create file", zap.Error(createErr))
-				return false, errors.New("file does not exist and cannot be created")
-			}
-			file.Close()
-			s.log.Info("Healthcheck: file created successfully", zap.String("file", filePath))
-			return true, nil
+	info, err := os.Stat(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		dir, err := os.Stat(filepath.Dir(filePath))
+		if err != nil {
+			return false, fmt.Errorf("storage directory is not accessible: %w", err)
 		}
-		s.log.Error("Healthcheck: error stating file", zap.Error(err))
-		return false, err
+		if !dir.IsDir() {
+			return false, fmt.Errorf("storage directory %s is not a directory", filepath.Dir(filePath))
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("storage file is not accessible: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return false, fmt.Errorf("storage file %s is not a regular file", filePath)
 	}
 
-	file, openErr := os.OpenFile(filePath, os.O_RDWR, 0666)
-	if openErr != nil {
-		s.log.Error("Healthcheck: unable to open file", zap.Error(openErr))
-		return false, openErr
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return false, fmt.Errorf("storage file is not writable: %w", err)
 	}
 	file.Close()
-
-	s.log.Info("Healthcheck: file is accessible", zap.String("file", filePath))
 	return true, nil
 }
 
-// Del marks URLs as deleted in the in-memory storage.
-func (s *Data) Del(_ context.Context, userID int, aliases []string) error {
+// Del marks URLs of the user as deleted in the in-memory storage.
+func (s *Data) Del(_ context.Context, userID string, aliases []string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for _, alias := range aliases {
-		if delInfo, exists := s.data[alias]; exists {
+		if delInfo, exists := s.data[alias]; exists && delInfo.UserID == userID {
 			delInfo.IsDeleted = true
-			err := save(s.cfg.FileStorage, alias, delInfo.URL, userID, delInfo.IsDeleted)
+			err := save(s.cfg.FileStorage, alias, delInfo.URL, userID, delInfo.CreatedAt, delInfo.IsDeleted)
 			if err != nil {
 				return err
 			}
@@ -125,7 +266,13 @@ func (s *Data) Del(_ context.Context, userID int, aliases []string) error {
 }
 
 // Put saves a URL with a generated alias in the in-memory storage.
-func (s *Data) Put(_ context.Context, url string, alias string, userID int) (string, error) {
+func (s *Data) Put(ctx context.Context, url string, alias string, userID string) (string, error) {
+	return s.PutWithinQuota(ctx, url, alias, userID, entity.Quota{}, time.Time{})
+}
+
+// PutWithinQuota saves a URL as Put does, unless the link would exceed the quota of the user.
+// Links created since the time given count against the daily limit.
+func (s *Data) PutWithinQuota(_ context.Context, url, alias, userID string, q entity.Quota, since time.Time) (string, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	if alias == "" {
@@ -136,20 +283,30 @@ func (s *Data) Put(_ context.Context, url string, alias string, userID int) (str
 		return "", fmt.Errorf("alias '%s/%s' already exists", s.cfg.BaseURL, alias)
 	}
 
+	canonical := canonicalurl.Key(url)
 	for k, v := range s.data {
-		if v.URL == url {
+		if v.Canonical == canonical && v.UserID == userID {
 			return k, nil
 		}
 	}
 
+	if !q.Unlimited() {
+		links, created := s.countLinks(userID, since)
+		if err := quota.Check(q, links, created, 1); err != nil {
+			return "", err
+		}
+	}
+
 	s.data[alias] = &dataDel{
-		UserID:    strconv.Itoa(userID),
+		UserID:    userID,
 		URL:       url,
+		Canonical: canonical,
+		CreatedAt: time.Now(),
 		IsDeleted: false,
 	}
 
 	if s.cfg.FileStorage != "" {
-		err := save(s.cfg.FileStorage, alias, url, userID, s.data[alias].IsDeleted)
+		err := save(s.cfg.FileStorage, alias, url, userID, s.data[alias].CreatedAt, s.data[alias].IsDeleted)
 		if err != nil {
 			return alias, err
 		}
@@ -157,9 +314,63 @@ func (s *Data) Put(_ context.Context, url string, alias string, userID int) (str
 	return alias, nil
 }
 
+// PutBatchWithinQuota saves the URLs of the user with the aliases at the same index as
+// PutWithinQuota does, all or none: the new links must fit into the quota together.
+func (s *Data) PutBatchWithinQuota(_ context.Context, urls, aliases []string, userID string, q entity.Quota, since time.Time) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	seen := make(map[string]string) // alias by canonical URL
+	for k, v := range s.data {
+		if v.UserID == userID {
+			seen[v.Canonical] = k
+		}
+	}
+	stored := make([]string, len(urls))
+	var n int
+	for i, url := range urls {
+		canonical := canonicalurl.Key(url)
+		alias, ok := seen[canonical]
+		if !ok {
+			alias = aliases[i]
+			if _, exists := s.data[alias]; exists {
+				return nil, fmt.Errorf("alias '%s/%s' already exists", s.cfg.BaseURL, alias)
+			}
+			seen[canonical] = alias
+			n++
+		}
+		stored[i] = alias
+	}
+	if !q.Unlimited() {
+		links, created := s.countLinks(userID, since)
+		if err := quota.Check(q, links, created, n); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for i, url := range urls {
+		alias := stored[i]
+		if alias != aliases[i] {
+			continue
+		}
+		s.data[alias] = &dataDel{
+			UserID:    userID,
+			URL:       url,
+			Canonical: canonicalurl.Key(url),
+			CreatedAt: now,
+		}
+		if s.cfg.FileStorage != "" {
+			if err := save(s.cfg.FileStorage, alias, url, userID, now, false); err != nil {
+				return stored, err
+			}
+		}
+	}
+	return stored, nil
+}
+
 // save writes URL record and deletion status to the specified files.
-func save(file, alias, url string, userID int, isDeleted bool) error {
-	uuid := strconv.Itoa(userID)
+func save(file, alias, url string, uuid string, createdAt time.Time, isDeleted bool) error {
 	if url != "" {
 		producer, err := NewProducer(file)
 		if err != nil {
@@ -167,6 +378,7 @@ func save(file, alias, url string, userID int, isDeleted bool) error {
 		}
 		defer producer.Close()
 		event := NewFileStorage(uuid, alias, url)
+		event.CreatedAt = createdAt
 		if err = WriteEvent(producer, event); err != nil {
 			return err
 		}
@@ -250,8 +462,10 @@ func Load(filename string, db *Data) error {
 			db.mutex.Lock()
 			if delInfo, exists := db.data[item.Alias]; !exists || !delInfo.IsDeleted {
 				db.data[item.Alias] = &dataDel{
-					UserID: item.UUID,
-					URL:    item.URL,
+					UserID:    migrateUserID(item.UUID),
+					URL:       item.URL,
+					Canonical: canonicalurl.Key(item.URL),
+					CreatedAt: item.CreatedAt,
 				}
 			}
 			db.mutex.Unlock()
@@ -268,6 +482,14 @@ func Load(filename string, db *Data) error {
 	return nil
 }
 
+// migrateUserID maps the legacy integer user IDs of older files to their new IDs.
+func migrateUserID(id string) string {
+	if userid.IsLegacy(id) {
+		id, _ = userid.Parse(id)
+	}
+	return id
+}
+
 // GetStats retrieves statistics on the number of URLs and users.
 func (s *Data) GetStats(_ context.Context) ([]byte, error) {
 	s.mutex.RLock()
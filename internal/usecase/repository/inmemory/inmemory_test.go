@@ -1,8 +1,24 @@
 package inmemory
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
 )
 
 func TestSettings(t *testing.T) {
@@ -10,7 +26,599 @@ func TestSettings(t *testing.T) {
 	defer os.Remove(fileStorage)
 	defer os.Remove(fileDel)
 	data := NewFileStorage("1", "12345", "http://yandex.ru")
-	if err := save(fileStorage, data.Alias, data.URL, 1, false); err != nil {
+	if err := save(fileStorage, data.Alias, data.URL, "1", time.Now(), false); err != nil {
 		t.Error(err)
 	}
 }
+
+func TestPutCanonicalDuplicate(t *testing.T) {
+	db, err := New(&configuration.Config{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alias, err := db.Put(context.Background(), "HTTP://Example.com:80", "first", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err := db.Put(context.Background(), "http://example.com/", "second", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplicate != alias {
+		t.Errorf("expected existing alias %q, got %q", alias, duplicate)
+	}
+
+	url, err := db.Get(context.Background(), alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url.URL != "HTTP://Example.com:80" {
+		t.Errorf("expected original spelling to be kept, got %q", url.URL)
+	}
+}
+
+func TestUsersAreIsolated(t *testing.T) {
+	db, err := New(&configuration.Config{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	alice, bob := userid.New(), userid.New()
+
+	aliceAlias, err := db.Put(ctx, "http://example.com", "alice", alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobAlias, err := db.Put(ctx, "http://example.com", "bob", bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliceAlias == bobAlias {
+		t.Fatalf("users share alias %q", aliceAlias)
+	}
+
+	urls, err := db.GetAll(ctx, bob, "http://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 1 || urls[0].Alias != "http://localhost/bob" {
+		t.Errorf("expected only bob's link, got %v", urls)
+	}
+
+	if err = db.Del(ctx, bob, []string{aliceAlias}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Get(ctx, aliceAlias); err != nil {
+		t.Errorf("bob deleted alice's link: %v", err)
+	}
+}
+
+func TestLoadMigratesLegacyUserIDs(t *testing.T) {
+	fileStorage := "legacy_test.json"
+	defer os.Remove(fileStorage)
+	defer os.Remove(fileDel)
+	if err := os.WriteFile(fileStorage, []byte(`{"uuid":"924","alias":"abc","url":"http://example.com"}`+"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := New(&configuration.Config{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Load(fileStorage, db); err != nil {
+		t.Fatal(err)
+	}
+	url, err := db.Get(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url.UUID != userid.FromLegacy(924) {
+		t.Errorf("expected migrated user ID, got %q", url.UUID)
+	}
+}
+
+func TestSessionsPersisted(t *testing.T) {
+	defer os.Remove(fileSessions)
+	ctx := context.Background()
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "sessions_test.json"
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	session := &entity.Session{ID: "s1", UserID: userid.New(), CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err = db.PutSession(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.PutRefreshToken(ctx, "s1", "hash", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.UseRefreshToken(ctx, "hash", now, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadSessions(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if sessionID, err := loaded.UseRefreshToken(ctx, "hash", now, time.Second); err != nil || sessionID != "s1" {
+		t.Errorf("expected reuse of s1 within the grace period to pass, got %q, %v", sessionID, err)
+	}
+	if sessionID, err := loaded.UseRefreshToken(ctx, "hash", now.Add(time.Second), time.Second); !errors.Is(err, auth.ErrRefreshTokenReused) || sessionID != "s1" {
+		t.Errorf("expected reuse of s1 to be detected, got %q, %v", sessionID, err)
+	}
+	if _, err = loaded.UseRefreshToken(ctx, "unknown", now, time.Second); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("expected invalid refresh token, got %v", err)
+	}
+}
+
+func TestAPIKeysPersisted(t *testing.T) {
+	defer os.Remove(fileAPIKeys)
+	ctx := context.Background()
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "apikeys_test.json"
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	user := userid.New()
+	key := &entity.APIKey{ID: "k1", UserID: user, Prefix: "sk_0123", Hash: "hash", Scopes: []string{entity.ScopeLinksRead}, CreatedAt: now}
+	if err = db.PutAPIKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.TouchAPIKey(ctx, "k1", now); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.RevokeAPIKey(ctx, userid.New(), "k1", now); !errors.Is(err, auth.ErrAPIKeyNotFound) {
+		t.Errorf("expected a key of another user to be not found, got %v", err)
+	}
+	if err = db.RevokeAPIKey(ctx, user, "k1", now); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadAPIKeys(loaded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.GetAPIKeyByHash(ctx, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserID != user || !got.HasScope(entity.ScopeLinksRead) || got.LastUsedAt == nil || got.RevokedAt == nil {
+		t.Errorf("unexpected loaded key %+v", got)
+	}
+}
+
+func TestWebhooksPersisted(t *testing.T) {
+	defer os.Remove(fileWebhooks)
+	ctx := context.Background()
+	user := userid.New()
+	hook := &entity.Webhook{ID: "w1", UserID: user, URL: "https://example.com/hook", Secret: "secret", CreatedAt: time.Now()}
+
+	db, err := New(&configuration.Config{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.PutWebhook(ctx, hook); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(fileWebhooks); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no webhook file without file storage, got %v", err)
+	}
+
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "webhooks_test.json"
+	db, err = New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hook := range []*entity.Webhook{hook, {ID: "w2", UserID: user, URL: "https://example.com/other"}} {
+		if err = db.PutWebhook(ctx, hook); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = db.DelWebhook(ctx, user, "w2"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadWebhooks(loaded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.GetWebhooks(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "w1" || got[0].Secret != "secret" {
+		t.Errorf("unexpected loaded webhooks %+v", got)
+	}
+}
+
+func TestClaimLinks(t *testing.T) {
+	ctx := context.Background()
+	db, err := New(&configuration.Config{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymous, account := userid.New(), userid.New()
+	for alias, url := range map[string]string{"a1": "http://example.com/a", "a2": "http://example.com/b"} {
+		if _, err = db.Put(ctx, url, alias, anonymous); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = db.Put(ctx, "http://EXAMPLE.com/b", "b1", account); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := db.ClaimLinks(ctx, anonymous, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed != 1 {
+		t.Errorf("expected 1 claimed link, got %d", claimed)
+	}
+	urls, err := db.GetAll(ctx, account, "http://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 2 {
+		t.Errorf("expected the account to own 2 links, got %d", len(urls))
+	}
+}
+
+func TestAccountsPersisted(t *testing.T) {
+	defer os.Remove(fileAccounts)
+	ctx := context.Background()
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "accounts_test.json"
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := &entity.Account{UserID: userid.New(), Username: "alice", PasswordHash: "hash", CreatedAt: time.Now()}
+	if err = db.PutAccount(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.PutAccount(ctx, account); !errors.Is(err, auth.ErrUsernameTaken) {
+		t.Errorf("expected username to be taken, got %v", err)
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadAccounts(loaded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.GetAccountByUserID(ctx, account.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Username != "alice" || got.PasswordHash != "hash" {
+		t.Errorf("unexpected loaded account %+v", got)
+	}
+}
+
+func TestModerationPersisted(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "moderation_test.json"
+	for _, file := range []string{cfg.FileStorage, fileDel, fileDisabled, fileBans} {
+		defer os.Remove(file)
+	}
+	ctx := context.Background()
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	spammer, other := userid.New(), userid.New()
+	for alias, url := range map[string]string{"s1": "http://spam.example/a", "s2": "http://spam.example/b"} {
+		if _, err = db.Put(ctx, url, alias, spammer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = db.Put(ctx, "http://example.com", "o1", other); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Del(ctx, spammer, []string{"s2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.SetDisabled(ctx, "s1", true); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.SetDisabled(ctx, "missing", true); !errors.Is(err, admin.ErrLinkNotFound) {
+		t.Errorf("expected link not found, got %v", err)
+	}
+	if err = db.PutBan(ctx, &entity.Ban{UserID: spammer, BannedBy: other, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, load := range []func(*Data) error{func(d *Data) error { return Load(cfg.FileStorage, d) }, LoadDisabled, LoadBans} {
+		if err = load(loaded); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	urls, err := loaded.SearchLinks(ctx, entity.LinkFilter{Query: "spam.example/a", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 1 || urls[0].Alias != "s1" || !urls[0].Disabled {
+		t.Errorf("expected the disabled link s1, got %+v", urls)
+	}
+	counts, err := loaded.GetUserCounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, count := range counts {
+		if count.UserID == spammer && (count.Links != 1 || count.Disabled != 1 || !count.Banned) {
+			t.Errorf("unexpected counts of the spammer %+v", count)
+		}
+	}
+	if _, err = loaded.GetBan(ctx, spammer); err != nil {
+		t.Errorf("expected the ban to be loaded, got %v", err)
+	}
+}
+
+func TestPutWithinQuota(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "quota_test.json"
+	for _, file := range []string{cfg.FileStorage, fileDel, fileQuotas} {
+		defer os.Remove(file)
+	}
+	ctx := context.Background()
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := userid.New()
+	q := entity.Quota{MaxLinks: 2, MaxDailyLinks: 3}
+	today := quota.Day(time.Now())
+
+	for alias, url := range map[string]string{"q1": "http://example.com/1", "q2": "http://example.com/2"} {
+		if _, err = db.PutWithinQuota(ctx, url, alias, user, q, today); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = db.PutWithinQuota(ctx, "http://example.com/1", "", user, q, today); err != nil {
+		t.Errorf("expected a duplicate to fit into the quota, got %v", err)
+	}
+	var exceeded *quota.ExceededError
+	if _, err = db.PutWithinQuota(ctx, "http://example.com/3", "", user, q, today); !errors.As(err, &exceeded) || exceeded.Limit != quota.LimitLinks {
+		t.Fatalf("expected the links limit to be exceeded, got %v", err)
+	}
+
+	if err = db.Del(ctx, user, []string{"q1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.PutWithinQuota(ctx, "http://example.com/3", "", user, q, today); err != nil {
+		t.Fatalf("expected a deleted link to free the quota, got %v", err)
+	}
+	if _, err = db.PutWithinQuota(ctx, "http://example.com/4", "", user, entity.Quota{MaxDailyLinks: 3}, today); !errors.As(err, &exceeded) || exceeded.Limit != quota.LimitDailyLinks {
+		t.Fatalf("expected the daily limit to be exceeded, got %v", err)
+	}
+	links, created, err := db.CountLinks(ctx, user, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links != 2 || created != 3 {
+		t.Errorf("expected 2 links and 3 created today, got %d and %d", links, created)
+	}
+
+	if err = db.PutQuota(ctx, user, entity.Quota{MaxLinks: 100}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadQuotas(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := loaded.GetQuota(ctx, user); err != nil || got.MaxLinks != 100 {
+		t.Errorf("expected the quota override to be loaded, got %+v, %v", got, err)
+	}
+	if err = loaded.DelQuota(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loaded.GetQuota(ctx, user); !errors.Is(err, quota.ErrQuotaNotFound) {
+		t.Errorf("expected the quota override to be removed, got %v", err)
+	}
+}
+
+func TestPutBatchWithinQuota(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "batch_test.json"
+	for _, file := range []string{cfg.FileStorage, fileDel} {
+		defer os.Remove(file)
+	}
+	ctx := context.Background()
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := userid.New()
+	q := entity.Quota{MaxLinks: 3}
+	today := quota.Day(time.Now())
+	if _, err = db.PutWithinQuota(ctx, "http://example.com/1", "b1", user, q, today); err != nil {
+		t.Fatal(err)
+	}
+
+	// Three new links exceed the quota together, so none of them is saved.
+	urls := []string{"http://example.com/2", "http://example.com/3", "http://example.com/4"}
+	if _, err = db.PutBatchWithinQuota(ctx, urls, []string{"b2", "b3", "b4"}, user, q, today); !errors.Is(err, quota.ErrExceeded) {
+		t.Fatalf("expected the batch to exceed the quota, got %v", err)
+	}
+	if links, _, _ := db.CountLinks(ctx, user, today); links != 1 {
+		t.Fatalf("expected no link of the rejected batch to be saved, got %d links", links)
+	}
+
+	// Duplicates cost nothing and keep their alias, within the batch as well.
+	urls = []string{"http://example.com/1", "http://example.com/2", "http://example.com/2", "http://example.com/3"}
+	stored, err := db.PutBatchWithinQuota(ctx, urls, []string{"c1", "c2", "c3", "c4"}, user, q, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b1", "c2", "c2", "c4"}; !slices.Equal(stored, want) {
+		t.Errorf("expected aliases %v, got %v", want, stored)
+	}
+	if links, _, _ := db.CountLinks(ctx, user, today); links != 3 {
+		t.Errorf("expected 3 links, got %d", links)
+	}
+}
+
+func TestReports(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "report_test.json"
+	for _, file := range []string{cfg.FileStorage, fileReports} {
+		defer os.Remove(file)
+	}
+	ctx := context.Background()
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	first, err := db.PutReport(ctx, "abc", "phishing", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeat, err := db.PutReport(ctx, "abc", "malware", now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repeat.ID != first.ID || repeat.Count != 2 || repeat.Reason != "phishing" || repeat.LastReason != "malware" {
+		t.Errorf("expected the repeat report to be merged, got %+v", repeat)
+	}
+
+	if err = db.CloseReport(ctx, first.ID, entity.ReportActioned, "root", now); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.CloseReport(ctx, first.ID, entity.ReportDismissed, "root", now); !errors.Is(err, report.ErrReportClosed) {
+		t.Errorf("expected a closed report to stay closed, got %v", err)
+	}
+	reopened, err := db.PutReport(ctx, "abc", "spam", now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.ID == first.ID || reopened.Count != 1 {
+		t.Errorf("expected a new report after review, got %+v", reopened)
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadReports(loaded); err != nil {
+		t.Fatal(err)
+	}
+	open, err := loaded.GetReports(ctx, entity.ReportOpen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 1 || open[0].ID != reopened.ID {
+		t.Fatalf("expected one open report to be loaded, got %+v", open)
+	}
+	closed, err := loaded.GetReport(ctx, first.ID)
+	if err != nil || closed.Status != entity.ReportActioned || closed.Count != 2 || closed.ReviewedBy != "root" {
+		t.Errorf("expected the actioned report to be loaded, got %+v, %v", closed, err)
+	}
+	if merged, err := loaded.PutReport(ctx, "abc", "spam", now.Add(3*time.Minute)); err != nil || merged.ID != reopened.ID {
+		t.Errorf("expected the loaded open report to take repeat reports, got %+v, %v", merged, err)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.FileStorage = "audit_test.json"
+	for _, file := range []string{cfg.FileStorage, fileAudit} {
+		defer os.Remove(file)
+	}
+	ctx := context.Background()
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	entries := []*entity.AuditEntry{
+		{ID: "1", Action: audit.ActionLinkCreate, Alias: "abc", Actor: "user", After: []byte(`{"short_url":"abc"}`), At: start},
+		{ID: "2", Action: audit.ActionLinkDisable, Alias: "abc", Actor: "admin", At: start.Add(time.Minute)},
+		{ID: "3", Action: audit.ActionUserBan, Target: "user", Actor: "admin", At: start.Add(2 * time.Minute)},
+	}
+	for _, entry := range entries {
+		if err = db.PutAudit(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LoadAudit(loaded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.GetAudit(ctx, entity.AuditFilter{Alias: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "2" || got[1].ID != "1" || string(got[1].After) != `{"short_url":"abc"}` {
+		t.Errorf("expected the entries of the link, the latest first, got %+v", got)
+	}
+	got, err = loaded.GetAudit(ctx, entity.AuditFilter{Actor: "admin", From: start.Add(time.Minute), Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "2" {
+		t.Errorf("expected the second page of the entries of the admin, got %+v", got)
+	}
+	if got, _ = loaded.GetAudit(ctx, entity.AuditFilter{To: start}); len(got) != 0 {
+		t.Errorf("expected no entries before the start, got %+v", got)
+	}
+}
+
+func TestHealthcheckHasNoSideEffects(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &configuration.Config{}
+	cfg.FileStorage = filepath.Join(dir, "data.json")
+	db, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := db.Healthcheck(); !ok || err != nil {
+		t.Fatalf("missing file in existing directory: got %v, %v", ok, err)
+	}
+	if _, err = os.Stat(cfg.FileStorage); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("healthcheck created the storage file: %v", err)
+	}
+
+	cfg.FileStorage = filepath.Join(dir, "missing", "data.json")
+	if ok, err := db.Healthcheck(); ok || err == nil {
+		t.Error("expected a missing directory to fail")
+	}
+
+	cfg.FileStorage = dir
+	if ok, err := db.Healthcheck(); ok || err == nil {
+		t.Error("expected a directory in place of the file to fail")
+	}
+}
@@ -0,0 +1,117 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+)
+
+const fileQuotas = "quotas.json"
+
+// QuotaRecord represents the quota override of a user, or its removal, in file storage.
+type QuotaRecord struct {
+	UserID string        `json:"uuid"`
+	Quota  *entity.Quota `json:"quota,omitempty"` // Quota is nil when the override was removed
+}
+
+// PutQuota stores the quota override of a user.
+func (s *Data) PutQuota(_ context.Context, userID string, q entity.Quota) error {
+	s.quotaMutex.Lock()
+	defer s.quotaMutex.Unlock()
+
+	s.quotas[userID] = &q
+	return s.saveQuota(&QuotaRecord{UserID: userID, Quota: &q})
+}
+
+// GetQuota retrieves a copy of the quota override of a user.
+func (s *Data) GetQuota(_ context.Context, userID string) (*entity.Quota, error) {
+	s.quotaMutex.RLock()
+	defer s.quotaMutex.RUnlock()
+
+	q, ok := s.quotas[userID]
+	if !ok {
+		return nil, quota.ErrQuotaNotFound
+	}
+	stored := *q
+	return &stored, nil
+}
+
+// DelQuota removes the quota override of a user.
+func (s *Data) DelQuota(_ context.Context, userID string) error {
+	s.quotaMutex.Lock()
+	defer s.quotaMutex.Unlock()
+
+	if _, ok := s.quotas[userID]; !ok {
+		return quota.ErrQuotaNotFound
+	}
+	delete(s.quotas, userID)
+	return s.saveQuota(&QuotaRecord{UserID: userID})
+}
+
+// CountLinks counts the links of the user that are not deleted and the links it created since.
+func (s *Data) CountLinks(_ context.Context, userID string, since time.Time) (int, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	links, created := s.countLinks(userID, since)
+	return links, created, nil
+}
+
+// countLinks counts the links of the user. The caller must hold s.mutex.
+func (s *Data) countLinks(userID string, since time.Time) (links, created int) {
+	for _, v := range s.data {
+		if v.UserID != userID || v.URL == "" {
+			continue
+		}
+		if !v.IsDeleted {
+			links++
+		}
+		if !v.CreatedAt.Before(since) {
+			created++
+		}
+	}
+	return links, created
+}
+
+// saveQuota appends a quota record to the quotas file.
+func (s *Data) saveQuota(record *QuotaRecord) error {
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileQuotas)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, record)
+}
+
+// LoadQuotas reads the quotas file into memory.
+func LoadQuotas(db *Data) error {
+	consumer, err := NewConsumer(fileQuotas)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.quotaMutex.Lock()
+	defer db.quotaMutex.Unlock()
+
+	for {
+		record, err := ReadEvent[QuotaRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if record.Quota == nil {
+			delete(db.quotas, record.UserID)
+			continue
+		}
+		db.quotas[record.UserID] = record.Quota
+	}
+}
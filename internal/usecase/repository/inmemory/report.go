@@ -0,0 +1,135 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+)
+
+// fileReports keeps a snapshot of a report after every change; the latest one wins.
+const fileReports = "reports.json"
+
+// PutReport files a report against the link, merging it into the open report of the link.
+func (s *Data) PutReport(_ context.Context, alias, reason string, at time.Time) (*entity.Report, error) {
+	s.reportMutex.Lock()
+	defer s.reportMutex.Unlock()
+
+	r, ok := s.reports[s.openReports[alias]]
+	if ok {
+		r.Count++
+		r.LastReason = reason
+		r.LastReportedAt = at
+	} else {
+		r = &entity.Report{
+			ID:             generatestring.GenerateUUID(),
+			Alias:          alias,
+			Reason:         reason,
+			LastReason:     reason,
+			Count:          1,
+			Status:         entity.ReportOpen,
+			CreatedAt:      at,
+			LastReportedAt: at,
+		}
+		s.reports[r.ID] = r
+		s.openReports[alias] = r.ID
+	}
+	if err := s.saveReport(r); err != nil {
+		return nil, err
+	}
+	stored := *r
+	return &stored, nil
+}
+
+// GetReports retrieves copies of the reports with the status, the latest reported first.
+func (s *Data) GetReports(_ context.Context, status string) ([]*entity.Report, error) {
+	s.reportMutex.RLock()
+	defer s.reportMutex.RUnlock()
+
+	var reports []*entity.Report
+	for _, r := range s.reports {
+		if status == "" || r.Status == status {
+			stored := *r
+			reports = append(reports, &stored)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].LastReportedAt.After(reports[j].LastReportedAt) })
+	return reports, nil
+}
+
+// GetReport retrieves a copy of a report.
+func (s *Data) GetReport(_ context.Context, id string) (*entity.Report, error) {
+	s.reportMutex.RLock()
+	defer s.reportMutex.RUnlock()
+
+	r, ok := s.reports[id]
+	if !ok {
+		return nil, report.ErrReportNotFound
+	}
+	stored := *r
+	return &stored, nil
+}
+
+// CloseReport sets the status of an open report.
+func (s *Data) CloseReport(_ context.Context, id, status, reviewedBy string, at time.Time) error {
+	s.reportMutex.Lock()
+	defer s.reportMutex.Unlock()
+
+	r, ok := s.reports[id]
+	if !ok {
+		return report.ErrReportNotFound
+	}
+	if r.Status != entity.ReportOpen {
+		return report.ErrReportClosed
+	}
+	r.Status = status
+	r.ReviewedBy = reviewedBy
+	r.ReviewedAt = &at
+	delete(s.openReports, r.Alias)
+	return s.saveReport(r)
+}
+
+// saveReport appends a snapshot of the report to the reports file.
+func (s *Data) saveReport(r *entity.Report) error {
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileReports)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, r)
+}
+
+// LoadReports reads the reports file into memory.
+func LoadReports(db *Data) error {
+	consumer, err := NewConsumer(fileReports)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.reportMutex.Lock()
+	defer db.reportMutex.Unlock()
+
+	for {
+		r, err := ReadEvent[entity.Report](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		db.reports[r.ID] = &r
+		if r.Status == entity.ReportOpen {
+			db.openReports[r.Alias] = r.ID
+		} else if db.openReports[r.Alias] == r.ID {
+			delete(db.openReports, r.Alias)
+		}
+	}
+}
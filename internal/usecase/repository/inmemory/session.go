@@ -0,0 +1,192 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const fileSessions = "sessions.json"
+
+// Operations recorded in the session file.
+const (
+	opSession = "session" // a session was created
+	opToken   = "token"   // a refresh token was added to a session
+	opUse     = "use"     // a refresh token was used
+	opRevoke  = "revoke"  // a session was revoked
+)
+
+// SessionRecord represents a change of a session or refresh token in file storage.
+type SessionRecord struct {
+	Op        string     `json:"op"`
+	SessionID string     `json:"session_id,omitempty"`
+	UserID    string     `json:"uuid,omitempty"`
+	TokenHash string     `json:"token_hash,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	UsedAt    time.Time  `json:"used_at,omitempty"`
+}
+
+// refreshToken is a stored refresh token.
+type refreshToken struct {
+	sessionID string
+	expiresAt time.Time
+	used      bool
+	usedAt    time.Time
+}
+
+// PutSession stores a new session.
+func (s *Data) PutSession(_ context.Context, session *entity.Session) error {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	stored := *session
+	s.sessions[session.ID] = &stored
+	return s.saveSession(&SessionRecord{
+		Op:        opSession,
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		CreatedAt: session.CreatedAt,
+		ExpiresAt: session.ExpiresAt,
+	})
+}
+
+// GetSession retrieves a copy of a session by its ID.
+func (s *Data) GetSession(_ context.Context, id string) (*entity.Session, error) {
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, auth.ErrSessionNotFound
+	}
+	stored := *session
+	return &stored, nil
+}
+
+// GetSessions retrieves copies of the sessions of a user.
+func (s *Data) GetSessions(_ context.Context, userID string) ([]*entity.Session, error) {
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+
+	var sessions []*entity.Session
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			stored := *session
+			sessions = append(sessions, &stored)
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession marks a session as revoked.
+func (s *Data) RevokeSession(_ context.Context, id string, at time.Time) error {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return auth.ErrSessionNotFound
+	}
+	if session.RevokedAt == nil {
+		session.RevokedAt = &at
+	}
+	return s.saveSession(&SessionRecord{Op: opRevoke, SessionID: id, RevokedAt: &at})
+}
+
+// PutRefreshToken adds a refresh token to a session and extends the session until expiresAt.
+func (s *Data) PutRefreshToken(_ context.Context, sessionID, hash string, expiresAt time.Time) error {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return auth.ErrSessionNotFound
+	}
+	session.ExpiresAt = expiresAt
+	s.refreshTokens[hash] = &refreshToken{sessionID: sessionID, expiresAt: expiresAt}
+	return s.saveSession(&SessionRecord{Op: opToken, SessionID: sessionID, TokenHash: hash, ExpiresAt: expiresAt})
+}
+
+// UseRefreshToken marks an unused, unexpired refresh token as used and returns its session.
+// A token used less than grace before now also returns its session.
+func (s *Data) UseRefreshToken(_ context.Context, hash string, now time.Time, grace time.Duration) (string, error) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	token, ok := s.refreshTokens[hash]
+	if !ok || !now.Before(token.expiresAt) {
+		return "", auth.ErrInvalidRefreshToken
+	}
+	if token.used {
+		if now.Sub(token.usedAt) < grace {
+			return token.sessionID, nil
+		}
+		return token.sessionID, auth.ErrRefreshTokenReused
+	}
+	token.used = true
+	token.usedAt = now
+	return token.sessionID, s.saveSession(&SessionRecord{Op: opUse, TokenHash: hash, UsedAt: now})
+}
+
+// saveSession appends a session record to the session file if the storage is file backed.
+func (s *Data) saveSession(record *SessionRecord) error {
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileSessions)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, record)
+}
+
+// LoadSessions replays the session file into memory.
+func LoadSessions(db *Data) error {
+	consumer, err := NewConsumer(fileSessions)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.sessionMutex.Lock()
+	defer db.sessionMutex.Unlock()
+
+	for {
+		record, err := ReadEvent[SessionRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch record.Op {
+		case opSession:
+			db.sessions[record.SessionID] = &entity.Session{
+				ID:        record.SessionID,
+				UserID:    record.UserID,
+				CreatedAt: record.CreatedAt,
+				ExpiresAt: record.ExpiresAt,
+			}
+		case opToken:
+			if session, ok := db.sessions[record.SessionID]; ok {
+				session.ExpiresAt = record.ExpiresAt
+			}
+			db.refreshTokens[record.TokenHash] = &refreshToken{sessionID: record.SessionID, expiresAt: record.ExpiresAt}
+		case opUse:
+			if token, ok := db.refreshTokens[record.TokenHash]; ok {
+				token.used = true
+				token.usedAt = record.UsedAt
+			}
+		case opRevoke:
+			if session, ok := db.sessions[record.SessionID]; ok {
+				session.RevokedAt = record.RevokedAt
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package inmemory
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
+)
+
+const (
+	fileWebhooks  = "webhooks.json"
+	maxDeliveries = 100 // maxDeliveries is the number of delivery attempts kept per webhook
+)
+
+// WebhookRecord represents a webhook registration or removal in file storage.
+type WebhookRecord struct {
+	ID        string        `json:"id"`
+	UserID    userid.Compat `json:"uuid"`
+	URL       string        `json:"url"`
+	Secret    string        `json:"secret"`
+	Events    []string      `json:"events,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	Deleted   bool          `json:"deleted,omitempty"`
+}
+
+// PutWebhook stores a webhook registration.
+func (s *Data) PutWebhook(_ context.Context, hook *entity.Webhook) error {
+	s.hookMutex.Lock()
+	defer s.hookMutex.Unlock()
+
+	stored := *hook
+	s.webhooks[hook.ID] = &stored
+	return s.saveWebhook(&WebhookRecord{
+		ID:        hook.ID,
+		UserID:    userid.Compat(hook.UserID),
+		URL:       hook.URL,
+		Secret:    hook.Secret,
+		Events:    hook.Events,
+		CreatedAt: hook.CreatedAt,
+	})
+}
+
+// GetWebhooks retrieves copies of the webhooks registered by the user.
+func (s *Data) GetWebhooks(_ context.Context, userID string) ([]*entity.Webhook, error) {
+	s.hookMutex.RLock()
+	defer s.hookMutex.RUnlock()
+
+	var hooks []*entity.Webhook
+	for _, hook := range s.webhooks {
+		if hook.UserID == userID {
+			h := *hook
+			hooks = append(hooks, &h)
+		}
+	}
+	return hooks, nil
+}
+
+// DelWebhook removes a webhook registered by the user together with its delivery log.
+func (s *Data) DelWebhook(_ context.Context, userID string, id string) error {
+	s.hookMutex.Lock()
+	defer s.hookMutex.Unlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok || hook.UserID != userID {
+		return webhook.ErrNotFound
+	}
+	delete(s.webhooks, id)
+	delete(s.deliveries, id)
+	return s.saveWebhook(&WebhookRecord{ID: id, UserID: userid.Compat(userID), Deleted: true})
+}
+
+// PutDelivery appends a delivery attempt to the log of its webhook, keeping the most recent ones.
+func (s *Data) PutDelivery(_ context.Context, delivery *entity.Delivery) error {
+	s.hookMutex.Lock()
+	defer s.hookMutex.Unlock()
+
+	if _, ok := s.webhooks[delivery.WebhookID]; !ok {
+		return webhook.ErrNotFound
+	}
+	log := append(s.deliveries[delivery.WebhookID], delivery)
+	if len(log) > maxDeliveries {
+		log = log[len(log)-maxDeliveries:]
+	}
+	s.deliveries[delivery.WebhookID] = log
+	return nil
+}
+
+// GetDeliveries retrieves the delivery log of a webhook registered by the user, newest first.
+func (s *Data) GetDeliveries(_ context.Context, userID string, webhookID string) ([]*entity.Delivery, error) {
+	s.hookMutex.RLock()
+	defer s.hookMutex.RUnlock()
+
+	hook, ok := s.webhooks[webhookID]
+	if !ok || hook.UserID != userID {
+		return nil, webhook.ErrNotFound
+	}
+	log := s.deliveries[webhookID]
+	deliveries := make([]*entity.Delivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, log[i])
+	}
+	return deliveries, nil
+}
+
+// saveWebhook appends a webhook record to the webhook file if the storage is file backed.
+func (s *Data) saveWebhook(record *WebhookRecord) error {
+	if s.cfg.FileStorage == "" {
+		return nil
+	}
+	producer, err := NewProducer(fileWebhooks)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+	return WriteEvent(producer, record)
+}
+
+// LoadWebhooks reads webhook registrations from the webhook file and loads them into memory.
+func LoadWebhooks(db *Data) error {
+	consumer, err := NewConsumer(fileWebhooks)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+
+	db.hookMutex.Lock()
+	defer db.hookMutex.Unlock()
+
+	for {
+		record, err := ReadEvent[WebhookRecord](consumer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if record.Deleted {
+			delete(db.webhooks, record.ID)
+			continue
+		}
+		db.webhooks[record.ID] = &entity.Webhook{
+			ID:        record.ID,
+			UserID:    string(record.UserID),
+			URL:       record.URL,
+			Secret:    record.Secret,
+			Events:    record.Events,
+			CreatedAt: record.CreatedAt,
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/tracing"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
+)
+
+// Observer receives the duration and outcome of every repository operation.
+type Observer interface {
+	// ObserveRepository records an operation of the backend. err is nil for operations
+	// that succeeded or had an expected outcome.
+	ObserveRepository(backend, operation string, d time.Duration, err error)
+}
+
+// expectedErrors are outcomes of operations that are not failures of the backend.
+var expectedErrors = []error{
+	psql.ErrConflict,
+	admin.ErrLinkNotFound,
+	webhook.ErrNotFound,
+	auth.ErrAccountNotFound,
+	auth.ErrUsernameTaken,
+	auth.ErrIdentityNotFound,
+	auth.ErrIdentityExists,
+	auth.ErrSessionNotFound,
+	auth.ErrInvalidRefreshToken,
+	auth.ErrRefreshTokenReused,
+	auth.ErrBanNotFound,
+	auth.ErrAPIKeyNotFound,
+	quota.ErrExceeded,
+	quota.ErrQuotaNotFound,
+	report.ErrReportNotFound,
+	report.ErrReportClosed,
+}
+
+// instrumented reports the operations of a repository to an Observer and traces them.
+type instrumented struct {
+	repo     Repository
+	backend  string
+	observer Observer
+	tracer   *tracing.Tracer
+}
+
+// Instrument wraps the repository to report the duration and errors of its operations
+// to the observer and to trace them with a span each. Either may be nil. Type assertions
+// for optional interfaces, such as outbox.Store, must be made on Unwrap of the result.
+func Instrument(repo Repository, observer Observer, tracer *tracing.Tracer) Repository {
+	return &instrumented{repo: repo, backend: Backend(repo), observer: observer, tracer: tracer}
+}
+
+// Unwrap returns the repository wrapped by Instrument, or repo itself.
+func Unwrap(repo Repository) Repository {
+	if r, ok := repo.(*instrumented); ok {
+		return r.repo
+	}
+	return repo
+}
+
+// Backend returns the name of the backend of the repository: "memory", "postgres" or,
+// for other implementations, "other".
+func Backend(repo Repository) string {
+	switch Unwrap(repo).(type) {
+	case *inmemory.Data:
+		return "memory"
+	case *psql.Repo:
+		return "postgres"
+	default:
+		return "other"
+	}
+}
+
+// start starts the span of an operation and returns the function ending it, which
+// reports the operation with its error.
+func (r *instrumented) start(ctx context.Context, operation string) (context.Context, func(err error)) {
+	begin := time.Now()
+	kind := trace.SpanKindInternal
+	if r.backend == "postgres" {
+		kind = trace.SpanKindClient
+	}
+	ctx, span := r.tracer.Start(ctx, "repository."+operation, kind)
+	span.SetAttributes(attribute.String("repository.backend", r.backend))
+	return ctx, func(err error) {
+		failure := unexpected(err)
+		tracing.RecordError(span, failure)
+		span.End()
+		r.observe(operation, begin, &failure)
+	}
+}
+
+// observe reports the operation that started at start and ended with *err.
+func (r *instrumented) observe(operation string, start time.Time, err *error) {
+	if r.observer != nil {
+		r.observer.ObserveRepository(r.backend, operation, time.Since(start), unexpected(*err))
+	}
+}
+
+// unexpected returns err unless it is an expected outcome.
+func unexpected(err error) error {
+	for _, expected := range expectedErrors {
+		if errors.Is(err, expected) {
+			return nil
+		}
+	}
+	return err
+}
+
+func (r *instrumented) ClaimLinks(ctx context.Context, fromUserID, toUserID string) (_ int, err error) {
+	ctx, end := r.start(ctx, "ClaimLinks")
+	defer func() { end(err) }()
+	return r.repo.ClaimLinks(ctx, fromUserID, toUserID)
+}
+
+func (r *instrumented) CloseReport(ctx context.Context, id, status, reviewedBy string, at time.Time) (err error) {
+	ctx, end := r.start(ctx, "CloseReport")
+	defer func() { end(err) }()
+	return r.repo.CloseReport(ctx, id, status, reviewedBy, at)
+}
+
+func (r *instrumented) CountLinks(ctx context.Context, userID string, since time.Time) (links, created int, err error) {
+	ctx, end := r.start(ctx, "CountLinks")
+	defer func() { end(err) }()
+	return r.repo.CountLinks(ctx, userID, since)
+}
+
+func (r *instrumented) Del(ctx context.Context, userID string, aliases []string) (err error) {
+	ctx, end := r.start(ctx, "Del")
+	defer func() { end(err) }()
+	return r.repo.Del(ctx, userID, aliases)
+}
+
+func (r *instrumented) DelBan(ctx context.Context, userID string) (err error) {
+	ctx, end := r.start(ctx, "DelBan")
+	defer func() { end(err) }()
+	return r.repo.DelBan(ctx, userID)
+}
+
+func (r *instrumented) DelQuota(ctx context.Context, userID string) (err error) {
+	ctx, end := r.start(ctx, "DelQuota")
+	defer func() { end(err) }()
+	return r.repo.DelQuota(ctx, userID)
+}
+
+func (r *instrumented) DelWebhook(ctx context.Context, userID string, id string) (err error) {
+	ctx, end := r.start(ctx, "DelWebhook")
+	defer func() { end(err) }()
+	return r.repo.DelWebhook(ctx, userID, id)
+}
+
+func (r *instrumented) Get(ctx context.Context, alias string) (_ *entity.URL, err error) {
+	ctx, end := r.start(ctx, "Get")
+	defer func() { end(err) }()
+	return r.repo.Get(ctx, alias)
+}
+
+func (r *instrumented) GetAPIKeyByHash(ctx context.Context, hash string) (_ *entity.APIKey, err error) {
+	ctx, end := r.start(ctx, "GetAPIKeyByHash")
+	defer func() { end(err) }()
+	return r.repo.GetAPIKeyByHash(ctx, hash)
+}
+
+func (r *instrumented) GetAPIKeys(ctx context.Context, userID string) (_ []*entity.APIKey, err error) {
+	ctx, end := r.start(ctx, "GetAPIKeys")
+	defer func() { end(err) }()
+	return r.repo.GetAPIKeys(ctx, userID)
+}
+
+func (r *instrumented) GetAccount(ctx context.Context, username string) (_ *entity.Account, err error) {
+	ctx, end := r.start(ctx, "GetAccount")
+	defer func() { end(err) }()
+	return r.repo.GetAccount(ctx, username)
+}
+
+func (r *instrumented) GetAccountByUserID(ctx context.Context, userID string) (_ *entity.Account, err error) {
+	ctx, end := r.start(ctx, "GetAccountByUserID")
+	defer func() { end(err) }()
+	return r.repo.GetAccountByUserID(ctx, userID)
+}
+
+func (r *instrumented) GetActive(ctx context.Context) (_ []*entity.URL, err error) {
+	ctx, end := r.start(ctx, "GetActive")
+	defer func() { end(err) }()
+	return r.repo.GetActive(ctx)
+}
+
+func (r *instrumented) GetAll(ctx context.Context, userID string, host string) (_ []*entity.URL, err error) {
+	ctx, end := r.start(ctx, "GetAll")
+	defer func() { end(err) }()
+	return r.repo.GetAll(ctx, userID, host)
+}
+
+func (r *instrumented) GetAudit(ctx context.Context, filter entity.AuditFilter) (_ []*entity.AuditEntry, err error) {
+	ctx, end := r.start(ctx, "GetAudit")
+	defer func() { end(err) }()
+	return r.repo.GetAudit(ctx, filter)
+}
+
+func (r *instrumented) GetBan(ctx context.Context, userID string) (_ *entity.Ban, err error) {
+	ctx, end := r.start(ctx, "GetBan")
+	defer func() { end(err) }()
+	return r.repo.GetBan(ctx, userID)
+}
+
+func (r *instrumented) GetDeliveries(ctx context.Context, userID string, webhookID string) (_ []*entity.Delivery, err error) {
+	ctx, end := r.start(ctx, "GetDeliveries")
+	defer func() { end(err) }()
+	return r.repo.GetDeliveries(ctx, userID, webhookID)
+}
+
+func (r *instrumented) GetIdentity(ctx context.Context, issuer, subject string) (_ *entity.Identity, err error) {
+	ctx, end := r.start(ctx, "GetIdentity")
+	defer func() { end(err) }()
+	return r.repo.GetIdentity(ctx, issuer, subject)
+}
+
+func (r *instrumented) GetQuota(ctx context.Context, userID string) (_ *entity.Quota, err error) {
+	ctx, end := r.start(ctx, "GetQuota")
+	defer func() { end(err) }()
+	return r.repo.GetQuota(ctx, userID)
+}
+
+func (r *instrumented) GetReport(ctx context.Context, id string) (_ *entity.Report, err error) {
+	ctx, end := r.start(ctx, "GetReport")
+	defer func() { end(err) }()
+	return r.repo.GetReport(ctx, id)
+}
+
+func (r *instrumented) GetReports(ctx context.Context, status string) (_ []*entity.Report, err error) {
+	ctx, end := r.start(ctx, "GetReports")
+	defer func() { end(err) }()
+	return r.repo.GetReports(ctx, status)
+}
+
+func (r *instrumented) GetSession(ctx context.Context, id string) (_ *entity.Session, err error) {
+	ctx, end := r.start(ctx, "GetSession")
+	defer func() { end(err) }()
+	return r.repo.GetSession(ctx, id)
+}
+
+func (r *instrumented) GetSessions(ctx context.Context, userID string) (_ []*entity.Session, err error) {
+	ctx, end := r.start(ctx, "GetSessions")
+	defer func() { end(err) }()
+	return r.repo.GetSessions(ctx, userID)
+}
+
+func (r *instrumented) GetStats(ctx context.Context) (_ []byte, err error) {
+	ctx, end := r.start(ctx, "GetStats")
+	defer func() { end(err) }()
+	return r.repo.GetStats(ctx)
+}
+
+func (r *instrumented) GetUserCounts(ctx context.Context) (_ []*entity.UserCount, err error) {
+	ctx, end := r.start(ctx, "GetUserCounts")
+	defer func() { end(err) }()
+	return r.repo.GetUserCounts(ctx)
+}
+
+func (r *instrumented) GetWebhooks(ctx context.Context, userID string) (_ []*entity.Webhook, err error) {
+	ctx, end := r.start(ctx, "GetWebhooks")
+	defer func() { end(err) }()
+	return r.repo.GetWebhooks(ctx, userID)
+}
+
+func (r *instrumented) Healthcheck() (_ bool, err error) {
+	defer r.observe("Healthcheck", time.Now(), &err)
+	return r.repo.Healthcheck()
+}
+
+func (r *instrumented) ListLinks(ctx context.Context, userID string, after string, limit int) (_ []*entity.URL, err error) {
+	ctx, end := r.start(ctx, "ListLinks")
+	defer func() { end(err) }()
+	return r.repo.ListLinks(ctx, userID, after, limit)
+}
+
+func (r *instrumented) Put(ctx context.Context, url string, alias string, userID string) (_ string, err error) {
+	ctx, end := r.start(ctx, "Put")
+	defer func() { end(err) }()
+	return r.repo.Put(ctx, url, alias, userID)
+}
+
+func (r *instrumented) PutAPIKey(ctx context.Context, key *entity.APIKey) (err error) {
+	ctx, end := r.start(ctx, "PutAPIKey")
+	defer func() { end(err) }()
+	return r.repo.PutAPIKey(ctx, key)
+}
+
+func (r *instrumented) PutAccount(ctx context.Context, account *entity.Account) (err error) {
+	ctx, end := r.start(ctx, "PutAccount")
+	defer func() { end(err) }()
+	return r.repo.PutAccount(ctx, account)
+}
+
+func (r *instrumented) PutAudit(ctx context.Context, entry *entity.AuditEntry) (err error) {
+	ctx, end := r.start(ctx, "PutAudit")
+	defer func() { end(err) }()
+	return r.repo.PutAudit(ctx, entry)
+}
+
+func (r *instrumented) PutBan(ctx context.Context, ban *entity.Ban) (err error) {
+	ctx, end := r.start(ctx, "PutBan")
+	defer func() { end(err) }()
+	return r.repo.PutBan(ctx, ban)
+}
+
+func (r *instrumented) PutBatchWithinQuota(ctx context.Context, urls, aliases []string, userID string, q entity.Quota, since time.Time) (_ []string, err error) {
+	ctx, end := r.start(ctx, "PutBatchWithinQuota")
+	defer func() { end(err) }()
+	return r.repo.PutBatchWithinQuota(ctx, urls, aliases, userID, q, since)
+}
+
+func (r *instrumented) PutDelivery(ctx context.Context, delivery *entity.Delivery) (err error) {
+	ctx, end := r.start(ctx, "PutDelivery")
+	defer func() { end(err) }()
+	return r.repo.PutDelivery(ctx, delivery)
+}
+
+func (r *instrumented) PutIdentity(ctx context.Context, identity *entity.Identity) (err error) {
+	ctx, end := r.start(ctx, "PutIdentity")
+	defer func() { end(err) }()
+	return r.repo.PutIdentity(ctx, identity)
+}
+
+func (r *instrumented) PutQuota(ctx context.Context, userID string, q entity.Quota) (err error) {
+	ctx, end := r.start(ctx, "PutQuota")
+	defer func() { end(err) }()
+	return r.repo.PutQuota(ctx, userID, q)
+}
+
+func (r *instrumented) PutRefreshToken(ctx context.Context, sessionID, hash string, expiresAt time.Time) (err error) {
+	ctx, end := r.start(ctx, "PutRefreshToken")
+	defer func() { end(err) }()
+	return r.repo.PutRefreshToken(ctx, sessionID, hash, expiresAt)
+}
+
+func (r *instrumented) PutReport(ctx context.Context, alias, reason string, at time.Time) (_ *entity.Report, err error) {
+	ctx, end := r.start(ctx, "PutReport")
+	defer func() { end(err) }()
+	return r.repo.PutReport(ctx, alias, reason, at)
+}
+
+func (r *instrumented) PutSession(ctx context.Context, session *entity.Session) (err error) {
+	ctx, end := r.start(ctx, "PutSession")
+	defer func() { end(err) }()
+	return r.repo.PutSession(ctx, session)
+}
+
+func (r *instrumented) PutWebhook(ctx context.Context, hook *entity.Webhook) (err error) {
+	ctx, end := r.start(ctx, "PutWebhook")
+	defer func() { end(err) }()
+	return r.repo.PutWebhook(ctx, hook)
+}
+
+func (r *instrumented) PutWithinQuota(ctx context.Context, url, alias, userID string, q entity.Quota, since time.Time) (_ string, err error) {
+	ctx, end := r.start(ctx, "PutWithinQuota")
+	defer func() { end(err) }()
+	return r.repo.PutWithinQuota(ctx, url, alias, userID, q, since)
+}
+
+func (r *instrumented) RevokeAPIKey(ctx context.Context, userID, id string, at time.Time) (err error) {
+	ctx, end := r.start(ctx, "RevokeAPIKey")
+	defer func() { end(err) }()
+	return r.repo.RevokeAPIKey(ctx, userID, id, at)
+}
+
+func (r *instrumented) RevokeSession(ctx context.Context, id string, at time.Time) (err error) {
+	ctx, end := r.start(ctx, "RevokeSession")
+	defer func() { end(err) }()
+	return r.repo.RevokeSession(ctx, id, at)
+}
+
+func (r *instrumented) SearchLinks(ctx context.Context, filter entity.LinkFilter) (_ []*entity.URL, err error) {
+	ctx, end := r.start(ctx, "SearchLinks")
+	defer func() { end(err) }()
+	return r.repo.SearchLinks(ctx, filter)
+}
+
+func (r *instrumented) SetDisabled(ctx context.Context, alias string, disabled bool) (err error) {
+	ctx, end := r.start(ctx, "SetDisabled")
+	defer func() { end(err) }()
+	return r.repo.SetDisabled(ctx, alias, disabled)
+}
+
+func (r *instrumented) SetStatus(ctx context.Context, alias string, status int, checkedAt time.Time) (err error) {
+	ctx, end := r.start(ctx, "SetStatus")
+	defer func() { end(err) }()
+	return r.repo.SetStatus(ctx, alias, status, checkedAt)
+}
+
+func (r *instrumented) TouchAPIKey(ctx context.Context, id string, at time.Time) (err error) {
+	ctx, end := r.start(ctx, "TouchAPIKey")
+	defer func() { end(err) }()
+	return r.repo.TouchAPIKey(ctx, id, at)
+}
+
+func (r *instrumented) UseRefreshToken(ctx context.Context, hash string, now time.Time, grace time.Duration) (_ string, err error) {
+	ctx, end := r.start(ctx, "UseRefreshToken")
+	defer func() { end(err) }()
+	return r.repo.UseRefreshToken(ctx, hash, now, grace)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/tracing"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+)
+
+type observation struct {
+	backend, operation string
+	err                error
+}
+
+type recordingObserver []observation
+
+func (o *recordingObserver) ObserveRepository(backend, operation string, _ time.Duration, err error) {
+	*o = append(*o, observation{backend: backend, operation: operation, err: err})
+}
+
+// recordingExporter keeps the exported spans past Shutdown, which resets an in-memory
+// exporter.
+type recordingExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestInstrument(t *testing.T) {
+	mock := NewMockRepository(gomock.NewController(t))
+	var observer recordingObserver
+	exporter := recordingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+	tracer := tracing.New(exporter, 1, "test")
+	repo := Instrument(mock, &observer, tracer)
+
+	mock.EXPECT().Get(gomock.Any(), "abc").Return(&entity.URL{Alias: "abc"}, nil)
+	mock.EXPECT().SetDisabled(gomock.Any(), "missing", true).Return(admin.ErrLinkNotFound)
+	mock.EXPECT().GetStats(gomock.Any()).Return(nil, assert.AnError)
+
+	url, err := repo.Get(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", url.Alias)
+	assert.ErrorIs(t, repo.SetDisabled(context.Background(), "missing", true), admin.ErrLinkNotFound)
+	_, err = repo.GetStats(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+
+	assert.Equal(t, recordingObserver{
+		{backend: "other", operation: "Get"},
+		{backend: "other", operation: "SetDisabled"},
+		{backend: "other", operation: "GetStats", err: assert.AnError},
+	}, observer)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "repository.Get", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("repository.backend", "other"))
+	assert.Equal(t, codes.Unset, spans[1].Status.Code, "expected errors do not fail the span")
+	assert.Equal(t, codes.Error, spans[2].Status.Code)
+
+	assert.Same(t, Repository(mock), Unwrap(repo))
+	assert.Equal(t, "other", Backend(repo))
+}
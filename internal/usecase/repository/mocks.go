@@ -7,6 +7,7 @@ package repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/nextlag/shortenerURL/internal/entity"
@@ -35,8 +36,53 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimLinks mocks base method.
+func (m *MockRepository) ClaimLinks(arg0 context.Context, arg1, arg2 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimLinks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimLinks indicates an expected call of ClaimLinks.
+func (mr *MockRepositoryMockRecorder) ClaimLinks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimLinks", reflect.TypeOf((*MockRepository)(nil).ClaimLinks), arg0, arg1, arg2)
+}
+
+// CloseReport mocks base method.
+func (m *MockRepository) CloseReport(arg0 context.Context, arg1, arg2, arg3 string, arg4 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseReport", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseReport indicates an expected call of CloseReport.
+func (mr *MockRepositoryMockRecorder) CloseReport(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReport", reflect.TypeOf((*MockRepository)(nil).CloseReport), arg0, arg1, arg2, arg3, arg4)
+}
+
+// CountLinks mocks base method.
+func (m *MockRepository) CountLinks(arg0 context.Context, arg1 string, arg2 time.Time) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountLinks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountLinks indicates an expected call of CountLinks.
+func (mr *MockRepositoryMockRecorder) CountLinks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountLinks", reflect.TypeOf((*MockRepository)(nil).CountLinks), arg0, arg1, arg2)
+}
+
 // Del mocks base method.
-func (m *MockRepository) Del(arg0 context.Context, arg1 int, arg2 []string) error {
+func (m *MockRepository) Del(arg0 context.Context, arg1 string, arg2 []string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Del", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
@@ -49,6 +95,48 @@ func (mr *MockRepositoryMockRecorder) Del(arg0, arg1, arg2 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockRepository)(nil).Del), arg0, arg1, arg2)
 }
 
+// DelBan mocks base method.
+func (m *MockRepository) DelBan(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelBan", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelBan indicates an expected call of DelBan.
+func (mr *MockRepositoryMockRecorder) DelBan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelBan", reflect.TypeOf((*MockRepository)(nil).DelBan), arg0, arg1)
+}
+
+// DelQuota mocks base method.
+func (m *MockRepository) DelQuota(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelQuota", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelQuota indicates an expected call of DelQuota.
+func (mr *MockRepositoryMockRecorder) DelQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelQuota", reflect.TypeOf((*MockRepository)(nil).DelQuota), arg0, arg1)
+}
+
+// DelWebhook mocks base method.
+func (m *MockRepository) DelWebhook(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelWebhook indicates an expected call of DelWebhook.
+func (mr *MockRepositoryMockRecorder) DelWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelWebhook", reflect.TypeOf((*MockRepository)(nil).DelWebhook), arg0, arg1, arg2)
+}
+
 // Get mocks base method.
 func (m *MockRepository) Get(arg0 context.Context, arg1 string) (*entity.URL, error) {
 	m.ctrl.T.Helper()
@@ -64,8 +152,83 @@ func (mr *MockRepositoryMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), arg0, arg1)
 }
 
+// GetAPIKeyByHash mocks base method.
+func (m *MockRepository) GetAPIKeyByHash(arg0 context.Context, arg1 string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockRepositoryMockRecorder) GetAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockRepository)(nil).GetAPIKeyByHash), arg0, arg1)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockRepository) GetAPIKeys(arg0 context.Context, arg1 string) ([]*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockRepositoryMockRecorder) GetAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockRepository)(nil).GetAPIKeys), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockRepository) GetAccount(arg0 context.Context, arg1 string) (*entity.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockRepositoryMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockRepository)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountByUserID mocks base method.
+func (m *MockRepository) GetAccountByUserID(arg0 context.Context, arg1 string) (*entity.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByUserID", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByUserID indicates an expected call of GetAccountByUserID.
+func (mr *MockRepositoryMockRecorder) GetAccountByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByUserID", reflect.TypeOf((*MockRepository)(nil).GetAccountByUserID), arg0, arg1)
+}
+
+// GetActive mocks base method.
+func (m *MockRepository) GetActive(arg0 context.Context) ([]*entity.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActive", arg0)
+	ret0, _ := ret[0].([]*entity.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActive indicates an expected call of GetActive.
+func (mr *MockRepositoryMockRecorder) GetActive(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActive", reflect.TypeOf((*MockRepository)(nil).GetActive), arg0)
+}
+
 // GetAll mocks base method.
-func (m *MockRepository) GetAll(arg0 context.Context, arg1 int, arg2 string) ([]*entity.URL, error) {
+func (m *MockRepository) GetAll(arg0 context.Context, arg1, arg2 string) ([]*entity.URL, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAll", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*entity.URL)
@@ -79,6 +242,141 @@ func (mr *MockRepositoryMockRecorder) GetAll(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRepository)(nil).GetAll), arg0, arg1, arg2)
 }
 
+// GetAudit mocks base method.
+func (m *MockRepository) GetAudit(arg0 context.Context, arg1 entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAudit", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAudit indicates an expected call of GetAudit.
+func (mr *MockRepositoryMockRecorder) GetAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAudit", reflect.TypeOf((*MockRepository)(nil).GetAudit), arg0, arg1)
+}
+
+// GetBan mocks base method.
+func (m *MockRepository) GetBan(arg0 context.Context, arg1 string) (*entity.Ban, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBan", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Ban)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBan indicates an expected call of GetBan.
+func (mr *MockRepositoryMockRecorder) GetBan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBan", reflect.TypeOf((*MockRepository)(nil).GetBan), arg0, arg1)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockRepository) GetDeliveries(arg0 context.Context, arg1, arg2 string) ([]*entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockRepositoryMockRecorder) GetDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockRepository)(nil).GetDeliveries), arg0, arg1, arg2)
+}
+
+// GetIdentity mocks base method.
+func (m *MockRepository) GetIdentity(arg0 context.Context, arg1, arg2 string) (*entity.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentity", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentity indicates an expected call of GetIdentity.
+func (mr *MockRepositoryMockRecorder) GetIdentity(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentity", reflect.TypeOf((*MockRepository)(nil).GetIdentity), arg0, arg1, arg2)
+}
+
+// GetQuota mocks base method.
+func (m *MockRepository) GetQuota(arg0 context.Context, arg1 string) (*entity.Quota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuota", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuota indicates an expected call of GetQuota.
+func (mr *MockRepositoryMockRecorder) GetQuota(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuota", reflect.TypeOf((*MockRepository)(nil).GetQuota), arg0, arg1)
+}
+
+// GetReport mocks base method.
+func (m *MockRepository) GetReport(arg0 context.Context, arg1 string) (*entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport.
+func (mr *MockRepositoryMockRecorder) GetReport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockRepository)(nil).GetReport), arg0, arg1)
+}
+
+// GetReports mocks base method.
+func (m *MockRepository) GetReports(arg0 context.Context, arg1 string) ([]*entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReports", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReports indicates an expected call of GetReports.
+func (mr *MockRepositoryMockRecorder) GetReports(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReports", reflect.TypeOf((*MockRepository)(nil).GetReports), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockRepository) GetSession(arg0 context.Context, arg1 string) (*entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockRepositoryMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockRepository)(nil).GetSession), arg0, arg1)
+}
+
+// GetSessions mocks base method.
+func (m *MockRepository) GetSessions(arg0 context.Context, arg1 string) ([]*entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockRepositoryMockRecorder) GetSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockRepository)(nil).GetSessions), arg0, arg1)
+}
+
 // GetStats mocks base method.
 func (m *MockRepository) GetStats(arg0 context.Context) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +392,36 @@ func (mr *MockRepositoryMockRecorder) GetStats(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockRepository)(nil).GetStats), arg0)
 }
 
+// GetUserCounts mocks base method.
+func (m *MockRepository) GetUserCounts(arg0 context.Context) ([]*entity.UserCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCounts", arg0)
+	ret0, _ := ret[0].([]*entity.UserCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCounts indicates an expected call of GetUserCounts.
+func (mr *MockRepositoryMockRecorder) GetUserCounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCounts", reflect.TypeOf((*MockRepository)(nil).GetUserCounts), arg0)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockRepository) GetWebhooks(arg0 context.Context, arg1 string) ([]*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockRepositoryMockRecorder) GetWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockRepository)(nil).GetWebhooks), arg0, arg1)
+}
+
 // Healthcheck mocks base method.
 func (m *MockRepository) Healthcheck() (bool, error) {
 	m.ctrl.T.Helper()
@@ -109,8 +437,23 @@ func (mr *MockRepositoryMockRecorder) Healthcheck() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Healthcheck", reflect.TypeOf((*MockRepository)(nil).Healthcheck))
 }
 
+// ListLinks mocks base method.
+func (m *MockRepository) ListLinks(arg0 context.Context, arg1, arg2 string, arg3 int) ([]*entity.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*entity.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockRepositoryMockRecorder) ListLinks(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockRepository)(nil).ListLinks), arg0, arg1, arg2, arg3)
+}
+
 // Put mocks base method.
-func (m *MockRepository) Put(arg0 context.Context, arg1, arg2 string, arg3 int) (string, error) {
+func (m *MockRepository) Put(arg0 context.Context, arg1, arg2, arg3 string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Put", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(string)
@@ -123,3 +466,288 @@ func (mr *MockRepositoryMockRecorder) Put(arg0, arg1, arg2, arg3 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockRepository)(nil).Put), arg0, arg1, arg2, arg3)
 }
+
+// PutAPIKey mocks base method.
+func (m *MockRepository) PutAPIKey(arg0 context.Context, arg1 *entity.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutAPIKey indicates an expected call of PutAPIKey.
+func (mr *MockRepositoryMockRecorder) PutAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutAPIKey", reflect.TypeOf((*MockRepository)(nil).PutAPIKey), arg0, arg1)
+}
+
+// PutAccount mocks base method.
+func (m *MockRepository) PutAccount(arg0 context.Context, arg1 *entity.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutAccount indicates an expected call of PutAccount.
+func (mr *MockRepositoryMockRecorder) PutAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutAccount", reflect.TypeOf((*MockRepository)(nil).PutAccount), arg0, arg1)
+}
+
+// PutAudit mocks base method.
+func (m *MockRepository) PutAudit(arg0 context.Context, arg1 *entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutAudit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutAudit indicates an expected call of PutAudit.
+func (mr *MockRepositoryMockRecorder) PutAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutAudit", reflect.TypeOf((*MockRepository)(nil).PutAudit), arg0, arg1)
+}
+
+// PutBan mocks base method.
+func (m *MockRepository) PutBan(arg0 context.Context, arg1 *entity.Ban) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBan", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBan indicates an expected call of PutBan.
+func (mr *MockRepositoryMockRecorder) PutBan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBan", reflect.TypeOf((*MockRepository)(nil).PutBan), arg0, arg1)
+}
+
+// PutBatchWithinQuota mocks base method.
+func (m *MockRepository) PutBatchWithinQuota(arg0 context.Context, arg1, arg2 []string, arg3 string, arg4 entity.Quota, arg5 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBatchWithinQuota", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutBatchWithinQuota indicates an expected call of PutBatchWithinQuota.
+func (mr *MockRepositoryMockRecorder) PutBatchWithinQuota(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBatchWithinQuota", reflect.TypeOf((*MockRepository)(nil).PutBatchWithinQuota), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// PutDelivery mocks base method.
+func (m *MockRepository) PutDelivery(arg0 context.Context, arg1 *entity.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutDelivery indicates an expected call of PutDelivery.
+func (mr *MockRepositoryMockRecorder) PutDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutDelivery", reflect.TypeOf((*MockRepository)(nil).PutDelivery), arg0, arg1)
+}
+
+// PutIdentity mocks base method.
+func (m *MockRepository) PutIdentity(arg0 context.Context, arg1 *entity.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutIdentity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutIdentity indicates an expected call of PutIdentity.
+func (mr *MockRepositoryMockRecorder) PutIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutIdentity", reflect.TypeOf((*MockRepository)(nil).PutIdentity), arg0, arg1)
+}
+
+// PutQuota mocks base method.
+func (m *MockRepository) PutQuota(arg0 context.Context, arg1 string, arg2 entity.Quota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutQuota", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutQuota indicates an expected call of PutQuota.
+func (mr *MockRepositoryMockRecorder) PutQuota(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutQuota", reflect.TypeOf((*MockRepository)(nil).PutQuota), arg0, arg1, arg2)
+}
+
+// PutRefreshToken mocks base method.
+func (m *MockRepository) PutRefreshToken(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutRefreshToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutRefreshToken indicates an expected call of PutRefreshToken.
+func (mr *MockRepositoryMockRecorder) PutRefreshToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutRefreshToken", reflect.TypeOf((*MockRepository)(nil).PutRefreshToken), arg0, arg1, arg2, arg3)
+}
+
+// PutReport mocks base method.
+func (m *MockRepository) PutReport(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (*entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutReport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutReport indicates an expected call of PutReport.
+func (mr *MockRepositoryMockRecorder) PutReport(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutReport", reflect.TypeOf((*MockRepository)(nil).PutReport), arg0, arg1, arg2, arg3)
+}
+
+// PutSession mocks base method.
+func (m *MockRepository) PutSession(arg0 context.Context, arg1 *entity.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutSession indicates an expected call of PutSession.
+func (mr *MockRepositoryMockRecorder) PutSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSession", reflect.TypeOf((*MockRepository)(nil).PutSession), arg0, arg1)
+}
+
+// PutWebhook mocks base method.
+func (m *MockRepository) PutWebhook(arg0 context.Context, arg1 *entity.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutWebhook indicates an expected call of PutWebhook.
+func (mr *MockRepositoryMockRecorder) PutWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutWebhook", reflect.TypeOf((*MockRepository)(nil).PutWebhook), arg0, arg1)
+}
+
+// PutWithinQuota mocks base method.
+func (m *MockRepository) PutWithinQuota(arg0 context.Context, arg1, arg2, arg3 string, arg4 entity.Quota, arg5 time.Time) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutWithinQuota", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutWithinQuota indicates an expected call of PutWithinQuota.
+func (mr *MockRepositoryMockRecorder) PutWithinQuota(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutWithinQuota", reflect.TypeOf((*MockRepository)(nil).PutWithinQuota), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockRepository) RevokeAPIKey(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockRepositoryMockRecorder) RevokeAPIKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockRepository)(nil).RevokeAPIKey), arg0, arg1, arg2, arg3)
+}
+
+// RevokeSession mocks base method.
+func (m *MockRepository) RevokeSession(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockRepositoryMockRecorder) RevokeSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockRepository)(nil).RevokeSession), arg0, arg1, arg2)
+}
+
+// SearchLinks mocks base method.
+func (m *MockRepository) SearchLinks(arg0 context.Context, arg1 entity.LinkFilter) ([]*entity.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchLinks", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchLinks indicates an expected call of SearchLinks.
+func (mr *MockRepositoryMockRecorder) SearchLinks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchLinks", reflect.TypeOf((*MockRepository)(nil).SearchLinks), arg0, arg1)
+}
+
+// SetDisabled mocks base method.
+func (m *MockRepository) SetDisabled(arg0 context.Context, arg1 string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDisabled", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDisabled indicates an expected call of SetDisabled.
+func (mr *MockRepositoryMockRecorder) SetDisabled(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDisabled", reflect.TypeOf((*MockRepository)(nil).SetDisabled), arg0, arg1, arg2)
+}
+
+// SetStatus mocks base method.
+func (m *MockRepository) SetStatus(arg0 context.Context, arg1 string, arg2 int, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockRepositoryMockRecorder) SetStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockRepository)(nil).SetStatus), arg0, arg1, arg2, arg3)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockRepository) TouchAPIKey(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockRepositoryMockRecorder) TouchAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockRepository)(nil).TouchAPIKey), arg0, arg1, arg2)
+}
+
+// UseRefreshToken mocks base method.
+func (m *MockRepository) UseRefreshToken(arg0 context.Context, arg1 string, arg2 time.Time, arg3 time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRefreshToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRefreshToken indicates an expected call of UseRefreshToken.
+func (mr *MockRepositoryMockRecorder) UseRefreshToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRefreshToken", reflect.TypeOf((*MockRepository)(nil).UseRefreshToken), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,122 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const (
+	createAccounts = `CREATE TABLE IF NOT EXISTS accounts (
+		uuid VARCHAR(36) PRIMARY KEY,
+		username VARCHAR(32) NOT NULL UNIQUE,
+		password_hash VARCHAR(60) NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);`
+	createIdentities = `CREATE TABLE IF NOT EXISTS identities (
+		issuer VARCHAR NOT NULL,
+		subject VARCHAR NOT NULL,
+		uuid VARCHAR(36) NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (issuer, subject)
+	);`
+	insertIdentity     = `INSERT INTO identities (issuer, subject, uuid, created_at) VALUES ($1, $2, $3, $4);`
+	getIdentity        = `SELECT issuer, subject, uuid, created_at FROM identities WHERE issuer = $1 AND subject = $2;`
+	insertAccount      = `INSERT INTO accounts (uuid, username, password_hash, created_at) VALUES ($1, $2, $3, $4);`
+	getAccount         = `SELECT uuid, username, password_hash, created_at FROM accounts WHERE username = $1;`
+	getAccountByUserID = `SELECT uuid, username, password_hash, created_at FROM accounts WHERE uuid = $1;`
+	claimLinks         = `UPDATE short_urls s SET uuid = $2 WHERE s.uuid = $1 AND s.del IS NOT TRUE AND NOT EXISTS (
+		SELECT 1 FROM short_urls t WHERE t.uuid = $2 AND (t.url = s.url OR t.canonical_url = s.canonical_url)
+	);`
+)
+
+// createAccountTables creates the account and identity tables.
+func (r *Repo) createAccountTables(ctx context.Context) error {
+	for _, query := range []string{createAccounts, createIdentities} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create account tables query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// PutAccount stores a new account.
+func (r *Repo) PutAccount(ctx context.Context, account *entity.Account) error {
+	_, err := r.DB.ExecContext(ctx, insertAccount, account.UserID, account.Username, account.PasswordHash, account.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return auth.ErrUsernameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert account: %w", err)
+	}
+	return nil
+}
+
+// GetAccount retrieves an account by its username.
+func (r *Repo) GetAccount(ctx context.Context, username string) (*entity.Account, error) {
+	return r.scanAccount(r.DB.QueryRowContext(ctx, getAccount, username))
+}
+
+// GetAccountByUserID retrieves the account owning a user ID.
+func (r *Repo) GetAccountByUserID(ctx context.Context, userID string) (*entity.Account, error) {
+	return r.scanAccount(r.DB.QueryRowContext(ctx, getAccountByUserID, userID))
+}
+
+// PutIdentity stores a new identity.
+func (r *Repo) PutIdentity(ctx context.Context, identity *entity.Identity) error {
+	_, err := r.DB.ExecContext(ctx, insertIdentity, identity.Issuer, identity.Subject, identity.UserID, identity.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return auth.ErrIdentityExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert identity: %w", err)
+	}
+	return nil
+}
+
+// GetIdentity retrieves the identity of a subject of an issuer.
+func (r *Repo) GetIdentity(ctx context.Context, issuer, subject string) (*entity.Identity, error) {
+	var identity entity.Identity
+	err := r.DB.QueryRowContext(ctx, getIdentity, issuer, subject).
+		Scan(&identity.Issuer, &identity.Subject, &identity.UserID, &identity.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrIdentityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select identity: %w", err)
+	}
+	return &identity, nil
+}
+
+// ClaimLinks moves the links of one user to another, except those whose URL the
+// other user already shortened.
+func (r *Repo) ClaimLinks(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	res, err := r.DB.ExecContext(ctx, claimLinks, fromUserID, toUserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim links: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// scanAccount scans a row of getAccount or getAccountByUserID.
+func (r *Repo) scanAccount(row *sql.Row) (*entity.Account, error) {
+	var account entity.Account
+	err := row.Scan(&account.UserID, &account.Username, &account.PasswordHash, &account.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select account: %w", err)
+	}
+	return &account, nil
+}
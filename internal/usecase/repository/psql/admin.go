@@ -0,0 +1,144 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const (
+	addDisabled = `ALTER TABLE short_urls ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false;`
+	createBans  = `CREATE TABLE IF NOT EXISTS bans (
+		uuid VARCHAR(36) PRIMARY KEY,
+		reason VARCHAR NOT NULL DEFAULT '',
+		banned_by VARCHAR(36) NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);`
+	searchLinks = `SELECT uuid, url, alias, created_at, del, disabled, last_status, last_checked_at FROM short_urls
+		WHERE ($1 = '' OR alias LIKE '%' || $1 || '%' ESCAPE '\' OR url LIKE '%' || $1 || '%' ESCAPE '\') AND ($2 = '' OR uuid = $2)
+		ORDER BY alias LIMIT $3 OFFSET $4;`
+	setDisabled = `UPDATE short_urls SET disabled = $1 WHERE alias = $2;`
+	userCounts  = `SELECT s.uuid,
+		COUNT(*) FILTER (WHERE s.del IS NOT TRUE),
+		COUNT(*) FILTER (WHERE s.del IS TRUE),
+		COUNT(*) FILTER (WHERE s.del IS NOT TRUE AND s.disabled),
+		b.uuid IS NOT NULL
+		FROM short_urls s LEFT JOIN bans b ON b.uuid = s.uuid
+		GROUP BY s.uuid, b.uuid ORDER BY s.uuid;`
+	upsertBan = `INSERT INTO bans (uuid, reason, banned_by, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (uuid) DO UPDATE SET reason = EXCLUDED.reason, banned_by = EXCLUDED.banned_by, created_at = EXCLUDED.created_at;`
+	deleteBan = `DELETE FROM bans WHERE uuid = $1;`
+	getBan    = `SELECT uuid, reason, banned_by, created_at FROM bans WHERE uuid = $1;`
+)
+
+// createAdminTables adds the disabled column of links and creates the bans table.
+func (r *Repo) createAdminTables(ctx context.Context) error {
+	for _, query := range []string{addDisabled, createBans} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create admin tables query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchLinks retrieves the links of all users matching the filter, deleted ones included.
+// The query matches literally; LIKE wildcards in it are escaped.
+func (r *Repo) SearchLinks(ctx context.Context, filter entity.LinkFilter) ([]*entity.URL, error) {
+	query := likeEscaper.Replace(filter.Query)
+	rows, err := r.DB.QueryContext(ctx, searchLinks, query, filter.UserID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search links: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []*entity.URL
+	for rows.Next() {
+		var url entity.URL
+		var createdAt, checkedAt sql.NullTime
+		var deleted sql.NullBool
+		var status sql.NullInt64
+		err = rows.Scan(&url.UUID, &url.URL, &url.Alias, &createdAt, &deleted, &url.Disabled, &status, &checkedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		url.CreatedAt = createdAt.Time
+		url.IsDeleted = deleted.Bool
+		url.LastStatus = int(status.Int64)
+		url.LastCheckedAt = checkedAt.Time
+		urls = append(urls, &url)
+	}
+	return urls, rows.Err()
+}
+
+// SetDisabled disables or enables the redirect of a link.
+func (r *Repo) SetDisabled(ctx context.Context, alias string, disabled bool) error {
+	res, err := r.DB.ExecContext(ctx, setDisabled, disabled, alias)
+	if err != nil {
+		return fmt.Errorf("failed to update link: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return admin.ErrLinkNotFound
+	}
+	return nil
+}
+
+// GetUserCounts counts the links of every user and marks the banned ones.
+func (r *Repo) GetUserCounts(ctx context.Context) ([]*entity.UserCount, error) {
+	rows, err := r.DB.QueryContext(ctx, userCounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count links: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*entity.UserCount
+	for rows.Next() {
+		var count entity.UserCount
+		if err = rows.Scan(&count.UserID, &count.Links, &count.Deleted, &count.Disabled, &count.Banned); err != nil {
+			return nil, fmt.Errorf("failed to scan link count: %w", err)
+		}
+		counts = append(counts, &count)
+	}
+	return counts, rows.Err()
+}
+
+// PutBan stores the ban of a user, replacing an earlier one.
+func (r *Repo) PutBan(ctx context.Context, ban *entity.Ban) error {
+	if _, err := r.DB.ExecContext(ctx, upsertBan, ban.UserID, ban.Reason, ban.BannedBy, ban.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert ban: %w", err)
+	}
+	return nil
+}
+
+// DelBan lifts the ban of a user.
+func (r *Repo) DelBan(ctx context.Context, userID string) error {
+	res, err := r.DB.ExecContext(ctx, deleteBan, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ban: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return auth.ErrBanNotFound
+	}
+	return nil
+}
+
+// GetBan retrieves the ban of a user.
+func (r *Repo) GetBan(ctx context.Context, userID string) (*entity.Ban, error) {
+	var ban entity.Ban
+	err := r.DB.QueryRowContext(ctx, getBan, userID).Scan(&ban.UserID, &ban.Reason, &ban.BannedBy, &ban.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrBanNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban: %w", err)
+	}
+	return &ban, nil
+}
@@ -0,0 +1,136 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const (
+	createAPIKeys = `CREATE TABLE IF NOT EXISTS api_keys (
+		id VARCHAR(36) PRIMARY KEY,
+		uuid VARCHAR(36) NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		prefix VARCHAR(16) NOT NULL,
+		hash VARCHAR(64) NOT NULL UNIQUE,
+		scopes TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		last_used_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	);`
+	createAPIKeysUUIDIndex = `CREATE INDEX IF NOT EXISTS api_keys_uuid_idx ON api_keys (uuid);`
+	addAPIKeyQuota         = `ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS max_links INTEGER, ADD COLUMN IF NOT EXISTS max_daily_links INTEGER;`
+	insertAPIKey           = `INSERT INTO api_keys (id, uuid, name, prefix, hash, scopes, created_at, max_links, max_daily_links) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+	apiKeyColumns          = `id, uuid, name, prefix, hash, scopes, created_at, last_used_at, revoked_at, max_links, max_daily_links`
+	getAPIKeys             = `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE uuid = $1 ORDER BY created_at;`
+	getAPIKeyByHash        = `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE hash = $1;`
+	revokeAPIKey           = `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3) WHERE id = $1 AND uuid = $2;`
+	touchAPIKey            = `UPDATE api_keys SET last_used_at = $2 WHERE id = $1;`
+)
+
+// createAPIKeyTable creates the API key table.
+func (r *Repo) createAPIKeyTable(ctx context.Context) error {
+	for _, query := range []string{createAPIKeys, createAPIKeysUUIDIndex, addAPIKeyQuota} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create API key table query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// PutAPIKey stores a new API key. Scopes are stored comma separated, and the limits of
+// keys without a quota are NULL.
+func (r *Repo) PutAPIKey(ctx context.Context, key *entity.APIKey) error {
+	var maxLinks, maxDailyLinks sql.NullInt64
+	if key.Quota != nil {
+		maxLinks = sql.NullInt64{Int64: int64(key.Quota.MaxLinks), Valid: true}
+		maxDailyLinks = sql.NullInt64{Int64: int64(key.Quota.MaxDailyLinks), Valid: true}
+	}
+	_, err := r.DB.ExecContext(ctx, insertAPIKey, key.ID, key.UserID, key.Name, key.Prefix, key.Hash,
+		strings.Join(key.Scopes, ","), key.CreatedAt, maxLinks, maxDailyLinks)
+	if err != nil {
+		return fmt.Errorf("failed to insert API key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeys retrieves the API keys of a user.
+func (r *Repo) GetAPIKeys(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	rows, err := r.DB.QueryContext(ctx, getAPIKeys, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*entity.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+func (r *Repo) GetAPIKeyByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	key, err := scanAPIKey(r.DB.QueryRowContext(ctx, getAPIKeyByHash, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select API key: %w", err)
+	}
+	return key, nil
+}
+
+// RevokeAPIKey marks an API key of the user as revoked.
+func (r *Repo) RevokeAPIKey(ctx context.Context, userID, id string, at time.Time) error {
+	res, err := r.DB.ExecContext(ctx, revokeAPIKey, id, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return auth.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey sets the last-used time of an API key.
+func (r *Repo) TouchAPIKey(ctx context.Context, id string, at time.Time) error {
+	if _, err := r.DB.ExecContext(ctx, touchAPIKey, id, at); err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+	return nil
+}
+
+// scanAPIKey scans a row of getAPIKeys or getAPIKeyByHash.
+func scanAPIKey(row interface{ Scan(...any) error }) (*entity.APIKey, error) {
+	var key entity.APIKey
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+	var maxLinks, maxDailyLinks sql.NullInt64
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &scopes,
+		&key.CreatedAt, &lastUsedAt, &revokedAt, &maxLinks, &maxDailyLinks)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = strings.Split(scopes, ",")
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	if maxLinks.Valid || maxDailyLinks.Valid {
+		key.Quota = &entity.Quota{MaxLinks: int(maxLinks.Int64), MaxDailyLinks: int(maxDailyLinks.Int64)}
+	}
+	return &key, nil
+}
@@ -0,0 +1,87 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+const (
+	createAudit = `CREATE TABLE IF NOT EXISTS audit_log (
+		id VARCHAR(36) PRIMARY KEY,
+		action VARCHAR(32) NOT NULL,
+		alias VARCHAR NOT NULL DEFAULT '',
+		target VARCHAR NOT NULL DEFAULT '',
+		actor VARCHAR(36) NOT NULL DEFAULT '',
+		transport VARCHAR(16) NOT NULL,
+		request_id VARCHAR NOT NULL DEFAULT '',
+		client_ip VARCHAR NOT NULL DEFAULT '',
+		before JSONB,
+		after JSONB,
+		at TIMESTAMP NOT NULL
+	);`
+	createAuditAliasIndex = `CREATE INDEX IF NOT EXISTS audit_log_alias_at_idx ON audit_log (alias, at);`
+	createAuditActorIndex = `CREATE INDEX IF NOT EXISTS audit_log_actor_at_idx ON audit_log (actor, at);`
+	createAuditAtIndex    = `CREATE INDEX IF NOT EXISTS audit_log_at_idx ON audit_log (at);`
+	insertAudit           = `INSERT INTO audit_log (id, action, alias, target, actor, transport, request_id, client_ip, before, after, at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`
+	getAudit = `SELECT id, action, alias, target, actor, transport, request_id, client_ip, before, after, at FROM audit_log
+		WHERE ($1 = '' OR alias = $1) AND ($2 = '' OR actor = $2)
+		AND ($3::TIMESTAMP IS NULL OR at >= $3) AND ($4::TIMESTAMP IS NULL OR at < $4)
+		ORDER BY at DESC LIMIT $5 OFFSET $6;`
+)
+
+// createAuditTable creates the table of the audit log with the indexes of its queries.
+func (r *Repo) createAuditTable(ctx context.Context) error {
+	for _, query := range []string{createAudit, createAuditAliasIndex, createAuditActorIndex, createAuditAtIndex} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create audit table query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// PutAudit appends an entry to the audit log.
+func (r *Repo) PutAudit(ctx context.Context, entry *entity.AuditEntry) error {
+	_, err := r.DB.ExecContext(ctx, insertAudit, entry.ID, entry.Action, entry.Alias, entry.Target, entry.Actor,
+		entry.Transport, entry.RequestID, entry.ClientIP, jsonb(entry.Before), jsonb(entry.After), entry.At)
+	if err != nil {
+		return fmt.Errorf("failed to store audit entry: %w", err)
+	}
+	return nil
+}
+
+// GetAudit retrieves the entries matching the filter, the latest first.
+func (r *Repo) GetAudit(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	from := sql.NullTime{Time: filter.From.Local(), Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To.Local(), Valid: !filter.To.IsZero()}
+	rows, err := r.DB.QueryContext(ctx, getAudit, filter.Alias, filter.Actor, from, to, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*entity.AuditEntry
+	for rows.Next() {
+		var entry entity.AuditEntry
+		var before, after []byte
+		err = rows.Scan(&entry.ID, &entry.Action, &entry.Alias, &entry.Target, &entry.Actor, &entry.Transport,
+			&entry.RequestID, &entry.ClientIP, &before, &after, &entry.At)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// jsonb converts a snapshot to a JSONB parameter, NULL for no snapshot.
+func jsonb(snapshot []byte) any {
+	if len(snapshot) == 0 {
+		return nil
+	}
+	return string(snapshot)
+}
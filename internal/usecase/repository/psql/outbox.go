@@ -0,0 +1,134 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
+)
+
+// outboxLock is the advisory lock key that keeps relays of several instances
+// from publishing the same events concurrently.
+const outboxLock = 0x6f7574626f78
+
+const (
+	createOutbox = `CREATE TABLE IF NOT EXISTS outbox (
+		id BIGSERIAL PRIMARY KEY,
+		event_id VARCHAR(36) NOT NULL,
+		event_type VARCHAR(64) NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);`
+	insertOutbox = `INSERT INTO outbox (event_id, event_type, payload, created_at) VALUES ($1, $2, $3, $4);`
+	lockOutbox   = `SELECT pg_try_advisory_xact_lock($1);`
+	getOutbox    = `SELECT id, payload FROM outbox ORDER BY id LIMIT $1;`
+	deleteOutbox = `DELETE FROM outbox WHERE id = ANY($1);`
+)
+
+// outboxEvent decodes events recorded before user IDs became UUIDs.
+type outboxEvent struct {
+	entity.Event
+	UserID userid.Compat `json:"user_id"`
+}
+
+// createOutboxTable creates the table of unpublished link events.
+func (r *Repo) createOutboxTable(ctx context.Context) error {
+	if _, err := r.DB.ExecContext(ctx, createOutbox); err != nil {
+		return fmt.Errorf("exec create outbox table query, err=%v", err)
+	}
+	return nil
+}
+
+// addEvent records a link event in the outbox as part of tx.
+func addEvent(ctx context.Context, tx *sql.Tx, eventType, userID, alias, url string) error {
+	event := entity.Event{
+		ID:         generatestring.GenerateUUID(),
+		Type:       eventType,
+		UserID:     userID,
+		Alias:      alias,
+		URL:        url,
+		OccurredAt: time.Now(),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, insertOutbox, event.ID, event.Type, string(payload), event.OccurredAt); err != nil {
+		return fmt.Errorf("failed to insert event into outbox: %w", err)
+	}
+	return nil
+}
+
+// ProcessOutbox passes up to limit unpublished events, oldest first, to handle and removes
+// the handled ones. Only one instance processes the outbox at a time; the others get 0.
+func (r *Repo) ProcessOutbox(ctx context.Context, limit int, handle func(entity.Event) error) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin outbox transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err = tx.QueryRowContext(ctx, lockOutbox, outboxLock).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to lock outbox: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	ids, events, err := r.readOutbox(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var handleErr error
+	published := make([]int64, 0, len(ids))
+	for i, event := range events {
+		if handleErr = handle(event); handleErr != nil {
+			break
+		}
+		published = append(published, ids[i])
+	}
+
+	if len(published) > 0 {
+		if _, err = tx.ExecContext(ctx, deleteOutbox, published); err != nil {
+			return 0, fmt.Errorf("failed to delete published events: %w", err)
+		}
+		if err = tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to commit outbox transaction: %w", err)
+		}
+	}
+	return len(published), handleErr
+}
+
+// readOutbox selects up to limit events in the order they were recorded.
+func (r *Repo) readOutbox(ctx context.Context, tx *sql.Tx, limit int) ([]int64, []entity.Event, error) {
+	rows, err := tx.QueryContext(ctx, getOutbox, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to select outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var events []entity.Event
+	for rows.Next() {
+		var id int64
+		var payload string
+		if err = rows.Scan(&id, &payload); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		var event outboxEvent
+		if err = json.Unmarshal([]byte(payload), &event); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode outbox event %d: %w", id, err)
+		}
+		event.Event.UserID = string(event.UserID)
+		ids = append(ids, id)
+		events = append(events, event.Event)
+	}
+	return ids, events, rows.Err()
+}
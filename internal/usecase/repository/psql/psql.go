@@ -16,7 +16,9 @@ import (
 
 	"github.com/nextlag/shortenerURL/internal/configuration"
 	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/models"
+	"github.com/nextlag/shortenerURL/pkg/tools/canonicalurl"
 	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
 )
 
@@ -61,7 +63,7 @@ const (
 	pingTimeout         = time.Second * 3
 	createTablesTimeout = time.Second * 5
 	createTable         = `CREATE TABLE IF NOT EXISTS short_urls (
-		uuid INT,
+		uuid VARCHAR(36),
 		url VARCHAR NOT NULL,
 		alias VARCHAR(255) NOT NULL,
 		created_at TIMESTAMP,
@@ -69,11 +71,25 @@ const (
 		PRIMARY KEY (uuid, alias),
 		UNIQUE (uuid, url)
 	);`
-	insert       = `INSERT INTO short_urls (uuid, url, alias, created_at, del) VALUES ($1, $2, $3, $4, false);`
-	get          = `SELECT uuid, url, alias, created_at, del FROM short_urls WHERE alias = $1;`
-	getConflict  = `SELECT alias FROM short_urls WHERE url = $1;`
+	addCanonical = `ALTER TABLE short_urls ADD COLUMN IF NOT EXISTS canonical_url VARCHAR;`
+	getUncanon   = `SELECT alias, url FROM short_urls WHERE canonical_url IS NULL;`
+	setCanonical = `UPDATE short_urls SET canonical_url = $1 WHERE alias = $2;`
+	uniqueCanon  = `CREATE UNIQUE INDEX IF NOT EXISTS short_urls_uuid_canonical_url ON short_urls (uuid, canonical_url);`
+	addStatus    = `ALTER TABLE short_urls ADD COLUMN IF NOT EXISTS last_status INT, ADD COLUMN IF NOT EXISTS last_checked_at TIMESTAMP;`
+	getActive    = `SELECT alias, url, last_status, last_checked_at FROM short_urls WHERE del IS NOT TRUE;`
+	listLinks    = `SELECT url, alias, disabled, created_at, last_status, last_checked_at FROM short_urls
+		WHERE uuid = $1 AND del IS NOT TRUE AND alias > $2 ORDER BY alias LIMIT $3;`
+	setStatus    = `UPDATE short_urls SET last_status = $1, last_checked_at = $2 WHERE alias = $3;`
+	insert       = `INSERT INTO short_urls (uuid, url, canonical_url, alias, created_at, del) VALUES ($1, $2, $3, $4, $5, false);`
+	get          = `SELECT uuid, url, alias, created_at, del, disabled FROM short_urls WHERE alias = $1;`
+	deleteURLs   = `UPDATE short_urls SET del = true WHERE alias = ANY($1) AND uuid = $2 AND del IS NOT TRUE RETURNING alias;`
+	getConflict  = `SELECT alias FROM short_urls WHERE canonical_url = $1 AND uuid = $2;`
 	getUrlsStats = `SELECT COUNT(*) as urlsCount FROM short_urls;`
 	getUserStats = `SELECT COUNT(DISTINCT uuid) as uniqueUsers FROM short_urls;`
+	splitCanon   = `UPDATE short_urls SET canonical_url = canonical_url || ' duplicate:' || alias
+		WHERE (uuid, alias) IN (SELECT uuid, alias FROM (
+			SELECT uuid, alias, ROW_NUMBER() OVER (PARTITION BY uuid, canonical_url ORDER BY created_at NULLS LAST, alias) AS n
+			FROM short_urls WHERE canonical_url IS NOT NULL) AS ranked WHERE n > 1);`
 )
 
 // Stop closes the connection to the database.
@@ -93,17 +109,109 @@ func (r *Repo) Healthcheck() (bool, error) {
 	return true, nil
 }
 
-// CreateTable creates the short_urls table in the database.
+// CreateTable creates the short_urls, webhook, session, account, ban and outbox tables in the database and
+// brings older tables up to date with the canonical URL, liveness status and disabled columns and UUID user IDs.
 func (r *Repo) CreateTable(ctx context.Context) error {
 	_, err := r.DB.ExecContext(ctx, createTable)
 	if err != nil {
 		return fmt.Errorf("exec create table query, err=%v", err)
 	}
+	if _, err = r.DB.ExecContext(ctx, addCanonical); err != nil {
+		return fmt.Errorf("exec add canonical column query, err=%v", err)
+	}
+	if _, err = r.DB.ExecContext(ctx, addStatus); err != nil {
+		return fmt.Errorf("exec add status columns query, err=%v", err)
+	}
+	if err = r.backfillCanonical(ctx); err != nil {
+		return err
+	}
+	if _, err = r.DB.ExecContext(ctx, uniqueCanon); err != nil {
+		return fmt.Errorf("failed to create unique index on short_urls (uuid, canonical_url), "+
+			"check for links of a user with the same canonical_url: %w", err)
+	}
+	if err = r.createWebhookTables(ctx); err != nil {
+		return err
+	}
+	if err = r.migrateUserIDs(ctx); err != nil {
+		return err
+	}
+	if err = r.createSessionTables(ctx); err != nil {
+		return err
+	}
+	if err = r.createAPIKeyTable(ctx); err != nil {
+		return err
+	}
+	if err = r.createAccountTables(ctx); err != nil {
+		return err
+	}
+	if err = r.createAdminTables(ctx); err != nil {
+		return err
+	}
+	if err = r.createRateLimitTable(ctx); err != nil {
+		return err
+	}
+	if err = r.createQuotaTable(ctx); err != nil {
+		return err
+	}
+	if err = r.createReportTable(ctx); err != nil {
+		return err
+	}
+	if err = r.createAuditTable(ctx); err != nil {
+		return err
+	}
+	return r.createOutboxTable(ctx)
+}
+
+// backfillCanonical fills canonical_url for rows created before the column existed and resolves
+// the links of a user whose canonical URLs collide, so that the unique index can be created.
+func (r *Repo) backfillCanonical(ctx context.Context) error {
+	rows, err := r.DB.QueryContext(ctx, getUncanon)
+	if err != nil {
+		return fmt.Errorf("failed to select rows without canonical URL: %w", err)
+	}
+	defer rows.Close()
+
+	canonical := make(map[string]string)
+	for rows.Next() {
+		var alias, url string
+		if err = rows.Scan(&alias, &url); err != nil {
+			return fmt.Errorf("failed to scan row without canonical URL: %w", err)
+		}
+		canonical[alias] = canonicalurl.Key(url)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for alias, url := range canonical {
+		if _, err = r.DB.ExecContext(ctx, setCanonical, url, alias); err != nil {
+			return fmt.Errorf("failed to set canonical URL: %w", err)
+		}
+	}
+
+	// Rows stored before canonicalization may collide once canonicalized. The oldest link of
+	// the user keeps the canonical URL, so that new puts resolve to it, and the others keep
+	// serving their aliases with a suffix no canonical URL contains.
+	res, err := r.DB.ExecContext(ctx, splitCanon)
+	if err != nil {
+		return fmt.Errorf("failed to resolve duplicate canonical URLs: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		r.log.Info("resolved links with duplicate canonical URLs", zap.Int64("links", n))
+	}
 	return nil
 }
 
 // Put adds a new short URL to the database.
-func (r *Repo) Put(ctx context.Context, url string, alias string, userID int) (string, error) {
+func (r *Repo) Put(ctx context.Context, url string, alias string, userID string) (string, error) {
+	return r.PutWithinQuota(ctx, url, alias, userID, entity.Quota{}, time.Time{})
+}
+
+// PutWithinQuota saves a URL as Put does, unless the link would exceed the quota of the user.
+// Links created since the time given count against the daily limit. Puts of the user are
+// serialized by an advisory lock while the links are counted, so that concurrent puts
+// cannot exceed the quota together.
+func (r *Repo) PutWithinQuota(ctx context.Context, url, alias, userID string, q entity.Quota, since time.Time) (string, error) {
 	if alias == "" {
 		alias = generatestring.NewRandomString(8)
 	}
@@ -121,12 +229,34 @@ func (r *Repo) Put(ctx context.Context, url string, alias string, userID int) (s
 		IsDeleted: false,
 	}
 
-	_, err := r.DB.ExecContext(ctx, insert, shortURL.UUID, shortURL.URL, shortURL.Alias, shortURL.IsDeleted, shortURL.CreatedAt)
+	canonical := canonicalurl.Key(url)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return alias, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if !q.Unlimited() {
+		err = checkQuota(ctx, tx, userID, q, since)
+		if errors.Is(err, quota.ErrExceeded) {
+			// A duplicate of an existing link costs nothing, so report the conflict instead.
+			var existingAlias string
+			if r.DB.QueryRowContext(ctx, getConflict, canonical, userID).Scan(&existingAlias) == nil {
+				return existingAlias, ErrConflict
+			}
+		}
+		if err != nil {
+			return alias, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, insert, shortURL.UUID, shortURL.URL, canonical, shortURL.Alias, shortURL.CreatedAt)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
 			var existingAlias string
-			err = r.DB.QueryRowContext(ctx, getConflict, url).Scan(&existingAlias)
+			err = r.DB.QueryRowContext(ctx, getConflict, canonical, userID).Scan(&existingAlias)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) {
 					return alias, ErrConflict
@@ -137,13 +267,77 @@ func (r *Repo) Put(ctx context.Context, url string, alias string, userID int) (s
 		}
 		return alias, fmt.Errorf("failed to insert short URL into database: %w", err)
 	}
+	if err = addEvent(ctx, tx, entity.EventLinkCreated, userID, alias, url); err != nil {
+		return alias, err
+	}
+	if err = tx.Commit(); err != nil {
+		return alias, fmt.Errorf("failed to commit short URL: %w", err)
+	}
 	return alias, nil
 }
 
+// PutBatchWithinQuota saves the URLs of the user with the aliases at the same index as
+// PutWithinQuota does, all or none. The batch runs in one transaction holding the advisory
+// lock of the quota of the user, so that its new links are counted against the quota
+// together. URLs the user already shortened keep their alias.
+func (r *Repo) PutBatchWithinQuota(ctx context.Context, urls, aliases []string, userID string, q entity.Quota, since time.Time) ([]string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, lockQuota, userID); err != nil {
+		return nil, fmt.Errorf("failed to lock quota: %w", err)
+	}
+
+	stored := make([]string, len(urls))
+	seen := make(map[string]string) // alias by canonical URL
+	var n int
+	for i, url := range urls {
+		canonical := canonicalurl.Key(url)
+		alias, ok := seen[canonical]
+		if !ok {
+			err = tx.QueryRowContext(ctx, getConflict, canonical, userID).Scan(&alias)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				alias = aliases[i]
+				n++
+			case err != nil:
+				return nil, fmt.Errorf("failed to query existing alias: %w", err)
+			}
+			seen[canonical] = alias
+		}
+		stored[i] = alias
+	}
+	if !q.Unlimited() {
+		if err = checkLocked(ctx, tx, userID, q, since, n); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for i, url := range urls {
+		if stored[i] != aliases[i] {
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, insert, userID, url, canonicalurl.Key(url), aliases[i], now); err != nil {
+			return nil, fmt.Errorf("failed to insert short URL into database: %w", err)
+		}
+		if err = addEvent(ctx, tx, entity.EventLinkCreated, userID, aliases[i], url); err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit short URLs: %w", err)
+	}
+	return stored, nil
+}
+
 // Get retrieves a URL by its alias.
 func (r *Repo) Get(ctx context.Context, alias string) (*entity.URL, error) {
 	var url entity.URL
-	err := r.DB.QueryRowContext(ctx, get, alias).Scan(&url.UUID, &url.URL, &url.Alias, &url.CreatedAt, &url.IsDeleted)
+	err := r.DB.QueryRowContext(ctx, get, alias).Scan(&url.UUID, &url.URL, &url.Alias, &url.CreatedAt, &url.IsDeleted, &url.Disabled)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("no URL found for alias %s", alias)
@@ -154,13 +348,13 @@ func (r *Repo) Get(ctx context.Context, alias string) (*entity.URL, error) {
 }
 
 // GetAll retrieves all URLs for a specific user.
-func (r *Repo) GetAll(ctx context.Context, userID int, host string) ([]*entity.URL, error) {
+func (r *Repo) GetAll(ctx context.Context, userID string, host string) ([]*entity.URL, error) {
 	var urls []*entity.URL
 	DB := bun.NewDB(r.DB, pgdialect.New())
 
 	rows, err := DB.NewSelect().
 		TableExpr("short_urls").
-		Column("url", "alias", "del", "created_at").
+		Column("url", "alias", "del", "disabled", "created_at", "last_status", "last_checked_at").
 		Where("uuid = ?", userID).
 		Rows(ctx)
 	if err != nil {
@@ -171,11 +365,15 @@ func (r *Repo) GetAll(ctx context.Context, userID int, host string) ([]*entity.U
 
 	for rows.Next() {
 		var url entity.URL
-		if err = rows.Scan(&url.URL, &url.Alias, &url.IsDeleted, &url.CreatedAt); err != nil {
+		var status sql.NullInt64
+		var checkedAt sql.NullTime
+		if err = rows.Scan(&url.URL, &url.Alias, &url.IsDeleted, &url.Disabled, &url.CreatedAt, &status, &checkedAt); err != nil {
 			r.log.Error("Error scanning data: ", zap.Error(err))
 			return nil, err
 		}
 		url.Alias = fmt.Sprintf("%s/%s", host, url.Alias)
+		url.LastStatus = int(status.Int64)
+		url.LastCheckedAt = checkedAt.Time
 		urls = append(urls, &url)
 	}
 
@@ -187,22 +385,98 @@ func (r *Repo) GetAll(ctx context.Context, userID int, host string) ([]*entity.U
 	return urls, nil
 }
 
-// Del removes URLs for a user with a specific ID.
-func (r *Repo) Del(ctx context.Context, userID int, aliases []string) error {
-	DB := bun.NewDB(r.DB, pgdialect.New())
+// ListLinks retrieves a page of the links of a user that are not deleted, ordered by
+// alias: at most limit links with an alias after the given one.
+func (r *Repo) ListLinks(ctx context.Context, userID string, after string, limit int) ([]*entity.URL, error) {
+	rows, err := r.DB.QueryContext(ctx, listLinks, userID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+	defer rows.Close()
 
-	_, err := DB.NewUpdate().
-		TableExpr("short_urls").
-		Set("del = ?", true).
-		Where("alias IN (?)", bun.In(aliases)).
-		Where("uuid = ?", userID).
-		Exec(ctx)
+	var urls []*entity.URL
+	for rows.Next() {
+		url := entity.URL{UUID: userID}
+		var createdAt, checkedAt sql.NullTime
+		var status sql.NullInt64
+		if err = rows.Scan(&url.URL, &url.Alias, &url.Disabled, &createdAt, &status, &checkedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		url.CreatedAt = createdAt.Time
+		url.LastStatus = int(status.Int64)
+		url.LastCheckedAt = checkedAt.Time
+		urls = append(urls, &url)
+	}
+	return urls, rows.Err()
+}
+
+// GetActive retrieves all non-deleted URLs of all users.
+func (r *Repo) GetActive(ctx context.Context) ([]*entity.URL, error) {
+	rows, err := r.DB.QueryContext(ctx, getActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select active URLs: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []*entity.URL
+	for rows.Next() {
+		var url entity.URL
+		var status sql.NullInt64
+		var checkedAt sql.NullTime
+		if err = rows.Scan(&url.Alias, &url.URL, &status, &checkedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan active URL: %w", err)
+		}
+		url.LastStatus = int(status.Int64)
+		url.LastCheckedAt = checkedAt.Time
+		urls = append(urls, &url)
+	}
+	return urls, rows.Err()
+}
+
+// SetStatus records the result of a liveness check of the URL behind alias.
+func (r *Repo) SetStatus(ctx context.Context, alias string, status int, checkedAt time.Time) error {
+	if _, err := r.DB.ExecContext(ctx, setStatus, status, checkedAt, alias); err != nil {
+		return fmt.Errorf("failed to update URL status: %w", err)
+	}
+	return nil
+}
 
+// Del removes URLs for a user with a specific ID and records a deletion event
+// for every URL that was not deleted before.
+func (r *Repo) Del(ctx context.Context, userID string, aliases []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, deleteURLs, aliases, userID)
 	if err != nil {
 		r.log.Error("Can't exec update request: ", zap.Error(err))
 		return fmt.Errorf("failed to update URLs: %w", err)
 	}
+	var deleted []string
+	for rows.Next() {
+		var alias string
+		if err = rows.Scan(&alias); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan deleted alias: %w", err)
+		}
+		deleted = append(deleted, alias)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to update URLs: %w", err)
+	}
 
+	for _, alias := range deleted {
+		if err = addEvent(ctx, tx, entity.EventLinkDeleted, userID, alias, ""); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deletion: %w", err)
+	}
 	return nil
 }
 
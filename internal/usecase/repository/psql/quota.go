@@ -0,0 +1,98 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+)
+
+const (
+	createQuotas = `CREATE TABLE IF NOT EXISTS quotas (
+		uuid VARCHAR(36) PRIMARY KEY,
+		max_links INTEGER NOT NULL,
+		max_daily_links INTEGER NOT NULL
+	);`
+	upsertQuota = `INSERT INTO quotas (uuid, max_links, max_daily_links) VALUES ($1, $2, $3)
+		ON CONFLICT (uuid) DO UPDATE SET max_links = EXCLUDED.max_links, max_daily_links = EXCLUDED.max_daily_links;`
+	getQuota    = `SELECT max_links, max_daily_links FROM quotas WHERE uuid = $1;`
+	deleteQuota = `DELETE FROM quotas WHERE uuid = $1;`
+	lockQuota   = `SELECT pg_advisory_xact_lock(hashtext('quota:' || $1));`
+	countLinks  = `SELECT COUNT(*) FILTER (WHERE del IS NOT TRUE), COUNT(*) FILTER (WHERE created_at >= $2)
+		FROM short_urls WHERE uuid = $1;`
+)
+
+// createQuotaTable creates the table of the quota overrides of users.
+func (r *Repo) createQuotaTable(ctx context.Context) error {
+	if _, err := r.DB.ExecContext(ctx, createQuotas); err != nil {
+		return fmt.Errorf("exec create quota table query, err=%v", err)
+	}
+	return nil
+}
+
+// PutQuota stores the quota override of a user.
+func (r *Repo) PutQuota(ctx context.Context, userID string, q entity.Quota) error {
+	if _, err := r.DB.ExecContext(ctx, upsertQuota, userID, q.MaxLinks, q.MaxDailyLinks); err != nil {
+		return fmt.Errorf("failed to store quota: %w", err)
+	}
+	return nil
+}
+
+// GetQuota retrieves the quota override of a user.
+func (r *Repo) GetQuota(ctx context.Context, userID string) (*entity.Quota, error) {
+	var q entity.Quota
+	err := r.DB.QueryRowContext(ctx, getQuota, userID).Scan(&q.MaxLinks, &q.MaxDailyLinks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, quota.ErrQuotaNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select quota: %w", err)
+	}
+	return &q, nil
+}
+
+// DelQuota removes the quota override of a user.
+func (r *Repo) DelQuota(ctx context.Context, userID string) error {
+	res, err := r.DB.ExecContext(ctx, deleteQuota, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete quota: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return quota.ErrQuotaNotFound
+	}
+	return nil
+}
+
+// CountLinks counts the links of the user that are not deleted and the links it created since.
+func (r *Repo) CountLinks(ctx context.Context, userID string, since time.Time) (int, int, error) {
+	var links, created int
+	// created_at holds the local time of the server without its zone.
+	err := r.DB.QueryRowContext(ctx, countLinks, userID, since.Local()).Scan(&links, &created)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count links: %w", err)
+	}
+	return links, created, nil
+}
+
+// checkQuota locks the quota of the user until the end of the transaction and checks
+// that one more link fits into it.
+func checkQuota(ctx context.Context, tx *sql.Tx, userID string, q entity.Quota, since time.Time) error {
+	if _, err := tx.ExecContext(ctx, lockQuota, userID); err != nil {
+		return fmt.Errorf("failed to lock quota: %w", err)
+	}
+	return checkLocked(ctx, tx, userID, q, since, 1)
+}
+
+// checkLocked checks that n more links fit into the quota of the user, whose lock the
+// transaction holds.
+func checkLocked(ctx context.Context, tx *sql.Tx, userID string, q entity.Quota, since time.Time, n int) error {
+	var links, created int
+	if err := tx.QueryRowContext(ctx, countLinks, userID, since.Local()).Scan(&links, &created); err != nil {
+		return fmt.Errorf("failed to count links: %w", err)
+	}
+	return quota.Check(q, links, created, n)
+}
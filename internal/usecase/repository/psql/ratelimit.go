@@ -0,0 +1,67 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/middleware/ratelimit"
+)
+
+// rateLimitTTL is the age of the untouched buckets that are removed when new buckets
+// are created. Removed buckets come back full, which they are by then for any sane limit.
+const rateLimitTTL = 24 * time.Hour
+
+const (
+	createRateLimits = `CREATE TABLE IF NOT EXISTS rate_limits (
+		key VARCHAR PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);`
+	insertBucket       = `INSERT INTO rate_limits (key, tokens, updated_at) VALUES ($1, $2, $3) ON CONFLICT (key) DO NOTHING;`
+	selectBucket       = `SELECT tokens, updated_at FROM rate_limits WHERE key = $1 FOR UPDATE;`
+	updateBucket       = `UPDATE rate_limits SET tokens = $1, updated_at = $2 WHERE key = $3;`
+	deleteStaleBuckets = `DELETE FROM rate_limits WHERE updated_at < $1;`
+)
+
+// createRateLimitTable creates the table of the rate limit buckets.
+func (r *Repo) createRateLimitTable(ctx context.Context) error {
+	if _, err := r.DB.ExecContext(ctx, createRateLimits); err != nil {
+		return fmt.Errorf("exec create rate limit table query, err=%v", err)
+	}
+	return nil
+}
+
+// TakeToken takes a token from the bucket of the key. The bucket row is locked for the
+// take, so that the instances sharing the database share the limit too.
+func (r *Repo) TakeToken(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	now := time.Now().UTC()
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, insertBucket, key, float64(limit.Burst), now)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to insert bucket: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		if _, err = tx.ExecContext(ctx, deleteStaleBuckets, now.Add(-rateLimitTTL)); err != nil {
+			return ratelimit.Result{}, fmt.Errorf("failed to delete stale buckets: %w", err)
+		}
+	}
+
+	var b ratelimit.Bucket
+	if err = tx.QueryRowContext(ctx, selectBucket, key).Scan(&b.Tokens, &b.UpdatedAt); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to select bucket: %w", err)
+	}
+	result := b.Take(limit, now)
+	if _, err = tx.ExecContext(ctx, updateBucket, b.Tokens, b.UpdatedAt, key); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to update bucket: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("failed to commit bucket: %w", err)
+	}
+	return result, nil
+}
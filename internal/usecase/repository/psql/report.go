@@ -0,0 +1,120 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+)
+
+const (
+	createReports = `CREATE TABLE IF NOT EXISTS reports (
+		id VARCHAR(36) PRIMARY KEY,
+		alias VARCHAR NOT NULL,
+		reason TEXT NOT NULL,
+		count INTEGER NOT NULL DEFAULT 1,
+		status VARCHAR(16) NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		last_reported_at TIMESTAMP NOT NULL,
+		reviewed_by VARCHAR(36) NOT NULL DEFAULT '',
+		reviewed_at TIMESTAMP
+	);`
+	// createOpenReportIndex allows one open report per link, which repeat reports are merged into.
+	createOpenReportIndex = `CREATE UNIQUE INDEX IF NOT EXISTS reports_open_alias_idx ON reports (alias) WHERE status = 'open';`
+	addLastReason         = `ALTER TABLE reports ADD COLUMN IF NOT EXISTS last_reason TEXT NOT NULL DEFAULT '';`
+	fillLastReason        = `UPDATE reports SET last_reason = reason WHERE last_reason = '';`
+	reportColumns         = `id, alias, reason, last_reason, count, status, created_at, last_reported_at, reviewed_by, reviewed_at`
+	upsertReport          = `INSERT INTO reports (id, alias, reason, last_reason, count, status, created_at, last_reported_at)
+		VALUES ($1, $2, $3, $3, 1, 'open', $4, $4)
+		ON CONFLICT (alias) WHERE status = 'open'
+		DO UPDATE SET count = reports.count + 1, last_reason = EXCLUDED.last_reason, last_reported_at = EXCLUDED.last_reported_at
+		RETURNING ` + reportColumns + `;`
+	getReports  = `SELECT ` + reportColumns + ` FROM reports WHERE $1 = '' OR status = $1 ORDER BY last_reported_at DESC;`
+	getReport   = `SELECT ` + reportColumns + ` FROM reports WHERE id = $1;`
+	closeReport = `UPDATE reports SET status = $2, reviewed_by = $3, reviewed_at = $4 WHERE id = $1 AND status = 'open';`
+)
+
+// createReportTable creates the table of abuse reports.
+func (r *Repo) createReportTable(ctx context.Context) error {
+	for _, query := range []string{createReports, createOpenReportIndex, addLastReason, fillLastReason} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create report table query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// PutReport files a report against the link, merging it into the open report of the link.
+func (r *Repo) PutReport(ctx context.Context, alias, reason string, at time.Time) (*entity.Report, error) {
+	rep, err := scanReport(r.DB.QueryRowContext(ctx, upsertReport, generatestring.GenerateUUID(), alias, reason, at))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store report: %w", err)
+	}
+	return rep, nil
+}
+
+// GetReports retrieves the reports with the status, the latest reported first.
+func (r *Repo) GetReports(ctx context.Context, status string) ([]*entity.Report, error) {
+	rows, err := r.DB.QueryContext(ctx, getReports, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*entity.Report
+	for rows.Next() {
+		rep, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, rep)
+	}
+	return reports, rows.Err()
+}
+
+// GetReport retrieves a report by its ID.
+func (r *Repo) GetReport(ctx context.Context, id string) (*entity.Report, error) {
+	rep, err := scanReport(r.DB.QueryRowContext(ctx, getReport, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, report.ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select report: %w", err)
+	}
+	return rep, nil
+}
+
+// CloseReport sets the status of an open report.
+func (r *Repo) CloseReport(ctx context.Context, id, status, reviewedBy string, at time.Time) error {
+	res, err := r.DB.ExecContext(ctx, closeReport, id, status, reviewedBy, at)
+	if err != nil {
+		return fmt.Errorf("failed to close report: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	if _, err = r.GetReport(ctx, id); err != nil {
+		return err
+	}
+	return report.ErrReportClosed
+}
+
+// scanReport scans a row of reportColumns.
+func scanReport(row interface{ Scan(...any) error }) (*entity.Report, error) {
+	var rep entity.Report
+	var reviewedAt sql.NullTime
+	err := row.Scan(&rep.ID, &rep.Alias, &rep.Reason, &rep.LastReason, &rep.Count, &rep.Status, &rep.CreatedAt,
+		&rep.LastReportedAt, &rep.ReviewedBy, &reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		rep.ReviewedAt = &reviewedAt.Time
+	}
+	return &rep, nil
+}
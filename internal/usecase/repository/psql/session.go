@@ -0,0 +1,168 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+)
+
+const (
+	createSessions = `CREATE TABLE IF NOT EXISTS sessions (
+		id VARCHAR(36) PRIMARY KEY,
+		uuid VARCHAR(36) NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		revoked_at TIMESTAMP
+	);`
+	createRefreshTokens = `CREATE TABLE IF NOT EXISTS refresh_tokens (
+		hash VARCHAR(64) PRIMARY KEY,
+		session_id VARCHAR(36) NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		expires_at TIMESTAMP NOT NULL,
+		used BOOLEAN NOT NULL DEFAULT false
+	);`
+	insertSession    = `INSERT INTO sessions (id, uuid, created_at, expires_at) VALUES ($1, $2, $3, $4);`
+	getSession       = `SELECT id, uuid, created_at, expires_at, revoked_at FROM sessions WHERE id = $1;`
+	getSessions      = `SELECT id, uuid, created_at, expires_at, revoked_at FROM sessions WHERE uuid = $1 ORDER BY created_at;`
+	revokeSession    = `UPDATE sessions SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1;`
+	extendSession    = `UPDATE sessions SET expires_at = $2 WHERE id = $1;`
+	insertRefresh    = `INSERT INTO refresh_tokens (hash, session_id, expires_at) VALUES ($1, $2, $3);`
+	addRefreshUsedAt = `ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS used_at TIMESTAMP;`
+	useRefresh       = `UPDATE refresh_tokens SET used = true, used_at = $2 WHERE hash = $1 AND used = false AND expires_at > $2 RETURNING session_id;`
+	getRefresh       = `SELECT session_id, used, used_at FROM refresh_tokens WHERE hash = $1 AND expires_at > $2;`
+	deleteExpiredRTs = `DELETE FROM refresh_tokens WHERE expires_at <= $1;`
+)
+
+// createSessionTables creates the session and refresh token tables.
+func (r *Repo) createSessionTables(ctx context.Context) error {
+	for _, query := range []string{createSessions, createRefreshTokens, addRefreshUsedAt} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create session tables query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// PutSession stores a new session.
+func (r *Repo) PutSession(ctx context.Context, session *entity.Session) error {
+	_, err := r.DB.ExecContext(ctx, insertSession, session.ID, session.UserID, session.CreatedAt, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+	return nil
+}
+
+// GetSession retrieves a session by its ID.
+func (r *Repo) GetSession(ctx context.Context, id string) (*entity.Session, error) {
+	session, err := scanSession(r.DB.QueryRowContext(ctx, getSession, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select session: %w", err)
+	}
+	return session, nil
+}
+
+// GetSessions retrieves the sessions of a user.
+func (r *Repo) GetSessions(ctx context.Context, userID string) ([]*entity.Session, error) {
+	rows, err := r.DB.QueryContext(ctx, getSessions, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*entity.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession marks a session as revoked.
+func (r *Repo) RevokeSession(ctx context.Context, id string, at time.Time) error {
+	res, err := r.DB.ExecContext(ctx, revokeSession, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return auth.ErrSessionNotFound
+	}
+	return nil
+}
+
+// PutRefreshToken adds a refresh token to a session and extends the session until expiresAt.
+// Expired refresh tokens are removed on the way.
+func (r *Repo) PutRefreshToken(ctx context.Context, sessionID, hash string, expiresAt time.Time) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, extendSession, sessionID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to extend session: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return auth.ErrSessionNotFound
+	}
+	if _, err = tx.ExecContext(ctx, insertRefresh, hash, sessionID, expiresAt); err != nil {
+		return fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, deleteExpiredRTs, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	return tx.Commit()
+}
+
+// UseRefreshToken atomically marks an unused, unexpired refresh token as used and returns its session.
+// A token used less than grace before now also returns its session.
+func (r *Repo) UseRefreshToken(ctx context.Context, hash string, now time.Time, grace time.Duration) (string, error) {
+	var sessionID string
+	err := r.DB.QueryRowContext(ctx, useRefresh, hash, now).Scan(&sessionID)
+	if err == nil {
+		return sessionID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to use refresh token: %w", err)
+	}
+
+	var used bool
+	var usedAt sql.NullTime
+	err = r.DB.QueryRowContext(ctx, getRefresh, hash, now).Scan(&sessionID, &used, &usedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", auth.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to select refresh token: %w", err)
+	}
+	if used && usedAt.Valid && now.Sub(usedAt.Time) < grace {
+		return sessionID, nil
+	}
+	if used {
+		return sessionID, auth.ErrRefreshTokenReused
+	}
+	return "", auth.ErrInvalidRefreshToken
+}
+
+// scanSession scans a row of getSession or getSessions.
+func scanSession(row interface{ Scan(...any) error }) (*entity.Session, error) {
+	var session entity.Session
+	var revokedAt sql.NullTime
+	if err := row.Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
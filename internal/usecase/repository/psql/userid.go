@@ -0,0 +1,75 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/pkg/tools/userid"
+)
+
+const (
+	getUUIDType = `SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name = 'uuid';`
+	getLegacyIDs = `SELECT DISTINCT uuid FROM %s;`
+	alterUUID    = `ALTER TABLE %s ALTER COLUMN uuid TYPE VARCHAR(36) USING uuid::text;`
+	setUUID      = `UPDATE %s SET uuid = $1 WHERE uuid = $2;`
+)
+
+// migrateUserIDs converts the integer user IDs of tables created by earlier versions
+// to the UUIDs that userid.FromLegacy derives from them.
+func (r *Repo) migrateUserIDs(ctx context.Context) error {
+	for _, table := range []string{"short_urls", "webhooks"} {
+		var dataType string
+		if err := r.DB.QueryRowContext(ctx, getUUIDType, table).Scan(&dataType); err != nil {
+			return fmt.Errorf("failed to get user ID type of %s: %w", table, err)
+		}
+		if dataType != "integer" {
+			continue
+		}
+		if err := r.migrateTableUserIDs(ctx, table); err != nil {
+			return fmt.Errorf("failed to migrate user IDs of %s: %w", table, err)
+		}
+		r.log.Info("migrated legacy user IDs", zap.String("table", table))
+	}
+	return nil
+}
+
+// migrateTableUserIDs changes the type of the uuid column of table and maps its values in one transaction.
+func (r *Repo) migrateTableUserIDs(ctx context.Context, table string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(getLegacyIDs, table))
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf(alterUUID, table)); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(setUUID, table), userid.FromLegacy(id), strconv.Itoa(id)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
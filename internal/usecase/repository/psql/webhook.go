@@ -0,0 +1,138 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
+)
+
+const (
+	createWebhooks = `CREATE TABLE IF NOT EXISTS webhooks (
+		id VARCHAR(36) PRIMARY KEY,
+		uuid VARCHAR(36) NOT NULL,
+		url VARCHAR NOT NULL,
+		secret VARCHAR NOT NULL,
+		events VARCHAR NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL
+	);`
+	createDeliveries = `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id VARCHAR(36) PRIMARY KEY,
+		webhook_id VARCHAR(36) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+		event_id VARCHAR(36) NOT NULL,
+		event_type VARCHAR(64) NOT NULL,
+		payload TEXT NOT NULL,
+		attempt INT NOT NULL,
+		status INT NOT NULL,
+		error VARCHAR NOT NULL DEFAULT '',
+		dead_letter BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMP NOT NULL
+	);`
+	insertWebhook  = `INSERT INTO webhooks (id, uuid, url, secret, events, created_at) VALUES ($1, $2, $3, $4, $5, $6);`
+	getWebhooks    = `SELECT id, uuid, url, secret, events, created_at FROM webhooks WHERE uuid = $1 ORDER BY created_at;`
+	deleteWebhook  = `DELETE FROM webhooks WHERE id = $1 AND uuid = $2;`
+	insertDelivery = `INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, attempt, status, error, dead_letter, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+	getDeliveries = `SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempt, d.status, d.error, d.dead_letter, d.created_at
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.uuid = $2 ORDER BY d.created_at DESC LIMIT 100;`
+	existsWebhook = `SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1 AND uuid = $2);`
+)
+
+// createWebhookTables creates the webhook registration and delivery log tables.
+func (r *Repo) createWebhookTables(ctx context.Context) error {
+	for _, query := range []string{createWebhooks, createDeliveries} {
+		if _, err := r.DB.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec create webhook tables query, err=%v", err)
+		}
+	}
+	return nil
+}
+
+// PutWebhook stores a webhook registration.
+func (r *Repo) PutWebhook(ctx context.Context, hook *entity.Webhook) error {
+	_, err := r.DB.ExecContext(ctx, insertWebhook,
+		hook.ID, hook.UserID, hook.URL, hook.Secret, strings.Join(hook.Events, ","), hook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhooks retrieves the webhooks registered by the user.
+func (r *Repo) GetWebhooks(ctx context.Context, userID string) ([]*entity.Webhook, error) {
+	rows, err := r.DB.QueryContext(ctx, getWebhooks, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []*entity.Webhook
+	for rows.Next() {
+		var hook entity.Webhook
+		var events string
+		if err = rows.Scan(&hook.ID, &hook.UserID, &hook.URL, &hook.Secret, &events, &hook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		if events != "" {
+			hook.Events = strings.Split(events, ",")
+		}
+		hooks = append(hooks, &hook)
+	}
+	return hooks, rows.Err()
+}
+
+// DelWebhook removes a webhook registered by the user together with its delivery log.
+func (r *Repo) DelWebhook(ctx context.Context, userID string, id string) error {
+	res, err := r.DB.ExecContext(ctx, deleteWebhook, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+// PutDelivery appends a delivery attempt to the log of its webhook.
+func (r *Repo) PutDelivery(ctx context.Context, d *entity.Delivery) error {
+	_, err := r.DB.ExecContext(ctx, insertDelivery,
+		d.ID, d.WebhookID, d.EventID, d.EventType, string(d.Payload), d.Attempt, d.Status, d.Error, d.DeadLetter, d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries retrieves the latest deliveries of a webhook registered by the user, newest first.
+func (r *Repo) GetDeliveries(ctx context.Context, userID string, webhookID string) ([]*entity.Delivery, error) {
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, existsWebhook, webhookID, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check webhook: %w", err)
+	}
+	if !exists {
+		return nil, webhook.ErrNotFound
+	}
+
+	rows, err := r.DB.QueryContext(ctx, getDeliveries, webhookID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.Delivery
+	for rows.Next() {
+		d := entity.Delivery{UserID: userID}
+		var payload sql.RawBytes
+		if err = rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Attempt,
+			&d.Status, &d.Error, &d.DeadLetter, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.Payload = append([]byte(nil), payload...)
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
@@ -3,13 +3,20 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/nextlag/shortenerURL/internal/configuration"
 	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository/psql"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
 )
 
 // Repository represents the interface for data storage.
@@ -17,11 +24,25 @@ import (
 //go:generate mockgen -destination=mocks.go -package=repository github.com/nextlag/shortenerURL/internal/usecase/repository Repository
 type Repository interface {
 	Get(ctx context.Context, alias string) (*entity.URL, error)
-	GetAll(ctx context.Context, userID int, host string) ([]*entity.URL, error)
-	Put(ctx context.Context, url string, alias string, userID int) (string, error)
-	Del(ctx context.Context, userID int, aliases []string) error
+	GetAll(ctx context.Context, userID string, host string) ([]*entity.URL, error)
+	ListLinks(ctx context.Context, userID string, after string, limit int) ([]*entity.URL, error)
+	Put(ctx context.Context, url string, alias string, userID string) (string, error)
+	Del(ctx context.Context, userID string, aliases []string) error
 	Healthcheck() (bool, error)
 	GetStats(ctx context.Context) ([]byte, error)
+	GetActive(ctx context.Context) ([]*entity.URL, error)
+	SetStatus(ctx context.Context, alias string, status int, checkedAt time.Time) error
+	ClaimLinks(ctx context.Context, fromUserID, toUserID string) (int, error)
+	admin.Store
+	webhook.Store
+	auth.SessionStore
+	auth.APIKeyStore
+	auth.AccountStore
+	auth.IdentityStore
+	auth.BanStore
+	quota.Store
+	report.Store
+	audit.Store
 }
 
 const (
@@ -52,6 +73,46 @@ func New(cfg *configuration.Config, log *zap.Logger) (Repository, error) {
 			if err != nil {
 				log.Fatal("failed to load data from file", zap.Error(err))
 			}
+			err = inmemory.LoadWebhooks(db)
+			if err != nil {
+				log.Fatal("failed to load webhooks from file", zap.Error(err))
+			}
+			err = inmemory.LoadSessions(db)
+			if err != nil {
+				log.Fatal("failed to load sessions from file", zap.Error(err))
+			}
+			err = inmemory.LoadAPIKeys(db)
+			if err != nil {
+				log.Fatal("failed to load API keys from file", zap.Error(err))
+			}
+			err = inmemory.LoadAccounts(db)
+			if err != nil {
+				log.Fatal("failed to load accounts from file", zap.Error(err))
+			}
+			err = inmemory.LoadIdentities(db)
+			if err != nil {
+				log.Fatal("failed to load identities from file", zap.Error(err))
+			}
+			err = inmemory.LoadDisabled(db)
+			if err != nil {
+				log.Fatal("failed to load disabled links from file", zap.Error(err))
+			}
+			err = inmemory.LoadBans(db)
+			if err != nil {
+				log.Fatal("failed to load bans from file", zap.Error(err))
+			}
+			err = inmemory.LoadQuotas(db)
+			if err != nil {
+				log.Fatal("failed to load quotas from file", zap.Error(err))
+			}
+			err = inmemory.LoadReports(db)
+			if err != nil {
+				log.Fatal("failed to load reports from file", zap.Error(err))
+			}
+			err = inmemory.LoadAudit(db)
+			if err != nil {
+				log.Fatal("failed to load audit log from file", zap.Error(err))
+			}
 			return db, nil
 		} else {
 			log.Fatal("the configuration is incorrect: remove the DSN configuration parameter")
@@ -4,43 +4,359 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/admin"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/internal/usecase/auth"
+	"github.com/nextlag/shortenerURL/internal/usecase/outbox"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/report"
 	"github.com/nextlag/shortenerURL/internal/usecase/repository"
+	"github.com/nextlag/shortenerURL/internal/usecase/webhook"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
 )
 
+// Publisher receives the link lifecycle events produced by the use cases.
+// Implementations must not block the caller.
+type Publisher interface {
+	Publish(ctx context.Context, event entity.Event)
+}
+
 // UseCase provides the use cases for interacting with the repository.
 type UseCase struct {
-	repo repository.Repository // interface for the repository
+	repo   repository.Repository // interface for the repository
+	events Publisher             // receiver of link lifecycle events, may be nil
+	outbox bool                  // the repository records created and deleted events itself
+	quota  entity.Quota          // default quota of users
+	audit  *audit.Recorder       // recorder of the audit log, may be nil
+}
+
+// Option configures a UseCase.
+type Option func(*UseCase)
+
+// WithPublisher sets the receiver of link lifecycle events.
+func WithPublisher(p Publisher) Option {
+	return func(uc *UseCase) {
+		uc.events = p
+	}
+}
+
+// WithQuota sets the default quota of users, which admins may override per user.
+func WithQuota(q entity.Quota) Option {
+	return func(uc *UseCase) {
+		uc.quota = q
+	}
+}
+
+// WithAudit sets the recorder of the audit log of mutating operations.
+func WithAudit(r *audit.Recorder) Option {
+	return func(uc *UseCase) {
+		uc.audit = r
+	}
 }
 
 // New creates a new instance of UseCase.
-func New(r repository.Repository) *UseCase {
-	return &UseCase{repo: r}
+func New(r repository.Repository, opts ...Option) *UseCase {
+	uc := &UseCase{repo: r}
+	_, uc.outbox = repository.Unwrap(r).(outbox.Store)
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
 }
 
 // DoGet retrieves a URL by its alias.
 func (uc *UseCase) DoGet(ctx context.Context, alias string) (*entity.URL, error) {
-	return uc.repo.Get(ctx, alias)
+	url, err := uc.repo.Get(ctx, alias)
+	if err == nil && !url.IsDeleted && !url.Disabled {
+		uc.publish(ctx, entity.EventLinkClicked, url.UUID, alias, url.URL)
+	}
+	return url, err
 }
 
 // DoGetAll retrieves all URLs for a specific user.
-func (uc *UseCase) DoGetAll(ctx context.Context, userID int, url string) ([]*entity.URL, error) {
+func (uc *UseCase) DoGetAll(ctx context.Context, userID string, url string) ([]*entity.URL, error) {
 	return uc.repo.GetAll(ctx, userID, url)
 }
 
-// DoPut saves a URL with a generated alias.
-func (uc *UseCase) DoPut(ctx context.Context, url string, alias string, uuid int) (string, error) {
-	return uc.repo.Put(ctx, url, alias, uuid)
+// DoListLinks retrieves a page of the links of a user, at most limit links ordered by
+// alias with an alias after the given one, which is empty for the first page.
+func (uc *UseCase) DoListLinks(ctx context.Context, userID string, after string, limit int) ([]*entity.URL, error) {
+	return uc.repo.ListLinks(ctx, userID, after, limit)
+}
+
+// DoPut saves a URL with a generated alias. Links over the quota of the user are
+// rejected with a quota.ExceededError. A URL the user already shortened returns the
+// existing alias without an audit entry or a link.created event.
+func (uc *UseCase) DoPut(ctx context.Context, url string, alias string, uuid string) (string, error) {
+	q, err := uc.quotaOf(ctx, uuid)
+	if err != nil {
+		return "", err
+	}
+	// The alias is generated here rather than by the repository, so that getting another
+	// alias back tells that the URL was already stored and nothing was inserted.
+	if alias == "" {
+		alias = generatestring.NewRandomString(8)
+	}
+	var stored string
+	if q.Unlimited() {
+		stored, err = uc.repo.Put(ctx, url, alias, uuid)
+	} else {
+		stored, err = uc.repo.PutWithinQuota(ctx, url, alias, uuid, q, quota.Day(time.Now()))
+	}
+	if err != nil || stored != alias {
+		return stored, err
+	}
+	uc.audit.Record(ctx, audit.ActionLinkCreate, uuid, stored, "", nil, &entity.URL{UUID: uuid, URL: url, Alias: stored})
+	if !uc.outbox {
+		uc.publish(ctx, entity.EventLinkCreated, uuid, stored, url)
+	}
+	return stored, nil
+}
+
+// DoPutBatch saves the URLs with generated aliases, all or none: a batch whose new links
+// would exceed the quota of the user together is rejected with a quota.ExceededError.
+// URLs the user already shortened return the existing alias and publish no event.
+func (uc *UseCase) DoPutBatch(ctx context.Context, urls []string, uuid string) ([]string, error) {
+	q, err := uc.quotaOf(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	aliases := make([]string, len(urls))
+	for i := range aliases {
+		aliases[i] = generatestring.NewRandomString(8)
+	}
+	stored, err := uc.repo.PutBatchWithinQuota(ctx, urls, aliases, uuid, q, quota.Day(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	for i, alias := range stored {
+		if alias != aliases[i] {
+			continue
+		}
+		uc.audit.Record(ctx, audit.ActionLinkCreate, uuid, alias, "", nil, &entity.URL{UUID: uuid, URL: urls[i], Alias: alias})
+		if !uc.outbox {
+			uc.publish(ctx, entity.EventLinkCreated, uuid, alias, urls[i])
+		}
+	}
+	return stored, nil
 }
 
 // DoDel deletes URLs for a user with the specified ID.
-func (uc *UseCase) DoDel(ctx context.Context, id int, aliases []string) {
+func (uc *UseCase) DoDel(ctx context.Context, id string, aliases []string) {
+	owned := uc.ownedLinks(ctx, id, aliases)
 	err := uc.repo.Del(ctx, id, aliases)
 	if err != nil {
 		_ = fmt.Errorf("error deleting user URL: %w", err)
+		return
+	}
+	// Only the links of the user that were not deleted yet are deleted, so that aliases
+	// of other users, unknown ones and repeated deletions are neither audited nor published.
+	for _, url := range owned {
+		deleted := *url
+		deleted.IsDeleted = true
+		uc.audit.Record(ctx, audit.ActionLinkDelete, id, url.Alias, "", url, &deleted)
+		if !uc.outbox {
+			uc.publish(ctx, entity.EventLinkDeleted, id, url.Alias, "")
+		}
+	}
+}
+
+// ownedLinks retrieves the links of the user that are about to be deleted, for the audit
+// log and the events. It retrieves nothing if neither is recorded.
+func (uc *UseCase) ownedLinks(ctx context.Context, userID string, aliases []string) []*entity.URL {
+	if !uc.audit.Enabled() && (uc.outbox || uc.events == nil) {
+		return nil
+	}
+	var owned []*entity.URL
+	for _, alias := range aliases {
+		url, err := uc.repo.Get(ctx, alias)
+		if err == nil && url.UUID == userID && !url.IsDeleted {
+			owned = append(owned, url)
+		}
 	}
+	return owned
+}
+
+// claimSnapshot is the state of the links of a claim in the audit log.
+type claimSnapshot struct {
+	UserID string `json:"user_id"`
+	Links  int    `json:"links"`
+}
+
+// DoClaim moves the links of an anonymous user into an account and returns how many were moved.
+func (uc *UseCase) DoClaim(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	n, err := uc.repo.ClaimLinks(ctx, fromUserID, toUserID)
+	if err == nil && n > 0 {
+		uc.audit.Record(ctx, audit.ActionLinkClaim, toUserID, "", fromUserID,
+			claimSnapshot{UserID: fromUserID, Links: n}, claimSnapshot{UserID: toUserID, Links: n})
+	}
+	return n, err
+}
+
+// DoGetQuota retrieves the quota of the user and its current usage.
+func (uc *UseCase) DoGetQuota(ctx context.Context, userID string) (*entity.QuotaUsage, error) {
+	q, err := uc.quotaOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	day := quota.Day(now)
+	links, created, err := uc.repo.CountLinks(ctx, userID, day)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.QuotaUsage{Quota: q, Links: links, LinksToday: created, ResetsAt: day.Add(24 * time.Hour)}, nil
+}
+
+// DoSetQuota sets the quota override of a user, or removes it if q is nil.
+func (uc *UseCase) DoSetQuota(ctx context.Context, userID string, q *entity.Quota) error {
+	if q != nil {
+		if err := quota.Validate(*q); err != nil {
+			return err
+		}
+	}
+	var before *entity.Quota
+	if uc.audit.Enabled() {
+		before, _ = uc.repo.GetQuota(ctx, userID)
+	}
+
+	var err error
+	action := audit.ActionQuotaSet
+	if q == nil {
+		action = audit.ActionQuotaDelete
+		err = uc.repo.DelQuota(ctx, userID)
+	} else {
+		err = uc.repo.PutQuota(ctx, userID, *q)
+	}
+	if err == nil {
+		uc.audit.Record(ctx, action, actorOf(ctx), "", userID, before, q)
+	}
+	return err
+}
+
+// quotaOf returns the quota of the user: its override or the default, narrowed by the
+// quota of the API key in the context.
+func (uc *UseCase) quotaOf(ctx context.Context, userID string) (entity.Quota, error) {
+	q := uc.quota
+	override, err := uc.repo.GetQuota(ctx, userID)
+	switch {
+	case err == nil:
+		q = *override
+	case !errors.Is(err, quota.ErrQuotaNotFound):
+		return entity.Quota{}, err
+	}
+	if keyQuota, ok := quota.FromContext(ctx); ok {
+		q = quota.Narrow(q, keyQuota)
+	}
+	return q, nil
+}
+
+// DoSearchLinks searches the links of all users for admins.
+func (uc *UseCase) DoSearchLinks(ctx context.Context, filter entity.LinkFilter) ([]*entity.URL, error) {
+	return uc.repo.SearchLinks(ctx, admin.NormalizeFilter(filter))
+}
+
+// DoSetDisabled disables or enables the redirect of a link.
+func (uc *UseCase) DoSetDisabled(ctx context.Context, alias string, disabled bool) error {
+	return uc.setDisabled(ctx, actorOf(ctx), alias, disabled)
+}
+
+// setDisabled disables or enables the redirect of a link on behalf of the actor.
+func (uc *UseCase) setDisabled(ctx context.Context, actor, alias string, disabled bool) error {
+	var before *entity.URL
+	if uc.audit.Enabled() {
+		before, _ = uc.repo.Get(ctx, alias)
+	}
+	if err := uc.repo.SetDisabled(ctx, alias, disabled); err != nil {
+		return err
+	}
+	if uc.audit.Enabled() {
+		action := audit.ActionLinkEnable
+		if disabled {
+			action = audit.ActionLinkDisable
+		}
+		after, _ := uc.repo.Get(ctx, alias)
+		uc.audit.Record(ctx, action, actor, alias, "", before, after)
+	}
+	return nil
+}
+
+// DoGetUserCounts counts the links of every user and marks the banned ones.
+func (uc *UseCase) DoGetUserCounts(ctx context.Context) ([]*entity.UserCount, error) {
+	return uc.repo.GetUserCounts(ctx)
+}
+
+// DoReport files an abuse report against a link, merging it into the open report of the
+// link if there is one. Unknown and deleted links are rejected with admin.ErrLinkNotFound.
+func (uc *UseCase) DoReport(ctx context.Context, alias, reason string) (*entity.Report, error) {
+	reason, err := report.NormalizeReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	url, err := uc.repo.Get(ctx, alias)
+	if err != nil || url.IsDeleted {
+		return nil, admin.ErrLinkNotFound
+	}
+	return uc.repo.PutReport(ctx, alias, reason, time.Now())
+}
+
+// DoGetReports retrieves the abuse reports with the status, or all reports if status is empty.
+func (uc *UseCase) DoGetReports(ctx context.Context, status string) ([]*entity.Report, error) {
+	if !report.ValidStatus(status) {
+		return nil, report.ErrUnknownStatus
+	}
+	return uc.repo.GetReports(ctx, status)
+}
+
+// DoReviewReport closes an open report by the action of the admin. ActionDisable disables
+// the reported link first.
+func (uc *UseCase) DoReviewReport(ctx context.Context, id, adminID, action string) (*entity.Report, error) {
+	status, err := report.StatusOf(action)
+	if err != nil {
+		return nil, err
+	}
+	rep, err := uc.repo.GetReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rep.Status != entity.ReportOpen {
+		return nil, report.ErrReportClosed
+	}
+	if action == report.ActionDisable {
+		if err = uc.setDisabled(ctx, adminID, rep.Alias, true); err != nil {
+			return nil, err
+		}
+	}
+	if err = uc.repo.CloseReport(ctx, id, status, adminID, time.Now()); err != nil {
+		return nil, err
+	}
+	reviewed, err := uc.repo.GetReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, audit.ActionReportReview, adminID, rep.Alias, id, rep, reviewed)
+	return reviewed, nil
+}
+
+// DoGetAudit retrieves the entries of the audit log matching the filter, the latest first.
+func (uc *UseCase) DoGetAudit(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	filter, err := audit.NormalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.GetAudit(ctx, filter)
+}
+
+// actorOf returns the user of the request in the context, who is the actor of the
+// operations without an explicit user, such as those of admins.
+func actorOf(ctx context.Context) string {
+	userID, _ := auth.FromContext(ctx)
+	return userID
 }
 
 // DoHealthcheck checks the health of the repository.
@@ -52,3 +368,73 @@ func (uc *UseCase) DoHealthcheck() (bool, error) {
 func (uc *UseCase) DoGetStats(ctx context.Context) ([]byte, error) {
 	return uc.repo.GetStats(ctx)
 }
+
+// DoAddWebhook registers a webhook for the user. A secret is generated if none is given.
+// URLs of hosts that are not publicly routable are rejected with webhook.ErrInvalidURL.
+func (uc *UseCase) DoAddWebhook(ctx context.Context, userID string, hook *entity.Webhook) (*entity.Webhook, error) {
+	if err := webhook.CheckURL(ctx, hook.URL); err != nil {
+		return nil, err
+	}
+	hook.ID = generatestring.GenerateUUID()
+	hook.UserID = userID
+	hook.CreatedAt = time.Now()
+	if hook.Secret == "" {
+		hook.Secret = generatestring.NewRandomString(32)
+	}
+	if err := uc.repo.PutWebhook(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// DoGetWebhooks retrieves the webhooks of the user without their secrets.
+func (uc *UseCase) DoGetWebhooks(ctx context.Context, userID string) ([]*entity.Webhook, error) {
+	hooks, err := uc.repo.GetWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+	return hooks, nil
+}
+
+// DoDelWebhook removes a webhook of the user.
+func (uc *UseCase) DoDelWebhook(ctx context.Context, userID string, id string) error {
+	return uc.repo.DelWebhook(ctx, userID, id)
+}
+
+// DoGetDeliveries retrieves the delivery log of a webhook of the user.
+func (uc *UseCase) DoGetDeliveries(ctx context.Context, userID string, webhookID string) ([]*entity.Delivery, error) {
+	return uc.repo.GetDeliveries(ctx, userID, webhookID)
+}
+
+// DoGetDeadLetters retrieves the deliveries of a webhook of the user that exhausted all attempts.
+func (uc *UseCase) DoGetDeadLetters(ctx context.Context, userID string, webhookID string) ([]*entity.Delivery, error) {
+	deliveries, err := uc.repo.GetDeliveries(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	deadLetters := make([]*entity.Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		if delivery.DeadLetter {
+			deadLetters = append(deadLetters, delivery)
+		}
+	}
+	return deadLetters, nil
+}
+
+// publish sends a link lifecycle event to the configured publisher.
+func (uc *UseCase) publish(ctx context.Context, eventType string, userID string, alias, url string) {
+	if uc.events == nil {
+		return
+	}
+	uc.events.Publish(ctx, entity.Event{
+		ID:         generatestring.GenerateUUID(),
+		Type:       eventType,
+		UserID:     userID,
+		Alias:      alias,
+		URL:        url,
+		OccurredAt: time.Now(),
+	})
+}
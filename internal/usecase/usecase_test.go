@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/internal/usecase/audit"
+	"github.com/nextlag/shortenerURL/internal/usecase/quota"
+	"github.com/nextlag/shortenerURL/internal/usecase/repository/inmemory"
+)
+
+type recordingPublisher struct {
+	events []entity.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event entity.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestDoPutReportsOnlyStoredLinks(t *testing.T) {
+	ctx := context.Background()
+	store, err := inmemory.New(&configuration.Config{}, zap.NewNop())
+	require.NoError(t, err)
+	publisher := &recordingPublisher{}
+	uc := New(store, WithPublisher(publisher), WithAudit(audit.NewRecorder(store, zap.NewNop())))
+
+	alias, err := uc.DoPut(ctx, "https://example.com/", "", "user")
+	require.NoError(t, err)
+	existing, err := uc.DoPut(ctx, "HTTPS://EXAMPLE.com:443", "", "user")
+	require.NoError(t, err)
+
+	assert.Equal(t, alias, existing)
+	require.Len(t, publisher.events, 1, "the duplicate publishes nothing")
+	assert.Equal(t, entity.EventLinkCreated, publisher.events[0].Type)
+	assert.Equal(t, alias, publisher.events[0].Alias)
+
+	entries, err := store.GetAudit(ctx, entity.AuditFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "the duplicate is not audited")
+	assert.Equal(t, audit.ActionLinkCreate, entries[0].Action)
+}
+
+func TestDoPutBatch(t *testing.T) {
+	ctx := context.Background()
+	store, err := inmemory.New(&configuration.Config{}, zap.NewNop())
+	require.NoError(t, err)
+	publisher := &recordingPublisher{}
+	uc := New(store, WithPublisher(publisher), WithQuota(entity.Quota{MaxLinks: 2}))
+
+	existing, err := uc.DoPut(ctx, "https://example.com/", "", "user")
+	require.NoError(t, err)
+	_, err = uc.DoPutBatch(ctx, []string{"https://example.com/a", "https://example.com/b"}, "user")
+	assert.ErrorIs(t, err, quota.ErrExceeded, "the batch exceeds the quota together")
+
+	aliases, err := uc.DoPutBatch(ctx, []string{"https://example.com/", "https://example.com/a"}, "user")
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	assert.Equal(t, existing, aliases[0])
+	require.Len(t, publisher.events, 2, "only the new link of the batch is published")
+	assert.Equal(t, aliases[1], publisher.events[1].Alias)
+}
+
+func TestDoDelReportsOnlyOwnedLinks(t *testing.T) {
+	// The in-memory storage records deletions in del.json of the working directory.
+	t.Cleanup(func() { os.Remove("del.json") })
+	ctx := context.Background()
+	cfg := &configuration.Config{}
+	cfg.FileStorage = filepath.Join(t.TempDir(), "links.json")
+	store, err := inmemory.New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	publisher := &recordingPublisher{}
+	uc := New(store, WithPublisher(publisher))
+
+	own, err := uc.DoPut(ctx, "https://example.com/own", "", "user")
+	require.NoError(t, err)
+	gone, err := uc.DoPut(ctx, "https://example.com/gone", "", "user")
+	require.NoError(t, err)
+	foreign, err := uc.DoPut(ctx, "https://example.com/foreign", "", "other")
+	require.NoError(t, err)
+	uc.DoDel(ctx, "user", []string{gone})
+	publisher.events = nil
+
+	uc.DoDel(ctx, "user", []string{own, gone, foreign, "unknown"})
+
+	require.Len(t, publisher.events, 1, "only the owned link that was not deleted yet is published")
+	assert.Equal(t, entity.EventLinkDeleted, publisher.events[0].Type)
+	assert.Equal(t, own, publisher.events[0].Alias)
+}
@@ -0,0 +1,261 @@
+// Package webhook delivers link lifecycle events to the HTTP endpoints registered by users.
+// Events are signed with the endpoint's HMAC secret and retried with exponential backoff;
+// the last failed attempt of an event is kept as a dead letter.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+	"github.com/nextlag/shortenerURL/pkg/tools/generatestring"
+	"github.com/nextlag/shortenerURL/pkg/tools/safehttp"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Shortener-Event"
+	HeaderDelivery  = "X-Shortener-Delivery"
+	HeaderTimestamp = "X-Shortener-Timestamp"
+	HeaderSignature = "X-Shortener-Signature"
+)
+
+// ErrNotFound is returned when a webhook or delivery does not exist or belongs to another user.
+var ErrNotFound = errors.New("webhook not found")
+
+// ErrInvalidURL is returned for webhook URLs that are not http or https URLs of a publicly
+// routable host.
+var ErrInvalidURL = errors.New("webhook URL is not allowed")
+
+// Store keeps webhook registrations and the delivery log.
+type Store interface {
+	PutWebhook(ctx context.Context, hook *entity.Webhook) error
+	GetWebhooks(ctx context.Context, userID string) ([]*entity.Webhook, error)
+	DelWebhook(ctx context.Context, userID string, id string) error
+	PutDelivery(ctx context.Context, delivery *entity.Delivery) error
+	GetDeliveries(ctx context.Context, userID string, webhookID string) ([]*entity.Delivery, error)
+}
+
+// job is a pending delivery of one event to one webhook.
+type job struct {
+	hook    *entity.Webhook
+	event   entity.Event
+	payload []byte
+	attempt int
+}
+
+// Dispatcher asynchronously delivers events to webhooks.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	log    *zap.Logger
+	cfg    configuration.Webhooks
+
+	events chan entity.Event
+	jobs   chan job
+	wg     sync.WaitGroup
+	ctx    context.Context
+}
+
+// New creates a new Dispatcher. Start must be called before events are delivered.
+func New(store Store, cfg configuration.Webhooks, log *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: safehttp.NewClient(time.Duration(cfg.Timeout)),
+		log:    log,
+		cfg:    cfg,
+		events: make(chan entity.Event, cfg.QueueSize),
+		jobs:   make(chan job, cfg.QueueSize),
+		ctx:    context.Background(),
+	}
+}
+
+// Start launches the delivery workers. They stop when ctx is canceled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.ctx = ctx
+	workers := d.cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+}
+
+// Wait blocks until all workers have stopped.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Publish queues the event for delivery without blocking the caller.
+// The event is dropped if the queue is full.
+func (d *Dispatcher) Publish(_ context.Context, event entity.Event) {
+	select {
+	case d.events <- event:
+	default:
+		d.log.Warn("webhook queue is full, event dropped", zap.String("event", event.ID), zap.String("type", event.Type))
+	}
+}
+
+// Events returns the queue of the events to deliver, for publishers that check for room
+// themselves, such as the outbox channel publisher. The workers deliver the events in
+// the background.
+func (d *Dispatcher) Events() chan<- entity.Event {
+	return d.events
+}
+
+// work processes events and deliveries until ctx is canceled.
+func (d *Dispatcher) work(ctx context.Context) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.events:
+			d.fanOut(ctx, event)
+		case j := <-d.jobs:
+			d.deliver(ctx, j)
+		}
+	}
+}
+
+// fanOut delivers the event to every webhook of the event owner subscribed to the event.
+func (d *Dispatcher) fanOut(ctx context.Context, event entity.Event) {
+	hooks, err := d.store.GetWebhooks(ctx, event.UserID)
+	if err != nil {
+		d.log.Error("failed to load webhooks", zap.String("user_id", event.UserID), zap.Error(err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		d.log.Error("failed to encode event", zap.String("event", event.ID), zap.Error(err))
+		return
+	}
+	for _, hook := range hooks {
+		if hook.Accepts(event.Type) {
+			d.deliver(ctx, job{hook: hook, event: event, payload: payload, attempt: 1})
+		}
+	}
+}
+
+// deliver sends one attempt, logs it and schedules a retry on failure.
+func (d *Dispatcher) deliver(ctx context.Context, j job) {
+	delivery := &entity.Delivery{
+		ID:        generatestring.GenerateUUID(),
+		WebhookID: j.hook.ID,
+		UserID:    j.hook.UserID,
+		EventID:   j.event.ID,
+		EventType: j.event.Type,
+		Payload:   j.payload,
+		Attempt:   j.attempt,
+		CreatedAt: time.Now(),
+	}
+
+	status, err := d.send(ctx, j.hook, delivery)
+	delivery.Status = status
+	if err == nil && (status < 200 || status >= 300) {
+		err = fmt.Errorf("unexpected status %d", status)
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+		delivery.DeadLetter = j.attempt >= d.cfg.MaxAttempts
+	}
+
+	if logErr := d.store.PutDelivery(ctx, delivery); logErr != nil {
+		d.log.Error("failed to log webhook delivery", zap.String("webhook", j.hook.ID), zap.Error(logErr))
+	}
+	if err == nil || delivery.DeadLetter {
+		return
+	}
+
+	j.attempt++
+	time.AfterFunc(d.Backoff(j.attempt), func() { d.enqueue(j) })
+}
+
+// CheckURL checks that a webhook URL is an http or https URL whose host resolves only to
+// publicly routable addresses, so that webhooks cannot reach the network of the service.
+// Deliveries check the addresses again when they connect.
+func CheckURL(ctx context.Context, raw string) error {
+	if err := safehttp.CheckURL(ctx, raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	return nil
+}
+
+// send posts the signed payload to the webhook URL. Connections to addresses that are
+// not publicly routable are refused.
+func (d *Dispatcher) send(ctx context.Context, hook *entity.Webhook, delivery *entity.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(delivery.CreatedAt.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// enqueue hands a retry to the workers unless the dispatcher is stopping.
+func (d *Dispatcher) enqueue(j job) {
+	select {
+	case <-d.ctx.Done():
+	case d.jobs <- j:
+	}
+}
+
+// Backoff returns the delay before the given attempt: the initial backoff doubled
+// for every previous retry and capped at the maximum backoff.
+func (d *Dispatcher) Backoff(attempt int) time.Duration {
+	delay := time.Duration(d.cfg.InitialBackoff)
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+		if delay >= time.Duration(d.cfg.MaxBackoff) {
+			return time.Duration(d.cfg.MaxBackoff)
+		}
+	}
+	return delay
+}
+
+// Sign returns the value of the signature header: the hex encoded HMAC-SHA256
+// of the timestamp and the body joined by a dot, prefixed with "sha256=".
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches the timestamp and body. Receivers can use it
+// to authenticate deliveries.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nextlag/shortenerURL/internal/configuration"
+	"github.com/nextlag/shortenerURL/internal/entity"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	hooks      []*entity.Webhook
+	deliveries []*entity.Delivery
+}
+
+func (s *fakeStore) PutWebhook(_ context.Context, hook *entity.Webhook) error {
+	s.hooks = append(s.hooks, hook)
+	return nil
+}
+
+func (s *fakeStore) GetWebhooks(_ context.Context, userID string) ([]*entity.Webhook, error) {
+	var hooks []*entity.Webhook
+	for _, hook := range s.hooks {
+		if hook.UserID == userID {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *fakeStore) DelWebhook(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+func (s *fakeStore) PutDelivery(_ context.Context, delivery *entity.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *fakeStore) GetDeliveries(_ context.Context, _ string, _ string) ([]*entity.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*entity.Delivery(nil), s.deliveries...), nil
+}
+
+func testConfig() configuration.Webhooks {
+	return configuration.Webhooks{
+		Workers:        2,
+		QueueSize:      16,
+		MaxAttempts:    3,
+		InitialBackoff: configuration.Duration(10 * time.Millisecond),
+		MaxBackoff:     configuration.Duration(20 * time.Millisecond),
+		Timeout:        configuration.Duration(time.Second),
+	}
+}
+
+func waitDeliveries(t *testing.T, store *fakeStore, n int) []*entity.Delivery {
+	t.Helper()
+	var deliveries []*entity.Delivery
+	require.Eventually(t, func() bool {
+		deliveries, _ = store.GetDeliveries(context.Background(), "", "")
+		return len(deliveries) >= n
+	}, 2*time.Second, 5*time.Millisecond)
+	return deliveries
+}
+
+func TestDispatcherSignedDelivery(t *testing.T) {
+	received := make(chan bool, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- Verify("secret", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) &&
+			r.Header.Get(HeaderEvent) == entity.EventLinkCreated
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{hooks: []*entity.Webhook{
+		{ID: "hook", UserID: "user", URL: srv.URL, Secret: "secret"},
+		{ID: "other", UserID: "user", URL: srv.URL, Secret: "secret", Events: []string{entity.EventLinkDeleted}},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := New(store, testConfig(), zap.NewNop())
+	// The test server listens on a loopback address, which the default client refuses.
+	d.client = &http.Client{Timeout: time.Second}
+	d.Start(ctx)
+	d.Publish(ctx, entity.Event{ID: "event", Type: entity.EventLinkCreated, UserID: "user", Alias: "abc"})
+
+	assert.True(t, <-received, "signature or event header mismatch")
+	deliveries := waitDeliveries(t, store, 1)
+	assert.Equal(t, "hook", deliveries[0].WebhookID)
+	assert.Equal(t, http.StatusNoContent, deliveries[0].Status)
+	assert.False(t, deliveries[0].DeadLetter)
+}
+
+func TestDispatcherRetriesAndDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{hooks: []*entity.Webhook{{ID: "hook", UserID: "user", URL: srv.URL, Secret: "secret"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := New(store, testConfig(), zap.NewNop())
+	d.client = &http.Client{Timeout: time.Second}
+	d.Start(ctx)
+	d.Publish(ctx, entity.Event{ID: "event", Type: entity.EventLinkClicked, UserID: "user"})
+
+	deliveries := waitDeliveries(t, store, 3)
+	assert.Equal(t, int32(3), calls.Load())
+	for i, delivery := range deliveries {
+		assert.Equal(t, i+1, delivery.Attempt)
+		assert.Equal(t, i == 2, delivery.DeadLetter)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := New(&fakeStore{}, configuration.Webhooks{
+		InitialBackoff: configuration.Duration(time.Second),
+		MaxBackoff:     configuration.Duration(5 * time.Second),
+	}, zap.NewNop())
+
+	assert.Equal(t, time.Second, d.Backoff(2))
+	assert.Equal(t, 2*time.Second, d.Backoff(3))
+	assert.Equal(t, 4*time.Second, d.Backoff(4))
+	assert.Equal(t, 5*time.Second, d.Backoff(5))
+}
+
+func TestInternalURLsAreRefused(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	assert.ErrorIs(t, CheckURL(context.Background(), srv.URL), ErrInvalidURL)
+	assert.ErrorIs(t, CheckURL(context.Background(), "http://169.254.169.254/latest/meta-data/"), ErrInvalidURL)
+
+	d := New(&fakeStore{}, testConfig(), zap.NewNop())
+	_, err := d.send(context.Background(), &entity.Webhook{URL: srv.URL}, &entity.Delivery{Payload: []byte("{}")})
+	assert.Error(t, err)
+	assert.Zero(t, calls.Load())
+}
+
+func TestEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{hooks: []*entity.Webhook{{ID: "hook", UserID: "user", URL: srv.URL, Secret: "secret"}}}
+	d := New(store, testConfig(), zap.NewNop())
+	d.client = &http.Client{Timeout: time.Second}
+	// Events are queued before the workers start and delivered by them.
+	d.Events() <- entity.Event{ID: "event", Type: entity.EventLinkCreated, UserID: "user"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+
+	deliveries := waitDeliveries(t, store, 1)
+	assert.Equal(t, "event", deliveries[0].EventID)
+}
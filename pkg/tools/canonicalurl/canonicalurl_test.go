@@ -0,0 +1,49 @@
+package canonicalurl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{name: "scheme and host case", raw: "HTTP://Example.COM/", expected: "http://example.com/"},
+		{name: "empty path", raw: "http://example.com", expected: "http://example.com/"},
+		{name: "default http port", raw: "http://example.com:80/a", expected: "http://example.com/a"},
+		{name: "default https port", raw: "https://example.com:443/a", expected: "https://example.com/a"},
+		{name: "custom port kept", raw: "http://example.com:8080/a", expected: "http://example.com:8080/a"},
+		{name: "unreserved escapes decoded", raw: "http://example.com/%7Euser/%61bc", expected: "http://example.com/~user/abc"},
+		{name: "reserved escapes upper-cased", raw: "http://example.com/a%2fb?q=%3d", expected: "http://example.com/a%2Fb?q=%3D"},
+		{name: "path case preserved", raw: "http://example.com/Path", expected: "http://example.com/Path"},
+		{name: "idn host", raw: "http://Пример.рф/", expected: "http://xn--e1afmkfd.xn--p1ai/"},
+		{name: "punycode host", raw: "http://xn--e1afmkfd.xn--p1ai", expected: "http://xn--e1afmkfd.xn--p1ai/"},
+		{name: "ipv6 host", raw: "http://[::1]:80/", expected: "http://[::1]/"},
+		{name: "fragment kept", raw: "http://example.com/#Top", expected: "http://example.com/#Top"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize(tt.raw)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestCanonicalizeError(t *testing.T) {
+	_, err := Canonicalize("example.com")
+	assert.ErrorIs(t, err, ErrNoHost)
+
+	_, err = Canonicalize("http://exa mple.com")
+	assert.Error(t, err)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "http://example.com/", Key("HTTP://EXAMPLE.com"))
+	assert.Equal(t, "not a url", Key("not a url"))
+}
@@ -0,0 +1,19 @@
+package canonicalurl_test
+
+import (
+	"fmt"
+
+	"github.com/nextlag/shortenerURL/pkg/tools/canonicalurl"
+)
+
+// Example demonstrates that different spellings of a URL share one canonical form.
+func Example() {
+	a, _ := canonicalurl.Canonicalize("HTTP://Example.com:80")
+	b, _ := canonicalurl.Canonicalize("http://example.com/")
+	fmt.Println(a)
+	fmt.Println(a == b)
+
+	// Output:
+	// http://example.com/
+	// true
+}
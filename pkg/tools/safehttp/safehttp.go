@@ -0,0 +1,137 @@
+// Package safehttp provides HTTP clients for URLs supplied by users, such as link targets
+// and webhook endpoints. The clients refuse to connect to loopback, private, link-local,
+// cloud metadata and other addresses that are not publicly routable, so that users cannot
+// make the service reach its own network.
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is the number of redirects the clients follow.
+const maxRedirects = 10
+
+// ErrForbiddenAddress is returned for addresses that are not publicly routable.
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// ErrForbiddenURL is returned for URLs that are not http or https URLs with a host.
+var ErrForbiddenURL = errors.New("URL must be an http or https URL with a host")
+
+// reserved are the special-purpose ranges not covered by the methods of netip.Addr.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast included
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which maps to IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which maps to IPv4 addresses
+}
+
+// Forbidden reports whether ip is not publicly routable: loopback, private, link-local
+// (such as the 169.254.169.254 metadata endpoint), multicast, unspecified or reserved.
+func Forbidden(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Control is a net.Dialer Control function that rejects connections to forbidden addresses.
+// It runs after name resolution, for every connection, so that host names resolving to
+// internal addresses are rejected as well.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if Forbidden(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewClient creates an HTTP client with the timeout that only connects to publicly
+// routable addresses, for the first request and for every redirect it follows. It
+// ignores the proxy settings of the environment, which would bypass the check.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: Control}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkTarget(req.URL)
+		},
+	}
+}
+
+// CheckURL checks that raw is an http or https URL whose host resolves only to publicly
+// routable addresses. It is meant for registration; the clients of NewClient check every
+// connection again, as the addresses of the host may change.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrForbiddenURL, err)
+	}
+	if err = checkTarget(u); err != nil {
+		return err
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if Forbidden(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+		}
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", u.Hostname(), err)
+	}
+	for _, ip := range ips {
+		if Forbidden(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, u.Hostname(), ip)
+		}
+	}
+	return nil
+}
+
+// checkTarget checks the scheme and the host of a request URL. Literal IP addresses are
+// rejected here already, before any connection is made.
+func checkTarget(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrForbiddenURL
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && Forbidden(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
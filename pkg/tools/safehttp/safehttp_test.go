@@ -0,0 +1,67 @@
+package safehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForbidden(t *testing.T) {
+	tests := []struct {
+		ip        string
+		forbidden bool
+	}{
+		{ip: "127.0.0.1", forbidden: true},
+		{ip: "10.1.2.3", forbidden: true},
+		{ip: "172.16.0.1", forbidden: true},
+		{ip: "192.168.1.1", forbidden: true},
+		{ip: "169.254.169.254", forbidden: true},
+		{ip: "100.64.0.1", forbidden: true},
+		{ip: "0.0.0.0", forbidden: true},
+		{ip: "::1", forbidden: true},
+		{ip: "::ffff:127.0.0.1", forbidden: true},
+		{ip: "fd00::1", forbidden: true},
+		{ip: "fe80::1", forbidden: true},
+		{ip: "64:ff9b::a9fe:a9fe", forbidden: true},
+		{ip: "93.184.216.34", forbidden: false},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", forbidden: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.forbidden, Forbidden(netip.MustParseAddr(tt.ip)))
+		})
+	}
+}
+
+func TestClientRejectsInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewClient(time.Second)
+	_, err := client.Get(srv.URL)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+
+	redirect, err := http.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data/", nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.CheckRedirect(redirect, []*http.Request{{}}), ErrForbiddenAddress)
+	redirect, err = http.NewRequest(http.MethodGet, "file:///etc/passwd", nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.CheckRedirect(redirect, []*http.Request{{}}), ErrForbiddenURL)
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorIs(t, CheckURL(ctx, "http://127.0.0.1:8080/hook"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckURL(ctx, "http://[::1]/hook"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckURL(ctx, "http://localhost/hook"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckURL(ctx, "ftp://example.com/"), ErrForbiddenURL)
+	assert.NoError(t, CheckURL(ctx, "https://93.184.216.34/hook"))
+}
@@ -0,0 +1,75 @@
+// Package userid generates and parses user identifiers.
+//
+// New identifiers are UUIDv7, which are unique without coordination and sort by creation time.
+// The integer identifiers issued by earlier versions are mapped to name-based UUIDs, so
+// the same legacy ID always maps to the same user.
+package userid
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalid is returned for strings that are neither a UUID nor a legacy integer ID.
+var ErrInvalid = errors.New("invalid user ID")
+
+// legacyNamespace is the namespace of the UUIDs derived from legacy integer IDs.
+var legacyNamespace = uuid.MustParse("5d6c2b39-2c9e-4f4e-9b0b-6b1f3f0c7a11")
+
+// New returns a new time-ordered user ID.
+func New() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		// The random source failed; a random UUID is still collision-free.
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// FromLegacy returns the user ID that replaces the legacy integer ID.
+func FromLegacy(id int) string {
+	return uuid.NewSHA1(legacyNamespace, []byte(strconv.Itoa(id))).String()
+}
+
+// Parse returns the canonical form of a user ID, mapping legacy integer IDs.
+func Parse(s string) (string, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		return FromLegacy(id), nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	return id.String(), nil
+}
+
+// IsLegacy reports whether s is a legacy integer ID.
+func IsLegacy(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// Compat is a user ID in JSON written by earlier versions, which store integer IDs.
+// Integers are mapped to their new IDs when decoded.
+type Compat string
+
+// UnmarshalJSON accepts both string and legacy integer IDs.
+func (id *Compat) UnmarshalJSON(data []byte) error {
+	var legacy int
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*id = Compat(FromLegacy(legacy))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if IsLegacy(s) {
+		s, _ = Parse(s)
+	}
+	*id = Compat(s)
+	return nil
+}
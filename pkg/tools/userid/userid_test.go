@@ -0,0 +1,50 @@
+package userid
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	assert.NotEqual(t, a, b)
+
+	id, err := uuid.Parse(a)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+}
+
+func TestParse(t *testing.T) {
+	id := New()
+	got, err := Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	legacy, err := Parse("924")
+	require.NoError(t, err)
+	assert.Equal(t, FromLegacy(924), legacy)
+	assert.NotEqual(t, FromLegacy(923), legacy)
+	assert.True(t, IsLegacy("924"))
+	assert.False(t, IsLegacy(id))
+
+	_, err = Parse("nobody")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestCompat(t *testing.T) {
+	var v struct {
+		ID Compat `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"id":924}`), &v))
+	assert.Equal(t, FromLegacy(924), string(v.ID))
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"924"}`), &v))
+	assert.Equal(t, FromLegacy(924), string(v.ID))
+
+	id := New()
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"`+id+`"}`), &v))
+	assert.Equal(t, id, string(v.ID))
+}
@@ -19,12 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	Links_Get_FullMethodName          = "/proto.Links/Get"
-	Links_Save_FullMethodName         = "/proto.Links/Save"
-	Links_GetAll_FullMethodName       = "/proto.Links/GetAll"
-	Links_Del_FullMethodName          = "/proto.Links/Del"
-	Links_Healthcheck_FullMethodName  = "/proto.Links/Healthcheck"
-	Links_BatchShorten_FullMethodName = "/proto.Links/BatchShorten"
+	Links_Get_FullMethodName           = "/proto.Links/Get"
+	Links_Save_FullMethodName          = "/proto.Links/Save"
+	Links_GetAll_FullMethodName        = "/proto.Links/GetAll"
+	Links_Del_FullMethodName           = "/proto.Links/Del"
+	Links_Healthcheck_FullMethodName   = "/proto.Links/Healthcheck"
+	Links_BatchShorten_FullMethodName  = "/proto.Links/BatchShorten"
+	Links_ShortenStream_FullMethodName = "/proto.Links/ShortenStream"
+	Links_ListLinks_FullMethodName     = "/proto.Links/ListLinks"
+	Links_Register_FullMethodName      = "/proto.Links/Register"
+	Links_Login_FullMethodName         = "/proto.Links/Login"
+	Links_Refresh_FullMethodName       = "/proto.Links/Refresh"
 )
 
 // LinksClient is the client API for Links service.
@@ -45,6 +50,17 @@ type LinksClient interface {
 	Healthcheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*HealthcheckResponse, error)
 	// RPC to process multiple URLs in a batch and return their shortened versions.
 	BatchShorten(ctx context.Context, in *BatchShortenRequest, opts ...grpc.CallOption) (*BatchShortenResponse, error)
+	// RPC to shorten a stream of URLs, returning the result of each URL as soon as it is
+	// stored. Failed URLs are reported in their result and do not end the stream.
+	ShortenStream(ctx context.Context, opts ...grpc.CallOption) (Links_ShortenStreamClient, error)
+	// RPC to stream all shortened links of a user, read from the storage page by page.
+	ListLinks(ctx context.Context, in *ListLinksRequest, opts ...grpc.CallOption) (Links_ListLinksClient, error)
+	// RPC to create an account and log it in.
+	Register(ctx context.Context, in *Credentials, opts ...grpc.CallOption) (*AuthResponse, error)
+	// RPC to log in to an account.
+	Login(ctx context.Context, in *Credentials, opts ...grpc.CallOption) (*AuthResponse, error)
+	// RPC to exchange a refresh token for new tokens.
+	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*AuthResponse, error)
 }
 
 type linksClient struct {
@@ -115,6 +131,101 @@ func (c *linksClient) BatchShorten(ctx context.Context, in *BatchShortenRequest,
 	return out, nil
 }
 
+func (c *linksClient) ShortenStream(ctx context.Context, opts ...grpc.CallOption) (Links_ShortenStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Links_ServiceDesc.Streams[0], Links_ShortenStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &linksShortenStreamClient{ClientStream: stream}
+	return x, nil
+}
+
+type Links_ShortenStreamClient interface {
+	Send(*ShortenStreamRequest) error
+	Recv() (*ShortenStreamResponse, error)
+	grpc.ClientStream
+}
+
+type linksShortenStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *linksShortenStreamClient) Send(m *ShortenStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *linksShortenStreamClient) Recv() (*ShortenStreamResponse, error) {
+	m := new(ShortenStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *linksClient) ListLinks(ctx context.Context, in *ListLinksRequest, opts ...grpc.CallOption) (Links_ListLinksClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Links_ServiceDesc.Streams[1], Links_ListLinks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &linksListLinksClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Links_ListLinksClient interface {
+	Recv() (*UserLink, error)
+	grpc.ClientStream
+}
+
+type linksListLinksClient struct {
+	grpc.ClientStream
+}
+
+func (x *linksListLinksClient) Recv() (*UserLink, error) {
+	m := new(UserLink)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *linksClient) Register(ctx context.Context, in *Credentials, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, Links_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linksClient) Login(ctx context.Context, in *Credentials, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, Links_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linksClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, Links_Refresh_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LinksServer is the server API for Links service.
 // All implementations must embed UnimplementedLinksServer
 // for forward compatibility
@@ -133,6 +244,17 @@ type LinksServer interface {
 	Healthcheck(context.Context, *Empty) (*HealthcheckResponse, error)
 	// RPC to process multiple URLs in a batch and return their shortened versions.
 	BatchShorten(context.Context, *BatchShortenRequest) (*BatchShortenResponse, error)
+	// RPC to shorten a stream of URLs, returning the result of each URL as soon as it is
+	// stored. Failed URLs are reported in their result and do not end the stream.
+	ShortenStream(Links_ShortenStreamServer) error
+	// RPC to stream all shortened links of a user, read from the storage page by page.
+	ListLinks(*ListLinksRequest, Links_ListLinksServer) error
+	// RPC to create an account and log it in.
+	Register(context.Context, *Credentials) (*AuthResponse, error)
+	// RPC to log in to an account.
+	Login(context.Context, *Credentials) (*AuthResponse, error)
+	// RPC to exchange a refresh token for new tokens.
+	Refresh(context.Context, *RefreshRequest) (*AuthResponse, error)
 	mustEmbedUnimplementedLinksServer()
 }
 
@@ -158,6 +280,21 @@ func (UnimplementedLinksServer) Healthcheck(context.Context, *Empty) (*Healthche
 func (UnimplementedLinksServer) BatchShorten(context.Context, *BatchShortenRequest) (*BatchShortenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchShorten not implemented")
 }
+func (UnimplementedLinksServer) ShortenStream(Links_ShortenStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ShortenStream not implemented")
+}
+func (UnimplementedLinksServer) ListLinks(*ListLinksRequest, Links_ListLinksServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListLinks not implemented")
+}
+func (UnimplementedLinksServer) Register(context.Context, *Credentials) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedLinksServer) Login(context.Context, *Credentials) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedLinksServer) Refresh(context.Context, *RefreshRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
+}
 func (UnimplementedLinksServer) mustEmbedUnimplementedLinksServer() {}
 
 // UnsafeLinksServer may be embedded to opt out of forward compatibility for this service.
@@ -279,6 +416,107 @@ func _Links_BatchShorten_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Links_ShortenStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LinksServer).ShortenStream(&linksShortenStreamServer{ServerStream: stream})
+}
+
+type Links_ShortenStreamServer interface {
+	Send(*ShortenStreamResponse) error
+	Recv() (*ShortenStreamRequest, error)
+	grpc.ServerStream
+}
+
+type linksShortenStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *linksShortenStreamServer) Send(m *ShortenStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *linksShortenStreamServer) Recv() (*ShortenStreamRequest, error) {
+	m := new(ShortenStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Links_ListLinks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListLinksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LinksServer).ListLinks(m, &linksListLinksServer{ServerStream: stream})
+}
+
+type Links_ListLinksServer interface {
+	Send(*UserLink) error
+	grpc.ServerStream
+}
+
+type linksListLinksServer struct {
+	grpc.ServerStream
+}
+
+func (x *linksListLinksServer) Send(m *UserLink) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Links_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Credentials)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).Register(ctx, req.(*Credentials))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Links_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Credentials)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).Login(ctx, req.(*Credentials))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Links_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).Refresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_Refresh_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).Refresh(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Links_ServiceDesc is the grpc.ServiceDesc for Links service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -310,6 +548,249 @@ var Links_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BatchShorten",
 			Handler:    _Links_BatchShorten_Handler,
 		},
+		{
+			MethodName: "Register",
+			Handler:    _Links_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Links_Login_Handler,
+		},
+		{
+			MethodName: "Refresh",
+			Handler:    _Links_Refresh_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ShortenStream",
+			Handler:       _Links_ShortenStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ListLinks",
+			Handler:       _Links_ListLinks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/shortener.proto",
+}
+
+const (
+	Admin_SearchLinks_FullMethodName     = "/proto.Admin/SearchLinks"
+	Admin_SetLinkDisabled_FullMethodName = "/proto.Admin/SetLinkDisabled"
+	Admin_BanUser_FullMethodName         = "/proto.Admin/BanUser"
+	Admin_GetUserCounts_FullMethodName   = "/proto.Admin/GetUserCounts"
+)
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// gRPC service definition for moderating the service. Calls need a token of a user
+// with the admin role.
+type AdminClient interface {
+	// RPC to search the links of all users.
+	SearchLinks(ctx context.Context, in *SearchLinksRequest, opts ...grpc.CallOption) (*AdminLinks, error)
+	// RPC to disable or enable the redirect of a link.
+	SetLinkDisabled(ctx context.Context, in *SetLinkDisabledRequest, opts ...grpc.CallOption) (*Empty, error)
+	// RPC to ban a user or lift the ban.
+	BanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*Empty, error)
+	// RPC to count the links of every user.
+	GetUserCounts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserCounts, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) SearchLinks(ctx context.Context, in *SearchLinksRequest, opts ...grpc.CallOption) (*AdminLinks, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminLinks)
+	err := c.cc.Invoke(ctx, Admin_SearchLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetLinkDisabled(ctx context.Context, in *SetLinkDisabledRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Admin_SetLinkDisabled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) BanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Admin_BanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetUserCounts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserCounts, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserCounts)
+	err := c.cc.Invoke(ctx, Admin_GetUserCounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+//
+// gRPC service definition for moderating the service. Calls need a token of a user
+// with the admin role.
+type AdminServer interface {
+	// RPC to search the links of all users.
+	SearchLinks(context.Context, *SearchLinksRequest) (*AdminLinks, error)
+	// RPC to disable or enable the redirect of a link.
+	SetLinkDisabled(context.Context, *SetLinkDisabledRequest) (*Empty, error)
+	// RPC to ban a user or lift the ban.
+	BanUser(context.Context, *BanUserRequest) (*Empty, error)
+	// RPC to count the links of every user.
+	GetUserCounts(context.Context, *Empty) (*UserCounts, error)
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) SearchLinks(context.Context, *SearchLinksRequest) (*AdminLinks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchLinks not implemented")
+}
+func (UnimplementedAdminServer) SetLinkDisabled(context.Context, *SetLinkDisabledRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLinkDisabled not implemented")
+}
+func (UnimplementedAdminServer) BanUser(context.Context, *BanUserRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanUser not implemented")
+}
+func (UnimplementedAdminServer) GetUserCounts(context.Context, *Empty) (*UserCounts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserCounts not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_SearchLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SearchLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SearchLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SearchLinks(ctx, req.(*SearchLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetLinkDisabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLinkDisabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetLinkDisabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetLinkDisabled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetLinkDisabled(ctx, req.(*SetLinkDisabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_BanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).BanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_BanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).BanUser(ctx, req.(*BanUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetUserCounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetUserCounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_GetUserCounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetUserCounts(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchLinks",
+			Handler:    _Admin_SearchLinks_Handler,
+		},
+		{
+			MethodName: "SetLinkDisabled",
+			Handler:    _Admin_SetLinkDisabled_Handler,
+		},
+		{
+			MethodName: "BanUser",
+			Handler:    _Admin_BanUser_Handler,
+		},
+		{
+			MethodName: "GetUserCounts",
+			Handler:    _Admin_GetUserCounts_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/shortener.proto",